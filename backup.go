@@ -0,0 +1,703 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	backupsDirName      = "backups"
+	backupManifestName  = "backup.json" // 백업 파일 안의 목록 (파일별 크기/SHA-256)
+	backupDataPrefix    = "data"        // 백업 파일 안에서 데이터 디렉토리가 들어가는 폴더
+	backupPartialSuffix = ".partial"    // 검증이 끝나기 전의 백업 파일
+	defaultDataRoot     = "./data"      // config.yaml에 dataRoot가 없을 때 SillyTavern이 사용하는 경로
+
+	backupReasonManual   = "manual"
+	backupReasonUpdate   = "update"
+	backupReasonRestore  = "restore"
+	backupReasonSchedule = "scheduled"
+)
+
+// backupSkipDirs는 데이터 디렉토리 최상위에서 백업하지 않는 캐시 폴더입니다 (SillyTavern이 다시 만듦).
+var backupSkipDirs = map[string]bool{"_cache": true, "_webpack": true}
+
+var backupReasonLabels = map[string]string{
+	backupReasonManual:   "직접 백업",
+	backupReasonUpdate:   "업데이트 이전 자동 백업",
+	backupReasonRestore:  "복원 이전 자동 백업",
+	backupReasonSchedule: "예약 백업",
+}
+
+// backupManifest는 백업 파일에 함께 저장하는 목록입니다. 복원 전에 이 목록으로 백업 파일을 검증합니다.
+type backupManifest struct {
+	Created  time.Time     `json:"created"`
+	Reason   string        `json:"reason"`
+	Source   string        `json:"source"`   // 백업한 인스턴스 경로
+	DataRoot string        `json:"dataRoot"` // 백업 당시 config.yaml의 dataRoot
+	Commit   string        `json:"commit,omitempty"`
+	Config   bool          `json:"config"` // config.yaml 포함 여부
+	Files    []backupEntry `json:"files"`
+}
+
+type backupEntry struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// backupFile은 보관 중인 백업 파일 하나입니다.
+type backupFile struct {
+	Path    string
+	Created time.Time
+	Reason  string
+	Size    int64
+}
+
+func backupDir(baseDir string) (string, error) {
+	return instanceDataDir(baseDir, backupsDirName)
+}
+
+// backupArchiveExt는 새 백업 파일의 확장자입니다 (Windows는 zip, 그 외는 tar.gz).
+func backupArchiveExt() string {
+	if runtime.GOOS == "windows" {
+		return ".zip"
+	}
+	return ".tar.gz"
+}
+
+// instanceDataRoot는 config.yaml의 dataRoot(없으면 ./data)를 baseDir 기준 절대 경로로 반환합니다.
+func instanceDataRoot(baseDir string) (string, string) {
+	dataRoot := defaultDataRoot
+	if config, err := loadConfig(filepath.Join(baseDir, configFileName)); err == nil {
+		if value, ok := config.getString("dataRoot"); ok && strings.TrimSpace(value) != "" {
+			dataRoot = strings.TrimSpace(value)
+		}
+	}
+	if filepath.IsAbs(dataRoot) {
+		return filepath.Clean(dataRoot), dataRoot
+	}
+	return filepath.Join(baseDir, dataRoot), dataRoot
+}
+
+// archiveWriter는 zip과 tar.gz에 파일을 같은 방식으로 추가하기 위한 인터페이스입니다.
+type archiveWriter interface {
+	add(name string, info fs.FileInfo, r io.Reader) error
+	Close() error
+}
+
+type zipArchiveWriter struct{ zw *zip.Writer }
+
+func (w zipArchiveWriter) add(name string, info fs.FileInfo, r io.Reader) error {
+	hdr, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return err
+	}
+	hdr.Name, hdr.Method = name, zip.Deflate
+	out, err := w.zw.CreateHeader(hdr)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, r)
+	return err
+}
+
+func (w zipArchiveWriter) Close() error { return w.zw.Close() }
+
+type tarArchiveWriter struct {
+	gz *gzip.Writer
+	tw *tar.Writer
+}
+
+func (w tarArchiveWriter) add(name string, info fs.FileInfo, r io.Reader) error {
+	hdr, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return err
+	}
+	hdr.Name = name
+	if err := w.tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	// 백업하는 동안 파일이 커져도 헤더에 기록한 크기까지만 씁니다.
+	_, err = io.Copy(w.tw, io.LimitReader(r, hdr.Size))
+	return err
+}
+
+func (w tarArchiveWriter) Close() error {
+	if err := w.tw.Close(); err != nil {
+		return err
+	}
+	return w.gz.Close()
+}
+
+func newArchiveWriter(out io.Writer, archivePath string) archiveWriter {
+	if strings.HasSuffix(strings.TrimSuffix(archivePath, backupPartialSuffix), ".zip") {
+		return zipArchiveWriter{zip.NewWriter(out)}
+	}
+	gz := gzip.NewWriter(out)
+	return tarArchiveWriter{gz, tar.NewWriter(gz)}
+}
+
+// memFileInfo는 백업 목록(backup.json)처럼 디스크에 없는 내용을 압축 파일에 넣을 때 사용합니다.
+type memFileInfo struct {
+	name string
+	size int64
+}
+
+func (m memFileInfo) Name() string       { return m.name }
+func (m memFileInfo) Size() int64        { return m.size }
+func (m memFileInfo) Mode() fs.FileMode  { return 0644 }
+func (m memFileInfo) ModTime() time.Time { return time.Now() }
+func (m memFileInfo) IsDir() bool        { return false }
+func (m memFileInfo) Sys() interface{}   { return nil }
+
+// createBackup은 baseDir의 데이터 디렉토리(dataRoot)와 config.yaml을 백업 파일로 만들고 검증한 뒤 경로를 반환합니다.
+// 검증이 끝날 때까지는 .partial 이름으로 두므로, 목록에는 검증을 통과한 백업만 나타납니다.
+func createBackup(baseDir, reason string) (string, error) {
+	absDir, err := normalizePath(baseDir)
+	if err != nil {
+		return "", err
+	}
+	dataPath, dataRoot := instanceDataRoot(absDir)
+	configPath := filepath.Join(absDir, configFileName)
+	_, errData := os.Stat(dataPath)
+	_, errConfig := os.Stat(configPath)
+	if errData != nil && errConfig != nil {
+		return "", fmt.Errorf("백업할 데이터가 없습니다 (%s, %s 모두 없음)", dataPath, configPath)
+	}
+
+	dir, err := backupDir(absDir)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("디렉토리(%s) 생성 실패: %w", dir, err)
+	}
+	now := time.Now()
+	archivePath := filepath.Join(dir, now.Format("20060102-150405")+"-"+reason+backupArchiveExt())
+	for i := 2; ; i++ {
+		if _, err := os.Stat(archivePath); os.IsNotExist(err) {
+			break
+		}
+		archivePath = filepath.Join(dir, fmt.Sprintf("%s-%s-%d%s", now.Format("20060102-150405"), reason, i, backupArchiveExt()))
+	}
+	partialPath := archivePath + backupPartialSuffix
+
+	manifest := &backupManifest{Created: now, Reason: reason, Source: absDir, DataRoot: dataRoot}
+	manifest.Commit, _ = gitHeadCommit(absDir)
+	fmt.Printf("데이터 백업 중 (%s)...\n", dataPath)
+	if err := writeBackupArchive(partialPath, manifest, dataPath, configPath); err != nil {
+		os.Remove(partialPath)
+		return "", err
+	}
+	if _, err := verifyBackup(partialPath); err != nil {
+		os.Remove(partialPath)
+		return "", fmt.Errorf("백업 파일 검증 실패: %w", err)
+	}
+	if err := os.Rename(partialPath, archivePath); err != nil {
+		os.Remove(partialPath)
+		return "", fmt.Errorf("백업 파일 이름 변경 실패: %w", err)
+	}
+	var total int64
+	for _, f := range manifest.Files {
+		total += f.Size
+	}
+	fmt.Printf("✅ 백업 완료 및 검증됨: %s (파일 %d개, %.2f MB)\n", archivePath, len(manifest.Files), float64(total)/(1024*1024))
+	return archivePath, nil
+}
+
+func writeBackupArchive(archivePath string, manifest *backupManifest, dataPath, configPath string) error {
+	out, err := os.Create(archivePath)
+	if err != nil {
+		return fmt.Errorf("백업 파일(%s) 만들기 실패: %w", archivePath, err)
+	}
+	defer out.Close()
+	aw := newArchiveWriter(out, archivePath)
+
+	addFile := func(name, src string, info fs.FileInfo) error {
+		in, err := os.Open(src)
+		if err != nil {
+			return fmt.Errorf("'%s' 읽기 실패: %w", src, err)
+		}
+		defer in.Close()
+		hash := sha256.New()
+		counter := &countingWriter{}
+		if err := aw.add(name, info, io.TeeReader(in, io.MultiWriter(hash, counter))); err != nil {
+			return fmt.Errorf("'%s' 백업 실패: %w", src, err)
+		}
+		manifest.Files = append(manifest.Files, backupEntry{Name: name, Size: counter.n, SHA256: hex.EncodeToString(hash.Sum(nil))})
+		return nil
+	}
+
+	if info, err := os.Stat(configPath); err == nil {
+		if err := addFile(configFileName, configPath, info); err != nil {
+			return err
+		}
+		manifest.Config = true
+	}
+	if _, err := os.Stat(dataPath); err == nil {
+		err := filepath.WalkDir(dataPath, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			rel, _ := filepath.Rel(dataPath, p)
+			if d.IsDir() {
+				if backupSkipDirs[rel] {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !d.Type().IsRegular() {
+				return nil // 심볼릭 링크 등은 건너뜀
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			return addFile(path.Join(backupDataPrefix, filepath.ToSlash(rel)), p, info)
+		})
+		if err != nil {
+			return fmt.Errorf("데이터 디렉토리 백업 실패: %w", err)
+		}
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := aw.add(backupManifestName, memFileInfo{backupManifestName, int64(len(data))}, strings.NewReader(string(data))); err != nil {
+		return fmt.Errorf("백업 목록 저장 실패: %w", err)
+	}
+	if err := aw.Close(); err != nil {
+		return fmt.Errorf("백업 파일 마무리 실패: %w", err)
+	}
+	return out.Close()
+}
+
+type countingWriter struct{ n int64 }
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	c.n += int64(len(p))
+	return len(p), nil
+}
+
+// walkArchive는 zip 또는 tar.gz 백업 파일의 일반 파일을 순서대로 fn에 넘깁니다.
+func walkArchive(archivePath string, fn func(name string, r io.Reader) error) error {
+	if strings.HasSuffix(strings.TrimSuffix(archivePath, backupPartialSuffix), ".zip") {
+		zr, err := zip.OpenReader(archivePath)
+		if err != nil {
+			return fmt.Errorf("백업 파일 열기 실패: %w", err)
+		}
+		defer zr.Close()
+		for _, f := range zr.File {
+			if f.FileInfo().IsDir() {
+				continue
+			}
+			in, err := f.Open()
+			if err != nil {
+				return fmt.Errorf("'%s' 읽기 실패: %w", f.Name, err)
+			}
+			err = fn(f.Name, in)
+			in.Close()
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return fmt.Errorf("백업 파일 열기 실패: %w", err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("gzip 해제 실패: %w", err)
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("tar 읽기 실패: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		if err := fn(hdr.Name, tr); err != nil {
+			return err
+		}
+	}
+}
+
+// readBackupManifest는 백업 파일 안의 목록(backup.json)을 읽습니다.
+func readBackupManifest(archivePath string) (*backupManifest, error) {
+	var manifest *backupManifest
+	err := walkArchive(archivePath, func(name string, r io.Reader) error {
+		if name != backupManifestName {
+			return nil
+		}
+		manifest = &backupManifest{}
+		if err := json.NewDecoder(r).Decode(manifest); err != nil {
+			return fmt.Errorf("백업 목록 파싱 실패: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if manifest == nil {
+		return nil, fmt.Errorf("설치 프로그램으로 만든 백업 파일이 아닙니다 (%s 없음)", backupManifestName)
+	}
+	return manifest, nil
+}
+
+// verifyBackup은 백업 파일의 모든 파일이 목록의 크기/SHA-256과 일치하는지 확인합니다.
+func verifyBackup(archivePath string) (*backupManifest, error) {
+	manifest, err := readBackupManifest(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	expected := make(map[string]backupEntry, len(manifest.Files))
+	for _, f := range manifest.Files {
+		expected[f.Name] = f
+	}
+	err = walkArchive(archivePath, func(name string, r io.Reader) error {
+		if name == backupManifestName {
+			return nil
+		}
+		entry, ok := expected[name]
+		if !ok {
+			return fmt.Errorf("목록에 없는 파일이 있습니다: %s", name)
+		}
+		hash := sha256.New()
+		n, err := io.Copy(hash, r)
+		if err != nil {
+			return fmt.Errorf("'%s' 읽기 실패: %w", name, err)
+		}
+		if n != entry.Size || hex.EncodeToString(hash.Sum(nil)) != entry.SHA256 {
+			return fmt.Errorf("'%s'의 내용이 목록과 다릅니다 (손상된 백업)", name)
+		}
+		delete(expected, name)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for name := range expected {
+		return nil, fmt.Errorf("목록의 파일이 백업에 없습니다: %s", name)
+	}
+	return manifest, nil
+}
+
+// listBackups는 baseDir의 백업 파일을 최신순으로 반환합니다 (검증을 마친 파일만).
+func listBackups(baseDir string) ([]*backupFile, error) {
+	dir, err := backupDir(baseDir)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("백업 목록 읽기 실패: %w", err)
+	}
+	var backups []*backupFile
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !(strings.HasSuffix(name, ".zip") || strings.HasSuffix(name, ".tar.gz")) {
+			continue
+		}
+		b := parseBackupFileName(filepath.Join(dir, name))
+		if b == nil {
+			continue
+		}
+		if info, err := entry.Info(); err == nil {
+			b.Size = info.Size()
+		}
+		backups = append(backups, b)
+	}
+	sort.Slice(backups, func(i, j int) bool { return backups[i].Created.After(backups[j].Created) })
+	return backups, nil
+}
+
+// parseBackupFileName은 '<시각>-<이유>[-n].<확장자>' 형식의 파일 이름을 해석합니다.
+func parseBackupFileName(archivePath string) *backupFile {
+	name := strings.TrimSuffix(strings.TrimSuffix(filepath.Base(archivePath), ".zip"), ".tar.gz")
+	parts := strings.SplitN(name, "-", 4)
+	if len(parts) < 3 {
+		return nil
+	}
+	created, err := time.ParseInLocation("20060102-150405", parts[0]+"-"+parts[1], time.Local)
+	if err != nil {
+		return nil
+	}
+	return &backupFile{Path: archivePath, Created: created, Reason: parts[2]}
+}
+
+func backupReasonLabel(reason string) string {
+	if label, ok := backupReasonLabels[reason]; ok {
+		return label
+	}
+	return reason
+}
+
+func printBackupList(backups []*backupFile) {
+	if len(backups) == 0 {
+		fmt.Println("보관 중인 백업이 없습니다.")
+		return
+	}
+	for i, b := range backups {
+		fmt.Printf("[%d] %s | %s | %.2f MB | %s\n", i+1, b.Created.Format("2006-01-02 15:04:05"), backupReasonLabel(b.Reason), float64(b.Size)/(1024*1024), b.Path)
+	}
+}
+
+// resolveBackupPath는 번호(목록 순서) 또는 파일 경로로 백업 파일을 찾습니다. 번호는 sourceDir 인스턴스의 목록 기준입니다.
+func resolveBackupPath(sourceDir, input string) (string, error) {
+	input = strings.TrimSpace(input)
+	if n, err := strconv.Atoi(input); err == nil {
+		backups, err := listBackups(sourceDir)
+		if err != nil {
+			return "", err
+		}
+		if n < 1 || n > len(backups) {
+			return "", fmt.Errorf("%d번 백업이 없습니다 (백업 %d개)", n, len(backups))
+		}
+		return backups[n-1].Path, nil
+	}
+	archivePath, err := normalizePath(input)
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(archivePath); err != nil {
+		return "", fmt.Errorf("백업 파일(%s)을 찾을 수 없습니다", archivePath)
+	}
+	return archivePath, nil
+}
+
+// resolveInstanceDir은 등록된 인스턴스 이름이나 경로를 설치 경로로 바꿉니다.
+func resolveInstanceDir(input string) (string, error) {
+	if registry, err := loadRegistry(); err == nil {
+		if inst := registry.find(instanceNameFromInput(registry, input)); inst != nil {
+			return inst.Path, nil
+		}
+	}
+	return normalizePath(input)
+}
+
+// restoreBackup은 백업 파일을 검증한 뒤 targetDir 인스턴스에 복원합니다. 복원 전에 대상의 현재 데이터를 자동으로 백업하며,
+// withConfig가 false이면 config.yaml은 그대로 둡니다. 데이터 디렉토리는 통째로 백업 당시 내용으로 바뀝니다.
+func restoreBackup(archivePath, targetDir string, withConfig bool) error {
+	absDir, err := normalizePath(targetDir)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(absDir); err != nil {
+		return fmt.Errorf("%w: 복원할 인스턴스(%s)를 찾을 수 없습니다", errNotInstalled, absDir)
+	}
+	if state, err := loadServerState(absDir); err == nil && state != nil {
+		return fmt.Errorf("SillyTavern이 실행 중입니다 (PID %d). 중지(stop)한 뒤 복원해주세요", state.SupervisorPID)
+	}
+	fmt.Printf("백업 파일 검증 중 (%s)...\n", archivePath)
+	manifest, err := verifyBackup(archivePath)
+	if err != nil {
+		return fmt.Errorf("백업 파일 검증 실패: %w", err)
+	}
+	fmt.Printf("✅ 검증 완료: %s에 %s에서 만든 백업 (파일 %d개)\n", manifest.Created.Local().Format("2006-01-02 15:04:05"), manifest.Source, len(manifest.Files))
+
+	if err := backupBeforeChange(absDir, backupReasonRestore); err != nil {
+		return err
+	}
+
+	// 같은 파일 시스템에서 이름만 바꿀 수 있도록 임시 폴더를 인스턴스 폴더 안에 만듭니다.
+	tmpDir, err := os.MkdirTemp(absDir, ".restore-")
+	if err != nil {
+		return fmt.Errorf("임시 폴더 생성 실패: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	if strings.HasSuffix(archivePath, ".zip") {
+		err = extractZip(archivePath, tmpDir)
+	} else {
+		err = extractTarGz(archivePath, tmpDir)
+	}
+	if err != nil {
+		return fmt.Errorf("백업 파일 압축 해제 실패: %w", err)
+	}
+
+	if withConfig && manifest.Config {
+		if err := replacePath(filepath.Join(tmpDir, configFileName), filepath.Join(absDir, configFileName)); err != nil {
+			return err
+		}
+		fmt.Println("✅ config.yaml 복원 완료")
+	}
+	// 복원 후의 config.yaml 기준으로 데이터 디렉토리 위치를 정합니다.
+	dataPath, _ := instanceDataRoot(absDir)
+	restoredData := filepath.Join(tmpDir, backupDataPrefix)
+	if _, err := os.Stat(restoredData); os.IsNotExist(err) {
+		os.MkdirAll(restoredData, 0755)
+	}
+	if err := os.MkdirAll(filepath.Dir(dataPath), 0755); err != nil {
+		return fmt.Errorf("디렉토리(%s) 생성 실패: %w", filepath.Dir(dataPath), err)
+	}
+	if err := replacePath(restoredData, dataPath); err != nil {
+		return err
+	}
+	fmt.Printf("✅ 데이터 디렉토리 복원 완료: %s\n", dataPath)
+	return nil
+}
+
+// replacePath는 dst를 src로 바꿉니다. dst가 있으면 먼저 옆으로 옮겨 두었다가, 교체에 성공하면 지웁니다.
+// 다른 드라이브/파일 시스템이라 이름을 바꿀 수 없으면 복사합니다.
+func replacePath(src, dst string) error {
+	old := dst + ".before-restore"
+	os.RemoveAll(old)
+	_, errExists := os.Stat(dst)
+	if errExists == nil {
+		if err := os.Rename(dst, old); err != nil {
+			return fmt.Errorf("기존 '%s' 이동 실패 (사용 중인 파일이 있는지 확인해주세요): %w", dst, err)
+		}
+	}
+	if err := os.Rename(src, dst); err != nil {
+		if errCopy := copyTree(src, dst); errCopy != nil {
+			os.RemoveAll(dst)
+			if errExists == nil {
+				os.Rename(old, dst)
+			}
+			return fmt.Errorf("'%s' 복원 실패: %w", dst, errCopy)
+		}
+	}
+	if errExists == nil {
+		os.RemoveAll(old)
+	}
+	return nil
+}
+
+// copyTree는 src(파일 또는 디렉토리)를 dst로 복사합니다.
+func copyTree(src, dst string) error {
+	return filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(src, p)
+		target := filepath.Join(dst, rel)
+		if d.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		in, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+		out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(out, in); err != nil {
+			out.Close()
+			return err
+		}
+		return out.Close()
+	})
+}
+
+// backupBeforeChange는 업데이트/복원 전에 자동으로 백업합니다. 백업할 데이터가 없으면 건너뛰고,
+// 백업에 실패하면 계속할지 묻습니다 (비대화형 실행에서 --yes가 없으면 중단).
+func backupBeforeChange(baseDir, reason string) error {
+	dataPath, _ := instanceDataRoot(baseDir)
+	_, errData := os.Stat(dataPath)
+	_, errConfig := os.Stat(filepath.Join(baseDir, configFileName))
+	if errData != nil && errConfig != nil {
+		return nil
+	}
+	fmt.Printf("ℹ️ 데이터를 자동으로 백업합니다 (%s).\n", backupReasonLabel(reason))
+	if _, err := createBackup(baseDir, reason); err != nil {
+		fmt.Println("⚠️ 자동 백업 실패:", err)
+		if !confirm("   백업 없이 계속하시겠습니까? (y/n): ") {
+			return fmt.Errorf("자동 백업에 실패하여 중단했습니다: %w", err)
+		}
+	}
+	return nil
+}
+
+// backupMenu는 메뉴의 '데이터 백업 / 복원' 항목입니다.
+func backupMenu() {
+	fmt.Println("\n[ 데이터 백업 / 복원 ]")
+	dataPath, _ := instanceDataRoot(installDir)
+	fmt.Printf("데이터 디렉토리: %s\n\n", dataPath)
+	backups, err := listBackups(installDir)
+	if err != nil {
+		fmt.Println("❌", err)
+		return
+	}
+	printBackupList(backups)
+
+	fmt.Println("\n1. 지금 백업")
+	fmt.Println("2. 이 인스턴스에 복원")
+	fmt.Println("3. 다른 인스턴스의 백업 가져와 복원")
+	fmt.Println("4. 백업 파일 검증")
+	fmt.Println("5. 자동 백업 일정")
+	fmt.Println("6. 돌아가기")
+	fmt.Print("\n선택하세요 (1-6): ")
+
+	switch choice := getUserChoice(); choice {
+	case "1":
+		_, err = createBackup(installDir, backupReasonManual)
+	case "2", "3":
+		sourceDir := installDir
+		if choice == "3" {
+			fmt.Print("백업을 가져올 인스턴스 이름 또는 경로: ")
+			if sourceDir, err = resolveInstanceDir(getUserChoice()); err != nil {
+				break
+			}
+			other, errList := listBackups(sourceDir)
+			if errList != nil {
+				err = errList
+				break
+			}
+			printBackupList(other)
+		}
+		fmt.Print("복원할 백업 번호 또는 파일 경로를 입력하세요: ")
+		var archivePath string
+		if archivePath, err = resolveBackupPath(sourceDir, getUserChoice()); err != nil {
+			break
+		}
+		withConfig := confirm("config.yaml(포트, 화이트리스트 등 설정)도 복원하시겠습니까? (y/n): ")
+		fmt.Printf("⚠️ %s의 데이터 디렉토리가 백업 당시 내용으로 바뀝니다 (현재 데이터는 자동으로 백업됩니다).\n", installDir)
+		if !confirm("복원하시겠습니까? (y/n): ") {
+			return
+		}
+		err = restoreBackup(archivePath, installDir, withConfig)
+	case "4":
+		fmt.Print("검증할 백업 번호 또는 파일 경로를 입력하세요: ")
+		var archivePath string
+		if archivePath, err = resolveBackupPath(installDir, getUserChoice()); err != nil {
+			break
+		}
+		var manifest *backupManifest
+		if manifest, err = verifyBackup(archivePath); err == nil {
+			fmt.Printf("✅ 백업 파일이 정상입니다 (파일 %d개, %s에서 생성).\n", len(manifest.Files), manifest.Source)
+		}
+	case "5":
+		backupScheduleMenu()
+	}
+	if err != nil {
+		fmt.Println("❌", err)
+	}
+}
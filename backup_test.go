@@ -0,0 +1,326 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// newBackupInstance는 config.yaml(비어 있으면 만들지 않음)과 files(baseDir 기준 경로 -> 내용)로 인스턴스 폴더를 만듭니다.
+func newBackupInstance(t *testing.T, config string, files map[string]string) string {
+	t.Helper()
+	t.Setenv(installerDataDirEnv, filepath.Join(t.TempDir(), "home"))
+	baseDir := filepath.Join(t.TempDir(), "Silly Tavern")
+	if err := os.MkdirAll(baseDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if config != "" {
+		writeFileT(t, filepath.Join(baseDir, configFileName), config)
+	}
+	for name, content := range files {
+		writeFileT(t, filepath.Join(baseDir, name), content)
+	}
+	return baseDir
+}
+
+// readTree는 dir 아래의 모든 파일을 슬래시 경로 -> 내용으로 읽습니다.
+func readTree(t *testing.T, dir string) map[string]string {
+	t.Helper()
+	tree := map[string]string{}
+	err := filepath.WalkDir(dir, func(p string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, p)
+		tree[filepath.ToSlash(rel)] = readFileT(t, p)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tree
+}
+
+// readArchive는 백업 파일의 모든 항목을 이름 -> 내용으로 읽습니다.
+func readArchive(t *testing.T, archivePath string) map[string]string {
+	t.Helper()
+	entries := map[string]string{}
+	err := walkArchive(archivePath, func(name string, r io.Reader) error {
+		data, err := io.ReadAll(r)
+		entries[name] = string(data)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return entries
+}
+
+// writeArchive는 entries로 백업 파일(archivePath의 확장자에 따라 zip 또는 tar.gz)을 새로 씁니다.
+func writeArchive(t *testing.T, archivePath string, entries map[string]string) {
+	t.Helper()
+	out, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Close()
+	aw := newArchiveWriter(out, archivePath)
+	var names []string
+	for name := range entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		content := entries[name]
+		if err := aw.add(name, memFileInfo{path.Base(name), int64(len(content))}, strings.NewReader(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := aw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// backupTo는 baseDir의 데이터를 archivePath(확장자로 형식 결정)에 백업합니다.
+func backupTo(t *testing.T, baseDir, archivePath string) *backupManifest {
+	t.Helper()
+	dataPath, dataRoot := instanceDataRoot(baseDir)
+	manifest := &backupManifest{Reason: backupReasonManual, Source: baseDir, DataRoot: dataRoot}
+	if err := writeBackupArchive(archivePath, manifest, dataPath, filepath.Join(baseDir, configFileName)); err != nil {
+		t.Fatalf("writeBackupArchive: %v", err)
+	}
+	return manifest
+}
+
+func manifestNames(m *backupManifest) []string {
+	var names []string
+	for _, f := range m.Files {
+		names = append(names, f.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+var backupFormats = []string{".tar.gz", ".zip"}
+
+func TestBackupRoundTrip(t *testing.T) {
+	const config = "dataRoot: ./data\nport: 8000\n"
+	original := map[string]string{
+		"data/default-user/chats/Alice/chat 1.jsonl": "{\"mes\": \"hi\"}\n",
+		"data/default-user/settings.json":            "{}\n",
+		"data/default-user/_cache/kept.txt":          "not a top-level cache\n",
+		"data/_cache/characters/big.bin":             "cache",
+		"data/_webpack/bundle.js":                    "cache",
+		"public/index.html":                          "<html></html>\n",
+	}
+	for _, ext := range backupFormats {
+		t.Run(ext, func(t *testing.T) {
+			baseDir := newBackupInstance(t, config, original)
+			archivePath := filepath.Join(t.TempDir(), "backup"+ext)
+			backupTo(t, baseDir, archivePath)
+
+			manifest, err := verifyBackup(archivePath)
+			if err != nil {
+				t.Fatalf("verifyBackup: %v", err)
+			}
+			want := []string{"config.yaml", "data/default-user/_cache/kept.txt", "data/default-user/chats/Alice/chat 1.jsonl", "data/default-user/settings.json"}
+			if got := manifestNames(manifest); !reflect.DeepEqual(got, want) {
+				t.Errorf("backed up %q, want %q (top-level _cache/_webpack skipped)", got, want)
+			}
+			if !manifest.Config || manifest.DataRoot != "./data" {
+				t.Errorf("manifest Config=%v DataRoot=%q", manifest.Config, manifest.DataRoot)
+			}
+
+			// 백업 이후의 변경은 복원하면 사라집니다.
+			writeFileT(t, filepath.Join(baseDir, "data/default-user/chats/Alice/chat 1.jsonl"), "changed\n")
+			writeFileT(t, filepath.Join(baseDir, "data/default-user/chats/Bob/new.jsonl"), "new\n")
+			writeFileT(t, filepath.Join(baseDir, configFileName), "dataRoot: ./data\nport: 9000\n")
+
+			if err := restoreBackup(archivePath, baseDir, true); err != nil {
+				t.Fatalf("restoreBackup: %v", err)
+			}
+			got := readTree(t, filepath.Join(baseDir, "data"))
+			wantData := map[string]string{
+				"default-user/chats/Alice/chat 1.jsonl": original["data/default-user/chats/Alice/chat 1.jsonl"],
+				"default-user/settings.json":            original["data/default-user/settings.json"],
+				"default-user/_cache/kept.txt":          original["data/default-user/_cache/kept.txt"],
+			}
+			if !reflect.DeepEqual(got, wantData) {
+				t.Errorf("restored data = %q, want %q", got, wantData)
+			}
+			if got := readFileT(t, filepath.Join(baseDir, configFileName)); got != config {
+				t.Errorf("config.yaml = %q, want %q", got, config)
+			}
+			if got := readFileT(t, filepath.Join(baseDir, "public/index.html")); got != original["public/index.html"] {
+				t.Errorf("file outside the data directory changed: %q", got)
+			}
+
+			// 복원 전 데이터는 자동 백업으로 남습니다.
+			backups, err := listBackups(baseDir)
+			if err != nil || len(backups) != 1 || backups[0].Reason != backupReasonRestore {
+				t.Fatalf("pre-restore backup = %v, %v", backups, err)
+			}
+			saved := readArchive(t, backups[0].Path)
+			if saved["data/default-user/chats/Bob/new.jsonl"] != "new\n" {
+				t.Errorf("pre-restore backup is missing the replaced data: %q", saved)
+			}
+		})
+	}
+}
+
+func TestCreateBackup(t *testing.T) {
+	baseDir := newBackupInstance(t, "port: 8000\n", map[string]string{"data/default-user/settings.json": "{}\n"})
+	archivePath, err := createBackup(baseDir, backupReasonManual)
+	if err != nil {
+		t.Fatalf("createBackup: %v", err)
+	}
+	if !strings.HasSuffix(archivePath, backupArchiveExt()) {
+		t.Errorf("archive %s does not use %s", archivePath, backupArchiveExt())
+	}
+	dir, _ := backupDir(baseDir)
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 || entries[0].Name() != filepath.Base(archivePath) {
+		t.Errorf("backup directory = %v, want only %s (no %s file)", entries, filepath.Base(archivePath), backupPartialSuffix)
+	}
+	backups, err := listBackups(baseDir)
+	if err != nil || len(backups) != 1 || backups[0].Path != archivePath || backups[0].Reason != backupReasonManual {
+		t.Fatalf("listBackups = %v, %v", backups, err)
+	}
+	// dataRoot가 없으면 ./data를 백업합니다.
+	manifest, err := verifyBackup(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := manifestNames(manifest); !reflect.DeepEqual(got, []string{"config.yaml", "data/default-user/settings.json"}) {
+		t.Errorf("backed up %q", got)
+	}
+
+	empty := newBackupInstance(t, "", nil)
+	if _, err := createBackup(empty, backupReasonManual); err == nil {
+		t.Error("createBackup accepted an instance without data or config.yaml")
+	}
+}
+
+func TestVerifyBackupRejectsModifiedArchive(t *testing.T) {
+	files := map[string]string{
+		"data/default-user/chats/a.jsonl": "a\n",
+		"data/default-user/chats/b.jsonl": "b\n",
+	}
+	tests := []struct {
+		name   string
+		modify func(entries map[string]string)
+	}{
+		{"tampered", func(e map[string]string) { e["data/default-user/chats/a.jsonl"] = "A\n" }},
+		{"same size", func(e map[string]string) { e["data/default-user/chats/a.jsonl"] = "x\n" }},
+		{"missing", func(e map[string]string) { delete(e, "data/default-user/chats/b.jsonl") }},
+		{"extra", func(e map[string]string) { e["data/default-user/chats/c.jsonl"] = "c\n" }},
+		{"no manifest", func(e map[string]string) { delete(e, backupManifestName) }},
+	}
+	for _, ext := range backupFormats {
+		for _, tt := range tests {
+			t.Run(ext+"/"+tt.name, func(t *testing.T) {
+				baseDir := newBackupInstance(t, "port: 8000\n", files)
+				archivePath := filepath.Join(t.TempDir(), "backup"+ext)
+				backupTo(t, baseDir, archivePath)
+				entries := readArchive(t, archivePath)
+				tt.modify(entries)
+				writeArchive(t, archivePath, entries)
+
+				if _, err := verifyBackup(archivePath); err == nil {
+					t.Fatal("verifyBackup accepted a modified archive")
+				}
+				writeFileT(t, filepath.Join(baseDir, "data/default-user/chats/a.jsonl"), "current\n")
+				if err := restoreBackup(archivePath, baseDir, true); err == nil {
+					t.Fatal("restoreBackup accepted a modified archive")
+				}
+				if got := readFileT(t, filepath.Join(baseDir, "data/default-user/chats/a.jsonl")); got != "current\n" {
+					t.Errorf("data changed by a rejected restore: %q", got)
+				}
+			})
+		}
+	}
+}
+
+func TestRestoreBackupConfig(t *testing.T) {
+	data := map[string]string{"data/default-user/settings.json": "{\"v\": 1}\n"}
+	for _, ext := range backupFormats {
+		t.Run(ext, func(t *testing.T) {
+			// config.yaml을 포함한 백업이지만 withConfig=false이면 현재 config.yaml을 유지합니다.
+			baseDir := newBackupInstance(t, "port: 8000\n", data)
+			archivePath := filepath.Join(t.TempDir(), "with-config"+ext)
+			backupTo(t, baseDir, archivePath)
+			writeFileT(t, filepath.Join(baseDir, configFileName), "port: 9000\n")
+			writeFileT(t, filepath.Join(baseDir, "data/default-user/settings.json"), "{\"v\": 2}\n")
+			if err := restoreBackup(archivePath, baseDir, false); err != nil {
+				t.Fatalf("restoreBackup: %v", err)
+			}
+			if got := readFileT(t, filepath.Join(baseDir, configFileName)); got != "port: 9000\n" {
+				t.Errorf("config.yaml = %q, want the current one kept", got)
+			}
+			if got := readFileT(t, filepath.Join(baseDir, "data/default-user/settings.json")); got != "{\"v\": 1}\n" {
+				t.Errorf("settings.json = %q after restore", got)
+			}
+
+			// config.yaml이 없는 백업은 withConfig=true여도 현재 config.yaml을 건드리지 않습니다.
+			noConfig := newBackupInstance(t, "", data)
+			archivePath = filepath.Join(t.TempDir(), "without-config"+ext)
+			if manifest := backupTo(t, noConfig, archivePath); manifest.Config {
+				t.Fatal("manifest claims config.yaml without one")
+			}
+			writeFileT(t, filepath.Join(noConfig, configFileName), "port: 9000\n")
+			if err := restoreBackup(archivePath, noConfig, true); err != nil {
+				t.Fatalf("restoreBackup: %v", err)
+			}
+			if got := readFileT(t, filepath.Join(noConfig, configFileName)); got != "port: 9000\n" {
+				t.Errorf("config.yaml = %q, want the current one kept", got)
+			}
+		})
+	}
+}
+
+func TestBackupDataRoot(t *testing.T) {
+	absRoot := filepath.Join(t.TempDir(), "shared data")
+	tests := []struct {
+		name, dataRoot string
+		dataPath       func(baseDir string) string
+	}{
+		{"relative", "./user data", func(baseDir string) string { return filepath.Join(baseDir, "user data") }},
+		{"absolute", absRoot, func(string) string { return absRoot }},
+	}
+	for _, ext := range backupFormats {
+		for _, tt := range tests {
+			t.Run(ext+"/"+tt.name, func(t *testing.T) {
+				config := "dataRoot: '" + tt.dataRoot + "'\n"
+				baseDir := newBackupInstance(t, config, map[string]string{"data/ignored.txt": "not the data root\n"})
+				dataPath := tt.dataPath(baseDir)
+				os.RemoveAll(dataPath)
+				writeFileT(t, filepath.Join(dataPath, "default-user/settings.json"), "{\"v\": 1}\n")
+
+				archivePath := filepath.Join(t.TempDir(), "backup"+ext)
+				manifest := backupTo(t, baseDir, archivePath)
+				if manifest.DataRoot != tt.dataRoot {
+					t.Errorf("manifest DataRoot = %q, want %q", manifest.DataRoot, tt.dataRoot)
+				}
+				if got := manifestNames(manifest); !reflect.DeepEqual(got, []string{"config.yaml", "data/default-user/settings.json"}) {
+					t.Errorf("backed up %q", got)
+				}
+
+				os.RemoveAll(dataPath)
+				if err := restoreBackup(archivePath, baseDir, true); err != nil {
+					t.Fatalf("restoreBackup: %v", err)
+				}
+				if got := readTree(t, dataPath); !reflect.DeepEqual(got, map[string]string{"default-user/settings.json": "{\"v\": 1}\n"}) {
+					t.Errorf("restored %s = %q", dataPath, got)
+				}
+				if got := readFileT(t, filepath.Join(baseDir, "data/ignored.txt")); got != "not the data root\n" {
+					t.Errorf("./data changed although dataRoot is %q: %q", tt.dataRoot, got)
+				}
+			})
+		}
+	}
+}
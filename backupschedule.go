@@ -0,0 +1,478 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	backupScheduleName  = "schedule.json" // 인스턴스 백업 폴더 안의 자동 백업 일정
+	defaultKeepDaily    = 7
+	defaultKeepWeekly   = 4
+	partialBackupMaxAge = 24 * time.Hour // 이보다 오래된 .partial 파일은 중단된 백업으로 보고 지움
+)
+
+// backupSchedule은 인스턴스 하나의 자동 백업 일정과 보관 규칙입니다.
+// Every(간격)와 Cron(분 시 일 월 요일) 중 하나만 사용합니다.
+type backupSchedule struct {
+	Path        string    `json:"path"`
+	Every       string    `json:"every,omitempty"` // 예: 24h, 1d, 30m
+	Cron        string    `json:"cron,omitempty"`  // 예: "0 3 * * *" (매일 03:00)
+	KeepDaily   int       `json:"keepDaily"`       // 날짜별로 가장 최근 백업을 남길 일 수
+	KeepWeekly  int       `json:"keepWeekly"`      // 주별로 가장 최근 백업을 남길 주 수
+	Created     time.Time `json:"created"`
+	LastRun     time.Time `json:"lastRun,omitempty"`
+	LastSuccess time.Time `json:"lastSuccess,omitempty"`
+	LastError   string    `json:"lastError,omitempty"`
+}
+
+func backupSchedulePath(baseDir string) (string, error) {
+	dir, err := backupDir(baseDir)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, backupScheduleName), nil
+}
+
+// loadBackupSchedule은 baseDir의 자동 백업 일정을 읽습니다. 설정되지 않았으면 nil입니다.
+func loadBackupSchedule(baseDir string) (*backupSchedule, error) {
+	path, err := backupSchedulePath(baseDir)
+	if err != nil {
+		return nil, err
+	}
+	schedule := &backupSchedule{}
+	if err := readJSONFile(path, schedule); err != nil {
+		return nil, err
+	}
+	if schedule.Path == "" {
+		return nil, nil
+	}
+	return schedule, nil
+}
+
+func saveBackupSchedule(schedule *backupSchedule) error {
+	path, err := backupSchedulePath(schedule.Path)
+	if err != nil {
+		return err
+	}
+	return writeJSONFile(path, schedule)
+}
+
+// parseBackupInterval은 Go 형식의 시간 간격(30m, 12h)과 일 단위(1d, 7d)를 해석합니다.
+func parseBackupInterval(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	var d time.Duration
+	var err error
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		var n int
+		n, err = strconv.Atoi(days)
+		d = time.Duration(n) * 24 * time.Hour
+	} else {
+		d, err = time.ParseDuration(s)
+	}
+	if err != nil || d < time.Minute {
+		return 0, fmt.Errorf("잘못된 간격입니다: '%s' (예: 30m, 12h, 1d, 최소 1분)", s)
+	}
+	return d, nil
+}
+
+// describe는 일정을 사람이 읽을 수 있는 문장으로 표현합니다.
+func (s *backupSchedule) describe() string {
+	when := "매 " + s.Every + "마다"
+	if s.Cron != "" {
+		when = "cron '" + s.Cron + "'"
+	}
+	return fmt.Sprintf("%s, 최근 %d일은 하루 1개, 최근 %d주는 주 1개 보관", when, s.KeepDaily, s.KeepWeekly)
+}
+
+// nextRun은 마지막 실행 이후 다음 백업 예정 시각을 계산합니다.
+func (s *backupSchedule) nextRun() (time.Time, error) {
+	last := s.LastSuccess
+	if last.IsZero() {
+		last = s.Created
+	}
+	if s.Cron != "" {
+		expr, err := parseCron(s.Cron)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return expr.next(last)
+	}
+	if s.LastSuccess.IsZero() {
+		return s.Created, nil // 간격 일정은 설정 직후 첫 백업
+	}
+	every, err := parseBackupInterval(s.Every)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return s.LastSuccess.Add(every), nil
+}
+
+// cronExpr은 '분 시 일 월 요일' 다섯 필드의 cron 식입니다. 각 필드는 *, 숫자, 범위(a-b), 간격(*/n, a-b/n), 목록(,)을 지원합니다.
+type cronExpr struct {
+	minute, hour, dom, month, dow map[int]bool
+	domAny, dowAny                bool
+}
+
+func parseCron(s string) (*cronExpr, error) {
+	fields := strings.Fields(s)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron 식은 '분 시 일 월 요일' 다섯 필드여야 합니다: '%s'", s)
+	}
+	ranges := [5][2]int{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 7}}
+	var sets [5]map[int]bool
+	for i, field := range fields {
+		set, err := parseCronField(field, ranges[i][0], ranges[i][1])
+		if err != nil {
+			return nil, fmt.Errorf("cron 식 '%s'의 %d번째 필드 오류: %w", s, i+1, err)
+		}
+		sets[i] = set
+	}
+	if sets[4][7] {
+		sets[4][0] = true // 일요일은 0과 7 모두 허용
+	}
+	// cron과 같이 '*'로 시작하는 필드(*/2 등)는 제한 없는 것으로 보고 일/요일 OR 규칙에서 뺍니다.
+	return &cronExpr{sets[0], sets[1], sets[2], sets[3], sets[4], strings.HasPrefix(fields[2], "*"), strings.HasPrefix(fields[4], "*")}, nil
+}
+
+func parseCronField(field string, min, max int) (map[int]bool, error) {
+	set := map[int]bool{}
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if base, stepText, ok := strings.Cut(part, "/"); ok {
+			n, err := strconv.Atoi(stepText)
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("잘못된 간격 '%s'", part)
+			}
+			part, step = base, n
+		}
+		lo, hi := min, max
+		if part != "*" {
+			from, to, isRange := strings.Cut(part, "-")
+			var err error
+			if lo, err = strconv.Atoi(from); err != nil {
+				return nil, fmt.Errorf("잘못된 값 '%s'", part)
+			}
+			hi = lo
+			if isRange {
+				if hi, err = strconv.Atoi(to); err != nil {
+					return nil, fmt.Errorf("잘못된 범위 '%s'", part)
+				}
+			} else if step > 1 {
+				hi = max // 'a/n'은 a부터 끝까지 n 간격
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return nil, fmt.Errorf("'%s'이(가) 범위(%d-%d)를 벗어났습니다", part, min, max)
+		}
+		for v := lo; v <= hi; v += step {
+			set[v] = true
+		}
+	}
+	return set, nil
+}
+
+// matchesDay는 cron의 일/요일 조건을 확인합니다. 둘 다 지정되었으면 하나만 맞아도 되고,
+// 하나라도 '*'로 시작하면 둘 다 맞아야 합니다 (cron 규칙).
+func (c *cronExpr) matchesDay(t time.Time) bool {
+	dom, dow := c.dom[t.Day()], c.dow[int(t.Weekday())]
+	if c.domAny || c.dowAny {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// next는 after 이후(after 제외) cron 식에 맞는 첫 시각을 반환합니다.
+func (c *cronExpr) next(after time.Time) (time.Time, error) {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		switch {
+		case !c.month[int(t.Month())]:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !c.matchesDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case !c.hour[t.Hour()]:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case !c.minute[t.Minute()]:
+			t = t.Add(time.Minute)
+		default:
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("cron 식에 맞는 시각이 없습니다")
+}
+
+// setBackupSchedule은 현재 인스턴스(installDir)의 자동 백업 일정을 저장합니다. every와 cron 중 하나만 지정해야 하며,
+// 보관 개수가 음수이면 기존 값(새 일정이면 기본값)을 유지합니다.
+func setBackupSchedule(every, cron string, keepDaily, keepWeekly int) (*backupSchedule, error) {
+	configPath, err := getConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	absDir, err := normalizePath(filepath.Dir(configPath))
+	if err != nil {
+		return nil, err
+	}
+	if (every == "") == (cron == "") {
+		return nil, fmt.Errorf("간격(every)과 cron 식 중 하나만 지정해주세요")
+	}
+	if every != "" {
+		if _, err := parseBackupInterval(every); err != nil {
+			return nil, err
+		}
+	} else if _, err := parseCron(cron); err != nil {
+		return nil, err
+	}
+	schedule, err := loadBackupSchedule(absDir)
+	if err != nil {
+		return nil, err
+	}
+	if schedule == nil {
+		schedule = &backupSchedule{Path: absDir, Created: time.Now(), KeepDaily: defaultKeepDaily, KeepWeekly: defaultKeepWeekly}
+	}
+	schedule.Every, schedule.Cron = every, cron
+	if keepDaily >= 0 {
+		schedule.KeepDaily = keepDaily
+	}
+	if keepWeekly >= 0 {
+		schedule.KeepWeekly = keepWeekly
+	}
+	if err := saveBackupSchedule(schedule); err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+func clearBackupSchedule(baseDir string) error {
+	path, err := backupSchedulePath(baseDir)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("자동 백업 일정 삭제 실패: %w", err)
+	}
+	return nil
+}
+
+// scheduledInstances는 자동 백업 일정이 설정된 모든 인스턴스의 일정을 반환합니다.
+func scheduledInstances() ([]*backupSchedule, error) {
+	dataDir, err := installerDataDir()
+	if err != nil {
+		return nil, err
+	}
+	paths, _ := filepath.Glob(filepath.Join(dataDir, backupsDirName, "*", backupScheduleName))
+	var schedules []*backupSchedule
+	for _, path := range paths {
+		schedule := &backupSchedule{}
+		if err := readJSONFile(path, schedule); err != nil {
+			fmt.Println("⚠️", err)
+			continue
+		}
+		if schedule.Path != "" {
+			schedules = append(schedules, schedule)
+		}
+	}
+	return schedules, nil
+}
+
+// runDueBackups는 일정이 설정된 모든 인스턴스에서 예정 시각이 지난 백업을 만들고 보관 규칙에 따라 정리합니다.
+// OS 스케줄러(cron, 작업 스케줄러)에서 주기적으로 호출하도록 만든 것이며, 실패한 백업이 있으면 오류를 반환합니다.
+func runDueBackups() error {
+	now := time.Now()
+	schedules, err := scheduledInstances()
+	if err != nil {
+		return err
+	}
+	if len(schedules) == 0 {
+		fmt.Println("ℹ️ 자동 백업 일정이 설정된 인스턴스가 없습니다.")
+		return nil
+	}
+	failed := 0
+	for _, schedule := range schedules {
+		next, err := schedule.nextRun()
+		if err != nil {
+			fmt.Printf("❌ %s: %v\n", schedule.Path, err)
+			failed++
+			continue
+		}
+		if next.After(now) {
+			fmt.Printf("ℹ️ %s: 다음 백업 예정 %s\n", schedule.Path, next.Format("2006-01-02 15:04"))
+			continue
+		}
+		fmt.Printf("\n[ %s 예약 백업 ]\n", schedule.Path)
+		schedule.LastRun = now
+		if _, err := createBackup(schedule.Path, backupReasonSchedule); err != nil {
+			fmt.Println("❌ 백업 실패:", err)
+			schedule.LastError = err.Error()
+			failed++
+		} else {
+			schedule.LastSuccess, schedule.LastError = now, ""
+			if err := pruneBackups(schedule.Path, schedule, now); err != nil {
+				fmt.Println("⚠️ 오래된 백업 정리 실패:", err)
+			}
+		}
+		if err := saveBackupSchedule(schedule); err != nil {
+			fmt.Println("⚠️ 일정 기록 저장 실패:", err)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d개 인스턴스의 예약 백업에 실패했습니다", failed)
+	}
+	return nil
+}
+
+// backupsToPrune은 보관 규칙에 따라 지울 백업을 고릅니다 (backups는 최신순).
+// 가장 최근 백업(이유와 관계없이), 직접 만든 백업, 가장 최근의 예약 백업은 지우지 않고, 그 외에는 최근 keepDaily일의
+// 날짜별/최근 keepWeekly주의 주별 가장 최근 백업만 남깁니다. 업데이트/복원 전 자동 백업이 더 최근에 있어도 예약 백업 하나는 항상 남습니다.
+func backupsToPrune(backups []*backupFile, keepDaily, keepWeekly int) []*backupFile {
+	keep := map[*backupFile]bool{}
+	days, weeks := map[string]bool{}, map[string]bool{}
+	keptScheduled := false
+	if len(backups) > 0 {
+		keep[backups[0]] = true
+	}
+	for _, b := range backups {
+		day := b.Created.Format("2006-01-02")
+		year, week := b.Created.ISOWeek()
+		weekKey := fmt.Sprintf("%d-%02d", year, week)
+		if b.Reason == backupReasonManual {
+			keep[b] = true
+		}
+		if b.Reason == backupReasonSchedule && !keptScheduled {
+			keptScheduled = true
+			keep[b] = true
+		}
+		if !days[day] && len(days) < keepDaily {
+			days[day] = true
+			keep[b] = true
+		}
+		if !weeks[weekKey] && len(weeks) < keepWeekly {
+			weeks[weekKey] = true
+			keep[b] = true
+		}
+	}
+	var prune []*backupFile
+	for _, b := range backups {
+		if !keep[b] {
+			prune = append(prune, b)
+		}
+	}
+	return prune
+}
+
+// pruneBackups는 보관 규칙에 따라 오래된 백업과 중단된 백업(.partial)을 지웁니다.
+func pruneBackups(baseDir string, schedule *backupSchedule, now time.Time) error {
+	backups, err := listBackups(baseDir)
+	if err != nil {
+		return err
+	}
+	for _, b := range backupsToPrune(backups, schedule.KeepDaily, schedule.KeepWeekly) {
+		if err := os.Remove(b.Path); err != nil {
+			return fmt.Errorf("'%s' 삭제 실패: %w", b.Path, err)
+		}
+		fmt.Printf("🗑️ 보관 기간이 지난 백업 삭제: %s\n", filepath.Base(b.Path))
+	}
+	dir, err := backupDir(baseDir)
+	if err != nil {
+		return err
+	}
+	partials, _ := filepath.Glob(filepath.Join(dir, "*"+backupPartialSuffix))
+	for _, p := range partials {
+		if info, err := os.Stat(p); err == nil && now.Sub(info.ModTime()) > partialBackupMaxAge {
+			os.Remove(p)
+		}
+	}
+	return nil
+}
+
+func printBackupSchedule(baseDir string) error {
+	schedule, err := loadBackupSchedule(baseDir)
+	if err != nil {
+		return err
+	}
+	if schedule == nil {
+		fmt.Println("자동 백업 일정이 없습니다.")
+		return nil
+	}
+	fmt.Printf("자동 백업: %s\n", schedule.describe())
+	if !schedule.LastSuccess.IsZero() {
+		fmt.Printf("마지막 성공: %s\n", schedule.LastSuccess.Local().Format("2006-01-02 15:04:05"))
+	}
+	if schedule.LastError != "" {
+		fmt.Printf("⚠️ 마지막 실패 (%s): %s\n", schedule.LastRun.Local().Format("2006-01-02 15:04:05"), schedule.LastError)
+	}
+	if next, err := schedule.nextRun(); err == nil {
+		fmt.Printf("다음 예정: %s (실제 백업은 'backup run-due'가 실행될 때 만들어집니다)\n", next.Local().Format("2006-01-02 15:04"))
+	}
+	return nil
+}
+
+// backupScheduleMenu는 백업 메뉴의 '자동 백업 일정' 항목입니다.
+func backupScheduleMenu() {
+	fmt.Println("\n[ 자동 백업 일정 ]")
+	if err := printBackupSchedule(installDir); err != nil {
+		fmt.Println("❌", err)
+		return
+	}
+	fmt.Println("\n1. 간격으로 설정 (예: 24h, 1d)")
+	fmt.Println("2. cron 식으로 설정 (예: 0 3 * * * = 매일 03:00)")
+	fmt.Println("3. 일정 해제")
+	fmt.Println("4. 돌아가기")
+	fmt.Print("\n선택하세요 (1-4): ")
+
+	var every, cron string
+	switch getUserChoice() {
+	case "1":
+		fmt.Print("백업 간격: ")
+		every = getUserChoice()
+	case "2":
+		fmt.Print("cron 식 (분 시 일 월 요일): ")
+		cron = getUserChoice()
+	case "3":
+		if err := clearBackupSchedule(installDir); err != nil {
+			fmt.Println("❌", err)
+			return
+		}
+		fmt.Println("✅ 자동 백업 일정을 해제했습니다 (기존 백업은 그대로 남습니다).")
+		return
+	default:
+		return
+	}
+	keepDaily := promptCount(fmt.Sprintf("날짜별로 남길 일 수 (비워두면 %d): ", defaultKeepDaily), defaultKeepDaily)
+	keepWeekly := promptCount(fmt.Sprintf("주별로 남길 주 수 (비워두면 %d): ", defaultKeepWeekly), defaultKeepWeekly)
+	schedule, err := setBackupSchedule(every, cron, keepDaily, keepWeekly)
+	if err != nil {
+		fmt.Println("❌", err)
+		return
+	}
+	fmt.Printf("✅ 자동 백업 일정을 설정했습니다: %s\n", schedule.describe())
+	printRunDueHint()
+}
+
+func promptCount(prompt string, def int) int {
+	fmt.Print(prompt)
+	if n, err := strconv.Atoi(getUserChoice()); err == nil && n >= 0 {
+		return n
+	}
+	return def
+}
+
+// printRunDueHint는 OS 스케줄러에 'backup run-due'를 등록하는 방법을 안내합니다.
+func printRunDueHint() {
+	self, err := os.Executable()
+	if err != nil {
+		self = "SillyTavernInstaller"
+	}
+	fmt.Println("ℹ️ 예약 백업은 'backup run-due' 명령이 실행될 때 만들어집니다. OS 스케줄러에 등록해주세요 (예: 15분마다):")
+	if runtime.GOOS == "windows" {
+		fmt.Printf("   schtasks /Create /TN \"SillyTavern\\BackupRunDue\" /SC MINUTE /MO 15 /TR \"\\\"%s\\\" backup run-due\"\n", self)
+	} else {
+		fmt.Printf("   crontab -e 에 추가: */15 * * * * \"%s\" backup run-due\n", self)
+	}
+}
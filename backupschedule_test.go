@@ -0,0 +1,161 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestParseCronField(t *testing.T) {
+	tests := []struct {
+		field    string
+		min, max int
+		want     []int
+	}{
+		{"5", 0, 59, []int{5}},
+		{"1,3,5", 0, 59, []int{1, 3, 5}},
+		{"1-4", 0, 59, []int{1, 2, 3, 4}},
+		{"*/15", 0, 59, []int{0, 15, 30, 45}},
+		{"10-20/5", 0, 59, []int{10, 15, 20}},
+		{"5/20", 0, 59, []int{5, 25, 45}},
+		{"1-3,10,20-22", 1, 31, []int{1, 2, 3, 10, 20, 21, 22}},
+		{"*/2", 0, 7, []int{0, 2, 4, 6}},
+		{"*", 1, 12, []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}},
+	}
+	for _, tt := range tests {
+		set, err := parseCronField(tt.field, tt.min, tt.max)
+		if err != nil {
+			t.Errorf("parseCronField(%q) error: %v", tt.field, err)
+			continue
+		}
+		var got []int
+		for v := range set {
+			got = append(got, v)
+		}
+		sort.Ints(got)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseCronField(%q) = %v, want %v", tt.field, got, tt.want)
+		}
+	}
+
+	for _, field := range []string{"60", "5-1", "*/0", "*/x", "a", "1-b", "", "1,,2", "-1"} {
+		if _, err := parseCronField(field, 0, 59); err == nil {
+			t.Errorf("parseCronField(%q) accepted an invalid field", field)
+		}
+	}
+}
+
+func TestParseCronRejectsWrongFieldCount(t *testing.T) {
+	for _, s := range []string{"", "0 3 * *", "0 3 * * * *"} {
+		if _, err := parseCron(s); err == nil {
+			t.Errorf("parseCron(%q) accepted a wrong number of fields", s)
+		}
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	at := func(s string) time.Time {
+		v, err := time.ParseInLocation("2006-01-02 15:04", s, time.UTC)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+	// 2026-01-01은 목요일, 2026-01-05는 월요일입니다.
+	tests := []struct {
+		expr, after string
+		want        []string
+	}{
+		{"0 3 * * *", "2026-01-01 02:59", []string{"2026-01-01 03:00", "2026-01-02 03:00"}},
+		{"*/20 * * * *", "2026-01-01 10:05", []string{"2026-01-01 10:20", "2026-01-01 10:40", "2026-01-01 11:00"}},
+		{"30 2 1,15 * *", "2026-01-01 03:00", []string{"2026-01-15 02:30", "2026-02-01 02:30"}},
+		{"0 9 * * 1-5", "2026-01-02 09:00", []string{"2026-01-05 09:00", "2026-01-06 09:00"}},
+		{"0 0 31 * *", "2026-01-31 00:00", []string{"2026-03-31 00:00", "2026-05-31 00:00"}},
+		{"0 0 1 */6 *", "2026-01-01 00:00", []string{"2026-07-01 00:00", "2027-01-01 00:00"}},
+		// 일과 요일이 모두 지정되면 둘 중 하나만 맞아도 됩니다 (13일 또는 금요일).
+		{"0 0 13 * 5", "2026-01-01 00:00", []string{"2026-01-02 00:00", "2026-01-09 00:00", "2026-01-13 00:00", "2026-01-16 00:00"}},
+		// '*'로 시작하는 일 필드는 제한 없는 것으로 보므로 요일과 모두 맞아야 합니다 (홀수 날인 월요일).
+		{"0 0 */2 * 1", "2026-01-01 00:00", []string{"2026-01-05 00:00", "2026-01-19 00:00"}},
+		// 일요일은 0과 7 모두 가능합니다.
+		{"0 0 * * 7", "2026-01-01 00:00", []string{"2026-01-04 00:00", "2026-01-11 00:00"}},
+		{"0 0 * * 0", "2026-01-01 00:00", []string{"2026-01-04 00:00"}},
+	}
+	for _, tt := range tests {
+		expr, err := parseCron(tt.expr)
+		if err != nil {
+			t.Fatalf("parseCron(%q): %v", tt.expr, err)
+		}
+		cur := at(tt.after)
+		for _, want := range tt.want {
+			next, err := expr.next(cur)
+			if err != nil || !next.Equal(at(want)) {
+				t.Errorf("%q after %s = %s, %v; want %s", tt.expr, cur.Format("2006-01-02 15:04"), next.Format("2006-01-02 15:04 Mon"), err, want)
+				break
+			}
+			cur = next
+		}
+	}
+
+	expr, err := parseCron("0 0 30 2 *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next, err := expr.next(at("2026-01-01 00:00")); err == nil {
+		t.Errorf("'0 0 30 2 *' (Feb 30) = %s, want error", next)
+	}
+}
+
+func TestBackupsToPrune(t *testing.T) {
+	// 최신순 목록. 2026-01-05(월)~07(수)은 ISO 2주차, 2025-12-29(월)~2026-01-01(목)은 1주차입니다.
+	newBackup := func(when, reason string) *backupFile {
+		created, err := time.Parse("2006-01-02 15:04", when)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &backupFile{Path: filepath.Join("backups", fmt.Sprintf("%s-%s", when, reason)), Created: created, Reason: reason}
+	}
+	backups := []*backupFile{
+		newBackup("2026-01-07 12:00", backupReasonUpdate), // 가장 최근이지만 예약 백업이 아님
+		newBackup("2026-01-07 03:00", backupReasonSchedule),
+		newBackup("2026-01-06 03:00", backupReasonSchedule),
+		newBackup("2026-01-05 15:00", backupReasonManual),
+		newBackup("2026-01-05 03:00", backupReasonSchedule),
+		newBackup("2026-01-01 03:00", backupReasonRestore),
+		newBackup("2025-12-30 03:00", backupReasonSchedule),
+		newBackup("2025-12-20 03:00", backupReasonSchedule),
+	}
+	tests := []struct {
+		keepDaily, keepWeekly int
+		kept                  []int // backups에서 남아야 하는 항목의 위치
+	}{
+		// 보관 개수가 0이면 가장 최근 백업, 직접 만든 백업, 가장 최근 예약 백업만 남습니다.
+		{0, 0, []int{0, 1, 3}},
+		{2, 0, []int{0, 1, 2, 3}},
+		{0, 2, []int{0, 1, 3, 5}},
+		// 01-05의 가장 최근 백업은 직접 만든 백업이므로 같은 날의 예약 백업(4)은 지웁니다.
+		{3, 2, []int{0, 1, 2, 3, 5}},
+		{30, 52, []int{0, 1, 2, 3, 5, 6, 7}},
+	}
+	for _, tt := range tests {
+		pruned := map[*backupFile]bool{}
+		for _, b := range backupsToPrune(backups, tt.keepDaily, tt.keepWeekly) {
+			pruned[b] = true
+		}
+		var kept []int
+		for i, b := range backups {
+			if !pruned[b] {
+				kept = append(kept, i)
+			}
+		}
+		if !reflect.DeepEqual(kept, tt.kept) {
+			t.Errorf("keepDaily=%d keepWeekly=%d: kept %v, want %v", tt.keepDaily, tt.keepWeekly, kept, tt.kept)
+		}
+	}
+
+	if got := backupsToPrune(nil, 0, 0); len(got) != 0 {
+		t.Errorf("backupsToPrune(nil) = %v", got)
+	}
+}
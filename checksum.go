@@ -0,0 +1,192 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// githubAPIBaseURL은 Git for Windows 릴리스 정보(공개 SHA-256)를 조회할 GitHub API 주소입니다.
+var githubAPIBaseURL = "https://api.github.com"
+
+var sha256HexRegex = regexp.MustCompile(`\b[0-9a-fA-F]{64}\b`)
+
+// verifyDownload는 내려받은 설치 파일의 SHA-256을 공개된 값과 비교합니다.
+// 해시를 확인할 수 없거나 일치하지 않으면 파일을 지우고 오류를 반환하므로, 검증되지 않은 파일은 실행되지 않습니다.
+func verifyDownload(downloadURL, filePath string) error {
+	fmt.Println("다운로드한 파일의 SHA-256 검증 중...")
+	expected, source, err := expectedSHA256(downloadURL)
+	if err != nil {
+		os.Remove(filePath)
+		return fmt.Errorf("SHA-256 기준값을 확인할 수 없어 설치를 중단합니다: %w", err)
+	}
+	actual, err := fileSHA256(filePath)
+	if err != nil {
+		os.Remove(filePath)
+		return err
+	}
+	if !strings.EqualFold(expected, actual) {
+		os.Remove(filePath)
+		return fmt.Errorf("SHA-256 불일치로 설치를 중단합니다. 파일이 손상되었거나 변조되었을 수 있습니다.\n   예상값 (%s): %s\n   실제값: %s", source, strings.ToLower(expected), actual)
+	}
+	fmt.Printf("✅ SHA-256 검증 완료 (%s): %s\n", source, actual)
+	return nil
+}
+
+// expectedSHA256은 downloadURL 파일의 SHA-256 기준값과 그 출처를 반환합니다.
+// Git for Windows는 GitHub 릴리스 정보에서, Node.js는 같은 디렉토리의 SHASUMS256.txt에서 확인합니다.
+// 설치 파일 안에 고정한 해시는 없으므로, 오프라인 대체 URL도 해시 목록을 받을 수 없으면 설치를 중단합니다.
+func expectedSHA256(downloadURL string) (sum, source string, err error) {
+	fileName := path.Base(downloadURL)
+	if strings.Contains(downloadURL, "/releases/download/") {
+		sum, err := githubReleaseSHA256(downloadURL, fileName)
+		return sum, "GitHub 릴리스 정보", err
+	}
+	sumsURL := downloadURL[:strings.LastIndex(downloadURL, "/")+1] + "SHASUMS256.txt"
+	sum, err = shasumsLookup(sumsURL, fileName)
+	return sum, "SHASUMS256.txt", err
+}
+
+// shasumsLookup은 "<해시>  <파일 이름>" 형식의 해시 목록(Node.js SHASUMS256.txt)에서 fileName의 해시를 찾습니다.
+func shasumsLookup(sumsURL, fileName string) (string, error) {
+	data, err := httpGetSmall(sumsURL)
+	if err != nil {
+		return "", err
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == fileName && sha256HexRegex.MatchString(fields[0]) {
+			return fields[0], nil
+		}
+	}
+	return "", fmt.Errorf("%s에 %s 항목이 없습니다", sumsURL, fileName)
+}
+
+// githubReleaseSHA256은 GitHub 릴리스 정보에서 파일의 SHA-256을 찾습니다.
+// API의 자산(asset) digest 값을 우선 사용하고, 없으면 Git for Windows 릴리스 노트의 "파일 이름 | SHA-256" 표를 확인합니다.
+// API를 쓸 수 없으면(요청 한도 초과 등) 릴리스 웹 페이지의 같은 표에서 찾습니다.
+func githubReleaseSHA256(downloadURL, fileName string) (string, error) {
+	sum, err := githubReleaseAPISHA256(downloadURL, fileName)
+	if err == nil {
+		return sum, nil
+	}
+	pageURL := downloadURL[:strings.Index(downloadURL, "/releases/download/")] + "/releases/tag/" + path.Base(path.Dir(downloadURL))
+	fmt.Printf("⚠️ GitHub API로 확인하지 못했습니다 (%v). 릴리스 페이지에서 확인합니다...\n", err)
+	page, pageErr := httpGetSmall(pageURL)
+	if pageErr != nil {
+		return "", fmt.Errorf("%v; %w", err, pageErr)
+	}
+	if sum := sha256NearName(string(page), fileName); sum != "" {
+		return sum, nil
+	}
+	return "", fmt.Errorf("%v; 릴리스 페이지(%s)에 %s의 SHA-256이 없습니다", err, pageURL, fileName)
+}
+
+// sha256NearName은 text에서 fileName 바로 뒤(같은 표의 행)에 나오는 SHA-256 값을 찾습니다.
+// 이름이 다른 파일 이름의 일부인 경우(예: PortableGit-...)는 건너뜁니다.
+func sha256NearName(text, fileName string) string {
+	for offset := 0; ; {
+		i := strings.Index(text[offset:], fileName)
+		if i < 0 {
+			return ""
+		}
+		start := offset + i
+		offset = start + len(fileName)
+		if start > 0 && (isAlnum(text[start-1]) || text[start-1] == '-') {
+			continue
+		}
+		end := offset + 300
+		if end > len(text) {
+			end = len(text)
+		}
+		if sum := sha256HexRegex.FindString(text[offset:end]); sum != "" {
+			return sum
+		}
+	}
+}
+
+func isAlnum(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// githubReleaseAPISHA256은 GitHub 릴리스 API 응답(자산 digest, 릴리스 노트)에서 파일의 SHA-256을 찾습니다.
+func githubReleaseAPISHA256(downloadURL, fileName string) (string, error) {
+	// https://github.com/<owner>/<repo>/releases/download/<tag>/<file>
+	parts := strings.Split(strings.SplitN(downloadURL, "://", 2)[1], "/")
+	if len(parts) < 7 {
+		return "", fmt.Errorf("GitHub 릴리스 다운로드 URL 형식이 아닙니다: %s", downloadURL)
+	}
+	owner, repo, tag := parts[1], parts[2], parts[5]
+	apiURL := fmt.Sprintf("%s/repos/%s/%s/releases/tags/%s", strings.TrimRight(githubAPIBaseURL, "/"), owner, repo, tag)
+	data, err := httpGetSmall(apiURL)
+	if err != nil {
+		return "", err
+	}
+	var release struct {
+		Body   string `json:"body"`
+		Assets []struct {
+			Name   string `json:"name"`
+			Digest string `json:"digest"`
+		} `json:"assets"`
+	}
+	if err := json.Unmarshal(data, &release); err != nil {
+		return "", fmt.Errorf("릴리스 정보 파싱 실패 (%s): %w", apiURL, err)
+	}
+	for _, asset := range release.Assets {
+		if asset.Name == fileName && strings.HasPrefix(asset.Digest, "sha256:") {
+			return strings.TrimPrefix(asset.Digest, "sha256:"), nil
+		}
+	}
+	if sum := sha256NearName(release.Body, fileName); sum != "" {
+		return sum, nil
+	}
+	return "", fmt.Errorf("릴리스 %s 정보에 %s의 SHA-256이 없습니다", tag, fileName)
+}
+
+// httpGetSmall은 해시 목록처럼 작은 텍스트/JSON 응답을 받아옵니다 (최대 4MB).
+func httpGetSmall(url string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("요청 생성 실패 (%s): %w", url, err)
+	}
+	req.Header.Set("User-Agent", "SillyTavernInstaller")
+	client := http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP GET 실패 (%s): %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("잘못된 응답 상태코드 (%s): %s", url, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if err != nil {
+		return nil, fmt.Errorf("응답 읽기 실패 (%s): %w", url, err)
+	}
+	return data, nil
+}
+
+// fileSHA256은 파일 내용의 SHA-256을 16진수 소문자로 반환합니다.
+func fileSHA256(filePath string) (string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", fmt.Errorf("파일 열기 실패 (%s): %w", filePath, err)
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("파일 해시 계산 실패 (%s): %w", filePath, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
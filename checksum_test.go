@@ -0,0 +1,120 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const (
+	goodInstaller     = "good installer payload"
+	tamperedInstaller = "good installer payload, but tampered"
+)
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// downloadAndVerify는 url을 임시 디렉토리로 내려받아 verifyDownload를 실행하고, 결과와 파일 경로를 반환합니다.
+func downloadAndVerify(t *testing.T, url string) (string, error) {
+	t.Helper()
+	target := filepath.Join(t.TempDir(), filepath.Base(url))
+	if err := downloadFile(url, target); err != nil {
+		t.Fatalf("downloadFile(%s): %v", url, err)
+	}
+	return target, verifyDownload(url, target)
+}
+
+func assertVerified(t *testing.T, url string, wantOK bool) {
+	t.Helper()
+	target, err := downloadAndVerify(t, url)
+	_, statErr := os.Stat(target)
+	switch {
+	case wantOK && err != nil:
+		t.Errorf("verifyDownload(%s) = %v, want nil", url, err)
+	case wantOK && statErr != nil:
+		t.Errorf("verified file %s is missing: %v", target, statErr)
+	case !wantOK && err == nil:
+		t.Errorf("verifyDownload(%s) accepted a tampered file", url)
+	case !wantOK && !os.IsNotExist(statErr):
+		t.Errorf("tampered file %s was not removed", target)
+	}
+}
+
+func TestVerifyDownloadSHASUMS(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/dist/v1/SHASUMS256.txt", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s  node-v1-x64.msi\n%s  node-v1-arm64.msi\n", sha256Hex(goodInstaller), sha256Hex(goodInstaller))
+	})
+	mux.HandleFunc("/dist/v1/node-v1-x64.msi", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, goodInstaller)
+	})
+	mux.HandleFunc("/dist/v1/node-v1-arm64.msi", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, tamperedInstaller)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	assertVerified(t, srv.URL+"/dist/v1/node-v1-x64.msi", true)
+	assertVerified(t, srv.URL+"/dist/v1/node-v1-arm64.msi", false)
+}
+
+func TestVerifyDownloadOfflineFallbackWithoutHash(t *testing.T) {
+	// 최신 버전 조회가 실패해 대체 URL을 쓰더라도, SHASUMS256.txt를 받을 수 없으면 설치 파일을 남기지 않습니다.
+	mux := http.NewServeMux()
+	mux.HandleFunc("/dist/"+nodeJSFallbackVersion+"/", func(w http.ResponseWriter, r *http.Request) {
+		if filepath.Base(r.URL.Path) == "SHASUMS256.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, goodInstaller)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	savedBase := nodeDistBaseURL
+	defer func() { nodeDistBaseURL = savedBase }()
+	nodeDistBaseURL = srv.URL + "/dist"
+	t.Setenv(nodeMirrorEnv, "")
+
+	assertVerified(t, fallbackNodeInstallerURL(), false)
+}
+
+func TestVerifyDownloadGitHubRelease(t *testing.T) {
+	const file = "Git-1.0-64-bit.exe"
+	apiDown := false
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/repos/o/r/releases/tags/v1", func(w http.ResponseWriter, r *http.Request) {
+		if apiDown {
+			http.Error(w, "API rate limit exceeded", http.StatusForbidden)
+			return
+		}
+		fmt.Fprintf(w, `{"body":"Filename | SHA-256\n-------- | -------\nPortableGit-1.0-64-bit.exe | %s\n%s | %s\n","assets":[]}`,
+			sha256Hex("other"), file, sha256Hex(goodInstaller))
+	})
+	mux.HandleFunc("/o/r/releases/tag/v1", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "<table>\n<tr>\n<td>Portable%s</td>\n<td>%s</td>\n</tr>\n<tr>\n<td>%s</td>\n<td>%s</td>\n</tr>\n</table>\n",
+			file, sha256Hex("other"), file, sha256Hex(goodInstaller))
+	})
+	mux.HandleFunc("/o/r/releases/download/v1/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, goodInstaller)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	saved := githubAPIBaseURL
+	defer func() { githubAPIBaseURL = saved }()
+	githubAPIBaseURL = srv.URL + "/api"
+
+	url := srv.URL + "/o/r/releases/download/v1/" + file
+	assertVerified(t, url, true)
+	// API 요청 한도를 넘으면 릴리스 페이지의 표에서 확인합니다.
+	apiDown = true
+	assertVerified(t, url, true)
+}
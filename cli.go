@@ -0,0 +1,911 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// 서브커맨드 실행 시 종료 코드
+const (
+	exitOK               = 0 // 성공
+	exitFailure          = 1 // 작업 실패 (git/npm/설정 저장 오류 등)
+	exitUsage            = 2 // 잘못된 명령 또는 인자
+	exitDependency       = 3 // Git/Node.js 등 필수 프로그램을 사용할 수 없음
+	exitNotInstalled     = 4 // SillyTavern이 설치되어 있지 않음
+	exitUpdatesAvailable = 5 // check-update: 새 업데이트가 있음 (모니터링용)
+)
+
+const cliUsage = `사용법: SillyTavernInstaller [명령] [옵션]
+
+명령 없이 실행하면 메뉴 화면이 시작됩니다.
+
+명령:
+  install [--branch 이름] [--portable-node]
+                                   새로 설치하거나, 이미 설치되어 있으면 업데이트
+                                   (--portable-node: 인스턴스 전용 Node.js를 내려받아 사용)
+  update [--on-conflict 방법]      설치된 SillyTavern을 현재 브랜치 기준으로 업데이트 (직접 수정한 소스 파일은
+                                   임시 저장 후 다시 적용, 충돌 시 mine: 내 버전, theirs: 새 버전,
+                                   abort: 업데이트 이전 커밋으로 되돌림(기본))
+  check-update                     원격 저장소의 새 커밋과 바뀐 파일(package.json, default/config.yaml 강조)을
+                                   미리 보기 (업데이트는 하지 않음, 새 업데이트가 있으면 종료 코드 5)
+  switch-branch <이름> [--on-conflict 방법]
+                                   브랜치 변경 (예: release, staging)
+  set-port <포트>                  config.yaml의 포트 변경
+  config-diff [--merge]            config.yaml을 기본 설정(default/config.yaml)과 비교해 추가/삭제/이름이 바뀐 항목 출력
+                                   (--merge 또는 --yes: 새 항목을 기존 값은 그대로 둔 채 추가, 업데이트 후에도 자동 확인)
+  whitelist list                   화이트리스트와 whitelistMode 출력
+  whitelist add <IP|CIDR>...       화이트리스트에 항목 추가 (IPv4/IPv6 주소, CIDR 대역)
+  whitelist remove <IP|CIDR>...    화이트리스트에서 항목 제거
+  whitelist set <IP|CIDR>...       화이트리스트 전체 교체
+  whitelist clear                  화이트리스트 비우기
+  whitelist mode <on|off>          whitelistMode 켜기/끄기
+  set-dir <경로>                   설치 경로를 저장하여 다음 실행부터 기본으로 사용
+  instances list                   등록된 인스턴스 목록 (브랜치, 포트, 현재 커밋, 마지막 업데이트)
+  instances add <이름> <경로> [--branch 이름] [--port 포트]
+                                   인스턴스 등록 (설치는 install --instance <이름>으로 진행)
+  instances remove <이름>          인스턴스 등록 해제 (파일은 삭제하지 않음)
+  instances use <이름>             기본 작업 대상으로 사용할 인스턴스 선택
+  pin status                       버전 고정 상태 출력
+  pin tags [--limit N]             원격 태그 목록 (git ls-remote --tags, 최신순)
+  pin tag <태그>                   태그(vX.Y.Z)로 설치/업데이트 후 고정
+  pin commit <SHA>                 커밋으로 체크아웃 후 고정
+  pin clear                        고정 해제 후 브랜치 최신 버전으로 복귀
+  rollback list                    업데이트/브랜치 변경 이전에 기록된 버전 목록
+  rollback [번호|ID]               기록된 버전으로 되돌리기 (생략 시 가장 최근 이전 버전)
+  start [--open] [--background]    SillyTavern 실행 (Ctrl+C로 종료, --open: 실행되면 브라우저로 열기,
+                                   --background: 백그라운드로 실행하고 비정상 종료 시 자동 재시작)
+  stop                             실행 중인 SillyTavern 중지
+  restart                          SillyTavern 재시작 (백그라운드로 다시 시작)
+  status                           실행 여부, 가동 시간, 포트 응답 여부 출력
+  logs [--lines N] [--follow]      SillyTavern 로그의 마지막 N줄(기본 50) 출력 (--follow: 계속 보기)
+  logs --search <텍스트>           모든 로그 파일에서 검색 (대소문자 무시)
+  backup [create]                  데이터 디렉토리(dataRoot)와 config.yaml 백업 (백업 후 검증)
+  backup list                      보관 중인 백업 목록
+  backup verify <번호|파일>        백업 파일 검증
+  backup schedule                  자동 백업 일정 출력
+  backup schedule --every <간격> | --cron "<식>" [--keep-daily N] [--keep-weekly M]
+                                   자동 백업 일정 설정 (간격 예: 12h, 1d / cron 예: "0 3 * * *",
+                                   최근 N일은 하루 1개, 최근 M주는 주 1개 보관, 기본 7/4)
+  backup schedule off              자동 백업 일정 해제 (기존 백업은 그대로 남음)
+  backup run-due                   일정이 설정된 모든 인스턴스에서 예정 시각이 지난 백업을 만들고 오래된
+                                   백업 정리 (OS 스케줄러에서 주기적으로 실행, 실패가 있으면 종료 코드 1)
+  restore <번호|파일> [--from 인스턴스] [--no-config]
+                                   백업을 대상 인스턴스(--dir/--instance)에 복원 (번호는 --from 인스턴스의
+                                   목록 기준, 기본은 대상 인스턴스, --no-config: config.yaml은 그대로 둠)
+  service install [--system] [--no-enable]
+                                   부팅 시 자동 실행되도록 서비스로 등록 (Linux: systemd 사용자 유닛,
+                                   --system: 시스템 유닛, Windows: 작업 스케줄러, --no-enable: 파일만 작성)
+  service uninstall                서비스 중지 및 등록 해제
+  service status                   서비스 상태 출력
+  service print [--system]         등록할 유닛 파일(Windows: 작업 XML) 내용 출력
+  node-runtime [status]            인스턴스의 Node.js 실행 환경(시스템/인스턴스 전용) 출력
+  node-runtime <system|portable>   시스템 Node.js 또는 인스턴스 전용 Node.js(runtime/ 폴더) 사용
+  help                             이 도움말 출력
+
+공통 옵션:
+  --dir <경로>                     SillyTavern 설치 경로 (명령 앞/뒤 모두 가능, 메뉴 실행에도 적용)
+  --instance <이름>                등록된 인스턴스를 작업 대상으로 사용
+  -y, --yes                        (y/n) 질문에 자동으로 'y' 응답 (예: Git/Node.js 자동 설치)
+
+설치 경로 우선순위:
+  --dir 옵션 > --instance 옵션 > SILLYTAVERN_DIR 환경 변수 > 선택된 인스턴스(instances use)
+  > 저장된 설정(set-dir) > 현재 디렉토리의 SillyTavern
+
+종료 코드:
+  0 성공, 1 작업 실패, 2 잘못된 명령/인자(등록되지 않은 인스턴스 포함), 3 필수 프로그램 없음,
+  4 SillyTavern 미설치, 5 새 업데이트 있음 (check-update)
+`
+
+// runCLI는 서브커맨드를 실행하고 프로세스 종료 코드를 반환합니다.
+func runCLI(args []string) int {
+	nonInteractive = true
+
+	command, rest := args[0], args[1:]
+	switch command {
+	case "install":
+		return cliInstall(rest)
+	case "update":
+		return cliUpdate(rest)
+	case "check-update":
+		return cliCheckUpdate(rest)
+	case "switch-branch":
+		return cliSwitchBranch(rest)
+	case "set-port":
+		return cliSetPort(rest)
+	case "config-diff":
+		return cliConfigDiff(rest)
+	case "whitelist":
+		return cliWhitelist(rest)
+	case "set-dir":
+		return cliSetDir(rest)
+	case "instances":
+		return cliInstances(rest)
+	case "pin":
+		return cliPin(rest)
+	case "rollback":
+		return cliRollback(rest)
+	case "start":
+		return cliStart(rest)
+	case "stop":
+		return cliStop(rest)
+	case "restart":
+		return cliRestart(rest)
+	case "status":
+		return cliStatus(rest)
+	case "logs":
+		return cliLogs(rest)
+	case "service":
+		return cliService(rest)
+	case "backup":
+		return cliBackup(rest)
+	case "restore":
+		return cliRestore(rest)
+	case supervisorCommand:
+		return cliSupervise(rest)
+	case "node-runtime":
+		return cliNodeRuntime(rest)
+	case "help", "-h", "--help":
+		fmt.Print(cliUsage)
+		return exitOK
+	default:
+		fmt.Fprintf(os.Stderr, "알 수 없는 명령입니다: %s\n\n", command)
+		fmt.Fprint(os.Stderr, cliUsage)
+		return exitUsage
+	}
+}
+
+var installDirFlag string // --dir 옵션 값
+
+// parseGlobalFlags는 명령 이름 앞에 오는 공통 옵션(--dir)을 해석하고 나머지 인자를 반환합니다.
+func parseGlobalFlags(args []string) ([]string, error) {
+	fs := flag.NewFlagSet("SillyTavernInstaller", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.StringVar(&installDirFlag, "dir", installDirFlag, "SillyTavern 설치 경로")
+	fs.StringVar(&instanceFlag, "instance", instanceFlag, "작업 대상 인스턴스 이름")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return []string{"help"}, nil
+		}
+		return nil, err
+	}
+	return fs.Args(), nil
+}
+
+// newFlagSet은 공통 옵션(-y/--yes, --dir, --instance)이 등록된 서브커맨드용 FlagSet을 만듭니다.
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.BoolVar(&assumeYes, "yes", false, "(y/n) 질문에 자동으로 'y' 응답")
+	fs.BoolVar(&assumeYes, "y", false, "(y/n) 질문에 자동으로 'y' 응답")
+	fs.StringVar(&installDirFlag, "dir", installDirFlag, "SillyTavern 설치 경로")
+	fs.StringVar(&instanceFlag, "instance", instanceFlag, "작업 대상 인스턴스 이름")
+	return fs
+}
+
+// parseFlags는 옵션과 위치 인자가 섞여 있어도 모두 해석하고 위치 인자만 반환합니다.
+// 해석이 끝나면 설치 경로(installDir)를 확정합니다.
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, applyInstallDir()
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+func usageError(format string, a ...interface{}) int {
+	fmt.Fprintf(os.Stderr, "❌ "+format+"\n", a...)
+	fmt.Fprintln(os.Stderr, "도움말: SillyTavernInstaller help")
+	return exitUsage
+}
+
+// exitCodeFor는 작업 오류를 종료 코드로 변환합니다.
+func exitCodeFor(err error) int {
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, errDependencyMissing):
+		return exitDependency
+	case errors.Is(err, errNotInstalled):
+		return exitNotInstalled
+	case errors.Is(err, errUnknownInstance):
+		return exitUsage
+	default:
+		return exitFailure
+	}
+}
+
+// reportFlagError는 옵션 해석 오류와 설치 경로/인스턴스 결정 오류를 구분하여 보고합니다.
+func reportFlagError(command string, err error) int {
+	if errors.Is(err, errUnknownInstance) {
+		return reportCLIError(err)
+	}
+	return usageError("%s: %v", command, err)
+}
+
+func reportCLIError(err error) int {
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "❌", err)
+	}
+	return exitCodeFor(err)
+}
+
+func cliInstall(args []string) int {
+	fs := newFlagSet("install")
+	branch := fs.String("branch", "", "새로 설치할 때 사용할 브랜치")
+	portableNode := fs.Bool("portable-node", false, "인스턴스 전용 Node.js 사용")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return reportFlagError("install", err)
+	}
+	if *branch == "" {
+		*branch = installBranchForCurrent()
+	}
+	if len(positional) > 0 {
+		return usageError("install: 알 수 없는 인자입니다: %s", strings.Join(positional, " "))
+	}
+	if *portableNode {
+		if err := setNodeRuntime(installDir, nodeRuntimePortable); err != nil {
+			return reportCLIError(err)
+		}
+	}
+	if err := checkDependencies(); err != nil {
+		return reportCLIError(err)
+	}
+	if err := installOrUpdate(installDir, *branch); err != nil {
+		return reportCLIError(err)
+	}
+	fmt.Println("\n✅ 설치/업데이트 완료!")
+	return exitOK
+}
+
+func cliUpdate(args []string) int {
+	fs := newFlagSet("update")
+	onConflict := fs.String("on-conflict", conflictAbort, "충돌 시 해결 방법 (mine, theirs, abort)")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return reportFlagError("update", err)
+	}
+	if err := setConflictResolution(*onConflict); err != nil {
+		return usageError("update: %v", err)
+	}
+	if len(positional) > 0 {
+		return usageError("update: 알 수 없는 인자입니다: %s", strings.Join(positional, " "))
+	}
+	if _, err := os.Stat(filepath.Join(installDir, ".git")); os.IsNotExist(err) {
+		return reportCLIError(fmt.Errorf("%w: %s", errNotInstalled, installDir))
+	}
+	if err := checkDependencies(); err != nil {
+		return reportCLIError(err)
+	}
+	if err := installOrUpdate(installDir, installBranchForCurrent()); err != nil {
+		return reportCLIError(err)
+	}
+	fmt.Println("\n✅ 업데이트 완료!")
+	return exitOK
+}
+
+func cliCheckUpdate(args []string) int {
+	fs := newFlagSet("check-update")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return reportFlagError("check-update", err)
+	}
+	if len(positional) > 0 {
+		return usageError("check-update: 알 수 없는 인자입니다: %s", strings.Join(positional, " "))
+	}
+	if err := checkDependencies(); err != nil {
+		return reportCLIError(err)
+	}
+	available, err := checkForUpdates(installDir)
+	if err != nil {
+		return reportCLIError(err)
+	}
+	if available {
+		fmt.Println("\nℹ️ 업데이트하려면 'update' 명령을 실행하세요.")
+		return exitUpdatesAvailable
+	}
+	return exitOK
+}
+
+func cliSwitchBranch(args []string) int {
+	fs := newFlagSet("switch-branch")
+	onConflict := fs.String("on-conflict", conflictAbort, "충돌 시 해결 방법 (mine, theirs, abort)")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return reportFlagError("switch-branch", err)
+	}
+	if err := setConflictResolution(*onConflict); err != nil {
+		return usageError("switch-branch: %v", err)
+	}
+	if len(positional) != 1 {
+		return usageError("switch-branch: 브랜치 이름 하나가 필요합니다 (예: %s, %s)", defaultBranch, stagingBranch)
+	}
+	if err := checkDependencies(); err != nil {
+		return reportCLIError(err)
+	}
+	if err := switchBranchTo(installDir, positional[0]); err != nil {
+		return reportCLIError(err)
+	}
+	return exitOK
+}
+
+func cliConfigDiff(args []string) int {
+	fs := newFlagSet("config-diff")
+	merge := fs.Bool("merge", false, "새 기본 설정 항목을 config.yaml에 추가")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return reportFlagError("config-diff", err)
+	}
+	if len(positional) > 0 {
+		return usageError("config-diff: 알 수 없는 인자입니다: %s", strings.Join(positional, " "))
+	}
+	configPath, err := getConfigPath()
+	if err != nil {
+		return reportCLIError(err)
+	}
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		return reportCLIError(fmt.Errorf("%s가 없습니다 (SillyTavern을 처음 실행하면 기본 설정으로 만들어집니다)", configPath))
+	}
+	if _, err := os.Stat(filepath.Join(installDir, "default", configFileName)); os.IsNotExist(err) {
+		return reportCLIError(fmt.Errorf("기본 설정 파일(default/%s)이 없습니다", configFileName))
+	}
+	return reportCLIError(reviewConfigDefaults(installDir, *merge))
+}
+
+func cliSetPort(args []string) int {
+	fs := newFlagSet("set-port")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return reportFlagError("set-port", err)
+	}
+	if len(positional) != 1 {
+		return usageError("set-port: 포트 번호 하나가 필요합니다")
+	}
+	port, err := parsePort(positional[0])
+	if err != nil {
+		return usageError("set-port: %v", err)
+	}
+	configPath, err := getConfigPath()
+	if err != nil {
+		return reportCLIError(err)
+	}
+	if err := setPort(configPath, port); err != nil {
+		return reportCLIError(err)
+	}
+	fmt.Printf("✅ 포트가 %d로 변경되었습니다. SillyTavern을 재시작해야 적용됩니다.\n", port)
+	offerRestart(installDir)
+	return exitOK
+}
+
+func cliWhitelist(args []string) int {
+	fs := newFlagSet("whitelist")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return reportFlagError("whitelist", err)
+	}
+	if len(positional) == 0 {
+		return usageError("whitelist: 하위 명령(list, add, remove, set, clear, mode)이 필요합니다")
+	}
+	action, values := positional[0], positional[1:]
+
+	configPath, err := getConfigPath()
+	if err != nil {
+		return reportCLIError(err)
+	}
+
+	var whitelist []string
+	switch action {
+	case "list":
+		config, err := loadConfig(configPath)
+		if err != nil {
+			return reportCLIError(err)
+		}
+		fmt.Println("# whitelistMode:", whitelistModeLabel(config))
+		for _, entry := range whitelistFromConfig(config) {
+			fmt.Println(entry)
+		}
+		return exitOK
+	case "add", "remove", "set":
+		if len(values) == 0 {
+			return usageError("whitelist %s: IP 주소 또는 CIDR 대역을 하나 이상 입력해주세요", action)
+		}
+		switch action {
+		case "add":
+			whitelist, err = addToWhitelist(configPath, splitListArgs(values))
+		case "remove":
+			whitelist, err = removeFromWhitelist(configPath, splitListArgs(values))
+		case "set":
+			whitelist, err = replaceWhitelist(configPath, splitListArgs(values))
+		}
+	case "clear":
+		if len(values) > 0 {
+			return usageError("whitelist clear: 인자가 필요하지 않습니다")
+		}
+		whitelist, err = replaceWhitelist(configPath, nil)
+	case "mode":
+		if len(values) != 1 || (values[0] != "on" && values[0] != "off") {
+			return usageError("whitelist mode: on 또는 off를 입력해주세요")
+		}
+		if err := setWhitelistMode(configPath, values[0] == "on"); err != nil {
+			return reportCLIError(err)
+		}
+		fmt.Printf("✅ whitelistMode를 %s(으)로 변경했습니다. SillyTavern을 재시작해야 적용됩니다.\n", values[0])
+		offerRestart(installDir)
+		return exitOK
+	default:
+		return usageError("whitelist: 알 수 없는 하위 명령입니다: %s", action)
+	}
+	if err != nil {
+		return reportCLIError(err)
+	}
+	fmt.Println("✅ 화이트리스트가 업데이트되었습니다. SillyTavern을 재시작해야 적용됩니다.")
+	printFinalWhitelist(whitelist)
+	offerRestart(installDir)
+	return exitOK
+}
+
+// splitListArgs는 "a,b c" 처럼 쉼표와 공백이 섞인 인자를 개별 항목으로 나눕니다.
+func splitListArgs(values []string) []string {
+	var items []string
+	for _, v := range values {
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+	}
+	return items
+}
+
+func cliSetDir(args []string) int {
+	fs := newFlagSet("set-dir")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return reportFlagError("set-dir", err)
+	}
+	if len(positional) != 1 {
+		return usageError("set-dir: 설치 경로 하나가 필요합니다 (공백이 있으면 따옴표로 감싸주세요)")
+	}
+	dir, err := normalizePath(positional[0])
+	if err != nil {
+		return usageError("set-dir: %v", err)
+	}
+	if err := saveInstallDirPreference(dir); err != nil {
+		return reportCLIError(err)
+	}
+	fmt.Printf("✅ 설치 경로가 '%s'(으)로 저장되었습니다.\n", dir)
+	return exitOK
+}
+
+func cliInstances(args []string) int {
+	fs := newFlagSet("instances")
+	branch := fs.String("branch", defaultBranch, "인스턴스 브랜치")
+	port := fs.Int("port", 0, "인스턴스 포트 (기록용)")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return reportFlagError("instances", err)
+	}
+	if len(positional) == 0 {
+		return usageError("instances: 하위 명령(list, add, remove, use)이 필요합니다")
+	}
+	action, values := positional[0], positional[1:]
+
+	switch action {
+	case "list":
+		registry, err := loadRegistry()
+		if err != nil {
+			return reportCLIError(err)
+		}
+		printInstanceList(registry)
+		return exitOK
+	case "add":
+		if len(values) != 2 {
+			return usageError("instances add: <이름> <경로>가 필요합니다")
+		}
+		if *port < 0 || *port > 65535 {
+			return usageError("instances add: 잘못된 포트 번호입니다: %d", *port)
+		}
+		inst, err := addInstance(values[0], values[1], *branch, *port)
+		if err != nil {
+			return reportCLIError(err)
+		}
+		fmt.Printf("✅ 인스턴스 '%s'을(를) 추가했습니다 (경로: %s, 브랜치: %s).\n", inst.Name, inst.Path, inst.Branch)
+		return exitOK
+	case "remove", "use":
+		if len(values) != 1 {
+			return usageError("instances %s: 인스턴스 이름 하나가 필요합니다", action)
+		}
+		if action == "remove" {
+			err = removeInstance(values[0])
+		} else {
+			err = selectInstance(values[0])
+		}
+		if err != nil {
+			return reportCLIError(err)
+		}
+		if action == "remove" {
+			fmt.Printf("✅ 인스턴스 '%s'의 등록을 해제했습니다.\n", values[0])
+		} else {
+			fmt.Printf("✅ 인스턴스 '%s'(%s)를 기본 작업 대상으로 선택했습니다.\n", currentInstance.Name, currentInstance.Path)
+		}
+		return exitOK
+	default:
+		return usageError("instances: 알 수 없는 하위 명령입니다: %s", action)
+	}
+}
+
+func cliPin(args []string) int {
+	fs := newFlagSet("pin")
+	limit := fs.Int("limit", pinTagListLimit, "출력할 태그 수 (0이면 전체)")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return reportFlagError("pin", err)
+	}
+	if len(positional) == 0 {
+		return usageError("pin: 하위 명령(status, tags, tag, commit, clear)이 필요합니다")
+	}
+	action, values := positional[0], positional[1:]
+
+	switch action {
+	case "status":
+		printPinStatus(installDir)
+		return exitOK
+	case "tags":
+		tags, err := listRemoteTags()
+		if err != nil {
+			return reportCLIError(err)
+		}
+		if *limit > 0 && len(tags) > *limit {
+			tags = tags[:*limit]
+		}
+		for _, tag := range tags {
+			fmt.Println(tag)
+		}
+		return exitOK
+	case "tag", "commit":
+		if len(values) != 1 {
+			return usageError("pin %s: 값 하나가 필요합니다", action)
+		}
+		if err := checkDependencies(); err != nil {
+			return reportCLIError(err)
+		}
+		kind := pinKindTag
+		if action == "commit" {
+			kind = pinKindCommit
+		}
+		return reportCLIError(pinVersion(installDir, kind, values[0]))
+	case "clear":
+		if err := checkDependencies(); err != nil {
+			return reportCLIError(err)
+		}
+		return reportCLIError(clearPin(installDir))
+	default:
+		return usageError("pin: 알 수 없는 하위 명령입니다: %s", action)
+	}
+}
+
+func cliRollback(args []string) int {
+	fs := newFlagSet("rollback")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return reportFlagError("rollback", err)
+	}
+	if len(positional) > 1 {
+		return usageError("rollback: list 또는 되돌릴 번호/ID 하나만 입력해주세요")
+	}
+	if _, err := os.Stat(filepath.Join(installDir, ".git")); os.IsNotExist(err) {
+		return reportCLIError(fmt.Errorf("%w: %s", errNotInstalled, installDir))
+	}
+	index, _, err := loadSnapshots(installDir)
+	if err != nil {
+		return reportCLIError(err)
+	}
+	head, _ := gitHeadCommit(installDir)
+	input := ""
+	if len(positional) == 1 {
+		input = positional[0]
+	}
+	if input == "list" {
+		printSnapshotList(index, head)
+		return exitOK
+	}
+
+	target, err := index.pick(input, head)
+	if err != nil {
+		return reportCLIError(err)
+	}
+	if err := checkDependencies(); err != nil {
+		return reportCLIError(err)
+	}
+	return reportCLIError(rollbackTo(installDir, target))
+}
+
+func cliNodeRuntime(args []string) int {
+	fs := newFlagSet("node-runtime")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return reportFlagError("node-runtime", err)
+	}
+	if len(positional) > 1 {
+		return usageError("node-runtime: status, system, portable 중 하나만 입력해주세요")
+	}
+	action := "status"
+	if len(positional) == 1 {
+		action = positional[0]
+	}
+	switch action {
+	case "status":
+		printNodeRuntimeStatus(installDir)
+		return exitOK
+	case "system":
+		return reportCLIError(changeNodeRuntime(installDir, nodeRuntimeSystem))
+	case "portable":
+		if err := changeNodeRuntime(installDir, nodeRuntimePortable); err != nil {
+			return reportCLIError(fmt.Errorf("%w: %v", errDependencyMissing, err))
+		}
+		return exitOK
+	default:
+		return usageError("node-runtime: 알 수 없는 하위 명령입니다: %s", action)
+	}
+}
+
+func cliStart(args []string) int {
+	fs := newFlagSet("start")
+	openBrowser := fs.Bool("open", false, "실행되면 브라우저로 열기")
+	background := fs.Bool("background", false, "백그라운드로 실행")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return reportFlagError("start", err)
+	}
+	if len(positional) > 0 {
+		return usageError("start: 알 수 없는 인자입니다: %s", strings.Join(positional, " "))
+	}
+	if err := checkDependencies(); err != nil {
+		return reportCLIError(err)
+	}
+	if *background {
+		return reportCLIError(startBackground(installDir, *openBrowser))
+	}
+	return reportCLIError(startSillyTavern(installDir, *openBrowser))
+}
+
+func cliStop(args []string) int {
+	fs := newFlagSet("stop")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return reportFlagError("stop", err)
+	}
+	if len(positional) > 0 {
+		return usageError("stop: 알 수 없는 인자입니다: %s", strings.Join(positional, " "))
+	}
+	if err := stopServer(installDir); errors.Is(err, errServerNotRunning) {
+		fmt.Println("ℹ️", err)
+		return exitOK
+	} else if err != nil {
+		return reportCLIError(err)
+	}
+	return exitOK
+}
+
+func cliRestart(args []string) int {
+	fs := newFlagSet("restart")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return reportFlagError("restart", err)
+	}
+	if len(positional) > 0 {
+		return usageError("restart: 알 수 없는 인자입니다: %s", strings.Join(positional, " "))
+	}
+	if err := checkDependencies(); err != nil {
+		return reportCLIError(err)
+	}
+	return reportCLIError(restartServer(installDir))
+}
+
+func cliStatus(args []string) int {
+	fs := newFlagSet("status")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return reportFlagError("status", err)
+	}
+	if len(positional) > 0 {
+		return usageError("status: 알 수 없는 인자입니다: %s", strings.Join(positional, " "))
+	}
+	if _, err := printServerStatus(installDir); err != nil {
+		return reportCLIError(err)
+	}
+	return exitOK
+}
+
+func cliLogs(args []string) int {
+	fs := newFlagSet("logs")
+	lines := fs.Int("lines", defaultTailLines, "출력할 줄 수")
+	follow := fs.Bool("follow", false, "새로 기록되는 내용을 계속 출력")
+	search := fs.String("search", "", "검색할 텍스트")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return reportFlagError("logs", err)
+	}
+	if len(positional) > 0 {
+		return usageError("logs: 알 수 없는 인자입니다: %s", strings.Join(positional, " "))
+	}
+	if *lines < 0 {
+		return usageError("logs: --lines는 0 이상이어야 합니다")
+	}
+	if *search != "" {
+		if *follow {
+			return usageError("logs: --search와 --follow는 함께 사용할 수 없습니다")
+		}
+		found, err := searchServerLog(installDir, *search)
+		if err != nil {
+			return reportCLIError(err)
+		}
+		if found == 0 {
+			fmt.Printf("ℹ️ '%s'이(가) 들어 있는 줄이 없습니다.\n", *search)
+			return exitFailure
+		}
+		return exitOK
+	}
+	return reportCLIError(printServerLog(installDir, *lines, *follow))
+}
+
+func cliBackup(args []string) int {
+	fs := newFlagSet("backup")
+	every := fs.String("every", "", "자동 백업 간격 (예: 12h, 1d)")
+	cron := fs.String("cron", "", "자동 백업 cron 식 (분 시 일 월 요일)")
+	keepDaily := fs.Int("keep-daily", -1, "날짜별로 남길 일 수")
+	keepWeekly := fs.Int("keep-weekly", -1, "주별로 남길 주 수")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return reportFlagError("backup", err)
+	}
+	action := "create"
+	if len(positional) > 0 {
+		action = positional[0]
+	}
+	switch {
+	case action == "create" && len(positional) <= 1:
+		_, err := createBackup(installDir, backupReasonManual)
+		return reportCLIError(err)
+	case action == "list" && len(positional) == 1:
+		backups, err := listBackups(installDir)
+		if err != nil {
+			return reportCLIError(err)
+		}
+		printBackupList(backups)
+		return exitOK
+	case action == "verify" && len(positional) == 2:
+		archivePath, err := resolveBackupPath(installDir, positional[1])
+		if err != nil {
+			return reportCLIError(err)
+		}
+		manifest, err := verifyBackup(archivePath)
+		if err != nil {
+			return reportCLIError(fmt.Errorf("백업 파일 검증 실패: %w", err))
+		}
+		fmt.Printf("✅ 백업 파일이 정상입니다 (파일 %d개, %s에서 생성).\n", len(manifest.Files), manifest.Source)
+		return exitOK
+	case action == "verify":
+		return usageError("backup verify: 백업 번호 또는 파일 경로를 하나 입력해주세요")
+	case action == "schedule" && len(positional) == 2 && positional[1] == "off":
+		if err := clearBackupSchedule(installDir); err != nil {
+			return reportCLIError(err)
+		}
+		fmt.Println("✅ 자동 백업 일정을 해제했습니다 (기존 백업은 그대로 남습니다).")
+		return exitOK
+	case action == "schedule" && len(positional) == 1 && *every == "" && *cron == "":
+		return reportCLIError(printBackupSchedule(installDir))
+	case action == "schedule" && len(positional) == 1:
+		if *every != "" && *cron != "" {
+			return usageError("backup schedule: --every와 --cron 중 하나만 지정해주세요")
+		}
+		schedule, err := setBackupSchedule(*every, *cron, *keepDaily, *keepWeekly)
+		if err != nil {
+			return reportCLIError(err)
+		}
+		fmt.Printf("✅ 자동 백업 일정을 설정했습니다: %s\n", schedule.describe())
+		printRunDueHint()
+		return exitOK
+	case action == "run-due" && len(positional) == 1:
+		return reportCLIError(runDueBackups())
+	default:
+		return usageError("backup: 알 수 없는 하위 명령이거나 인자가 잘못되었습니다: %s", strings.Join(positional, " "))
+	}
+}
+
+func cliRestore(args []string) int {
+	fs := newFlagSet("restore")
+	from := fs.String("from", "", "백업 번호를 찾을 인스턴스 (이름 또는 경로)")
+	noConfig := fs.Bool("no-config", false, "config.yaml은 복원하지 않음")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return reportFlagError("restore", err)
+	}
+	if len(positional) != 1 {
+		return usageError("restore: 백업 번호 또는 파일 경로를 하나 입력해주세요")
+	}
+	sourceDir := installDir
+	if *from != "" {
+		if sourceDir, err = resolveInstanceDir(*from); err != nil {
+			return reportCLIError(err)
+		}
+	}
+	archivePath, err := resolveBackupPath(sourceDir, positional[0])
+	if err != nil {
+		return reportCLIError(err)
+	}
+	fmt.Printf("⚠️ %s의 데이터 디렉토리가 백업 당시 내용으로 바뀝니다 (현재 데이터는 자동으로 백업됩니다).\n", installDir)
+	if !confirm("복원하시겠습니까? (y/n): ") {
+		return exitFailure
+	}
+	return reportCLIError(restoreBackup(archivePath, installDir, !*noConfig))
+}
+
+func cliService(args []string) int {
+	fs := newFlagSet("service")
+	system := fs.Bool("system", false, "시스템 서비스로 등록 (Linux, root 필요)")
+	noEnable := fs.Bool("no-enable", false, "정의 파일만 작성하고 등록/시작하지 않음")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return reportFlagError("service", err)
+	}
+	if len(positional) != 1 {
+		return usageError("service: install, uninstall, status, print 중 하나를 입력해주세요")
+	}
+	switch positional[0] {
+	case "install":
+		if err := checkDependencies(); err != nil {
+			return reportCLIError(err)
+		}
+		return reportCLIError(installService(installDir, *system, !*noEnable))
+	case "uninstall":
+		return reportCLIError(uninstallService(installDir))
+	case "status":
+		name, err := serviceName(installDir)
+		if err != nil {
+			return reportCLIError(err)
+		}
+		return reportCLIError(host.serviceStatus(name))
+	case "print":
+		svc, err := newServiceSpec(installDir, *system)
+		if err != nil {
+			return reportCLIError(err)
+		}
+		fmt.Print(renderServiceDefinition(svc))
+		return exitOK
+	default:
+		return usageError("service: 알 수 없는 하위 명령입니다: %s", positional[0])
+	}
+}
+
+// cliSupervise는 startBackground가 분리해서 실행하는 감시 프로세스입니다 (도움말에는 표시하지 않음).
+func cliSupervise(args []string) int {
+	fs := newFlagSet(supervisorCommand)
+	nodePath := fs.String("node", "", "사용할 node 실행 파일")
+	// --home은 parseFlags가 인스턴스 목록을 읽어 설치 경로를 정하기 전에 적용되어야 하므로 해석하는 즉시 설정합니다.
+	fs.Func("home", "설치 프로그램 데이터 디렉토리 (서비스로 실행할 때)", func(dir string) error {
+		if dir == "" {
+			return nil
+		}
+		return os.Setenv(installerDataDirEnv, dir)
+	})
+	if _, err := parseFlags(fs, args); err != nil {
+		return reportFlagError(supervisorCommand, err)
+	}
+	if *nodePath != "" {
+		nodeExecutablePath = *nodePath
+	}
+	return reportCLIError(superviseServer(installDir))
+}
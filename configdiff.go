@@ -0,0 +1,322 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// configKey는 config.yaml의 항목 하나입니다. 추가된 항목은 하위 항목까지 내려가 하나씩 기록하고,
+// 삭제된 항목은 하위 항목이 통째로 없어진 경우 가장 위 키만 기록합니다.
+type configKey struct {
+	path  []string
+	key   *yaml.Node
+	value *yaml.Node
+}
+
+func (k configKey) String() string {
+	return strings.Join(k.path, ".")
+}
+
+// configRename은 이름이 바뀌었거나 다른 위치로 옮겨진 것으로 보이는 항목 쌍입니다.
+type configRename struct {
+	from, to configKey
+}
+
+// configDiff는 인스턴스의 config.yaml과 SillyTavern 기본 설정(default/config.yaml)의 차이입니다.
+type configDiff struct {
+	Added   []configKey    // 기본 설정에만 있는 항목
+	Removed []configKey    // config.yaml에만 있는 항목 (더 이상 쓰이지 않을 수 있음)
+	Renamed []configRename // 같은 구역에서 대소문자/구분자만 바뀐 항목 (사용자 값을 새 이름으로 옮김)
+	Moved   []configRename // 다른 구역으로 옮겨진 것으로 보이는 항목 (보고만 하고 옮기지 않음)
+}
+
+func (d *configDiff) empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Renamed) == 0 && len(d.Moved) == 0
+}
+
+// diffConfigs는 user와 def를 비교합니다. 양쪽 모두 매핑인 항목만 하위로 내려가 비교하고, 값이 다른 것은 차이로 보지 않습니다.
+func diffConfigs(user, def *configDocument) *configDiff {
+	d := &configDiff{}
+	var walk func(u, f *yaml.Node, path []string)
+	walk = func(u, f *yaml.Node, path []string) {
+		for i := 0; i+1 < len(f.Content); i += 2 {
+			key := f.Content[i].Value
+			p := append(append([]string{}, path...), key)
+			fv := f.Content[i+1]
+			_, uv := mappingEntry(u, key)
+			switch {
+			case uv == nil && fv.Kind == yaml.MappingNode && len(fv.Content) > 0:
+				// 새 구역은 하위 항목별로 비교해, 기존 항목이 이 구역으로 옮겨진 경우도 찾을 수 있게 합니다.
+				walk(&yaml.Node{Kind: yaml.MappingNode}, fv, p)
+			case uv == nil:
+				d.Added = append(d.Added, configKey{p, f.Content[i], fv})
+			case uv.Kind == yaml.MappingNode && fv.Kind == yaml.MappingNode:
+				walk(uv, fv, p)
+			}
+		}
+		for i := 0; i+1 < len(u.Content); i += 2 {
+			key := u.Content[i].Value
+			if _, fv := mappingEntry(f, key); fv == nil {
+				d.Removed = append(d.Removed, configKey{append(append([]string{}, path...), key), u.Content[i], u.Content[i+1]})
+			}
+		}
+	}
+	walk(user.mapping(), def.mapping(), nil)
+	d.pairRenames()
+	return d
+}
+
+// pairRenames는 삭제된 항목과 추가된 항목 중 같은 설정으로 보이는 쌍을 찾습니다.
+//   - 같은 구역에서 키 이름이 대소문자/구분자(_, -)만 다르면 이름 변경(Renamed)으로 봅니다.
+//   - 다른 구역이라도 양쪽에서 한 번씩만 나오는 키 이름이고 값이 같으면 옮겨진 항목(Moved)으로 봅니다.
+//
+// SillyTavern 설정에는 enabled처럼 여러 구역에 쓰이는 키 이름이 많으므로, 키 이름만 같다고 묶지 않습니다.
+// Moved에 들어간 항목도 Added/Removed에 그대로 남아, 병합 시 새 항목은 기본값으로 추가되고 기존 항목은 지워지지 않습니다.
+func (d *configDiff) pairRenames() {
+	normalize := func(s string) string {
+		return strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(s))
+	}
+	parent := func(k configKey) string { return strings.Join(k.path[:len(k.path)-1], ".") }
+	leaf := func(k configKey) string { return k.path[len(k.path)-1] }
+	leafCount := func(keys []configKey) map[string]int {
+		counts := map[string]int{}
+		for _, k := range keys {
+			counts[leaf(k)]++
+		}
+		return counts
+	}
+	removedLeaves, addedLeaves := leafCount(d.Removed), leafCount(d.Added)
+
+	used := map[int]bool{}
+	var removed []configKey
+	for _, r := range d.Removed {
+		renamed := false
+		for i, a := range d.Added {
+			if used[i] || r.value.Kind != a.value.Kind {
+				continue
+			}
+			if parent(r) == parent(a) && normalize(leaf(r)) == normalize(leaf(a)) {
+				used[i], renamed = true, true
+				d.Renamed = append(d.Renamed, configRename{r, a})
+				break
+			}
+			if leaf(r) == leaf(a) && parent(r) != parent(a) && removedLeaves[leaf(r)] == 1 && addedLeaves[leaf(a)] == 1 && yamlNodesEqual(r.value, a.value) {
+				d.Moved = append(d.Moved, configRename{r, a})
+			}
+		}
+		if !renamed {
+			removed = append(removed, r)
+		}
+	}
+	var added []configKey
+	for i, a := range d.Added {
+		if !used[i] {
+			added = append(added, a)
+		}
+	}
+	d.Added, d.Removed = added, removed
+}
+
+// yamlNodesEqual은 두 노드가 같은 값을 나타내는지 비교합니다 (주석/스타일 무시).
+func yamlNodesEqual(a, b *yaml.Node) bool {
+	var va, vb interface{}
+	if a.Decode(&va) != nil || b.Decode(&vb) != nil {
+		return false
+	}
+	return reflect.DeepEqual(va, vb)
+}
+
+// describeConfigValue는 보고서에 표시할 값 요약입니다.
+func describeConfigValue(n *yaml.Node) string {
+	switch n.Kind {
+	case yaml.MappingNode:
+		return fmt.Sprintf("{하위 항목 %d개}", len(n.Content)/2)
+	case yaml.SequenceNode:
+		return fmt.Sprintf("[목록 %d개]", len(n.Content))
+	case yaml.AliasNode:
+		return "*" + n.Value
+	}
+	if n.Value == "" {
+		return `""`
+	}
+	return n.Value
+}
+
+// print는 차이 보고서를 출력합니다.
+func (d *configDiff) print() {
+	if d.empty() {
+		fmt.Println("✅ config.yaml에 기본 설정(default/config.yaml)의 모든 항목이 있습니다.")
+		return
+	}
+	if len(d.Added) > 0 {
+		fmt.Printf("➕ 새로 추가된 기본 설정 항목 %d개:\n", len(d.Added))
+		for _, a := range d.Added {
+			fmt.Printf("   %s = %s\n", a, describeConfigValue(a.value))
+		}
+	}
+	if len(d.Renamed) > 0 {
+		fmt.Printf("🔁 이름이 바뀐 것으로 보이는 항목 %d개:\n", len(d.Renamed))
+		for _, r := range d.Renamed {
+			fmt.Printf("   %s → %s (현재 값: %s)\n", r.from, r.to, describeConfigValue(r.from.value))
+		}
+	}
+	if len(d.Moved) > 0 {
+		fmt.Printf("↪️ 다른 구역으로 옮겨진 것으로 보이는 항목 %d개 (직접 확인해주세요):\n", len(d.Moved))
+		for _, m := range d.Moved {
+			fmt.Printf("   %s → %s\n", m.from, m.to)
+		}
+	}
+	if len(d.Removed) > 0 {
+		fmt.Printf("➖ 기본 설정에 없는 항목 %d개 (더 이상 쓰이지 않을 수 있으며, 그대로 둡니다):\n", len(d.Removed))
+		for _, r := range d.Removed {
+			fmt.Printf("   %s\n", r)
+		}
+	}
+}
+
+// copyYAMLNode는 노드 트리를 깊은 복사합니다 (두 문서가 노드를 공유하지 않도록).
+func copyYAMLNode(n *yaml.Node) *yaml.Node {
+	c := *n
+	c.Content = make([]*yaml.Node, len(n.Content))
+	for i, child := range n.Content {
+		c.Content[i] = copyYAMLNode(child)
+	}
+	return &c
+}
+
+// insertMappingEntry는 key/value 쌍을 mapping에 추가합니다. 기본 설정에서 바로 앞에 있던 키(after) 뒤에 넣어
+// 기본 설정과 비슷한 순서를 유지하며, 그 키가 없으면 맨 끝에 추가합니다.
+func insertMappingEntry(mapping, key, value *yaml.Node, after string) {
+	for i := 0; after != "" && i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == after {
+			pos := i + 2
+			mapping.Content = append(mapping.Content[:pos], append([]*yaml.Node{key, value}, mapping.Content[pos:]...)...)
+			return
+		}
+	}
+	mapping.Content = append(mapping.Content, key, value)
+}
+
+// precedingKey는 def 문서에서 path 항목 바로 앞에 있는 형제 키입니다.
+func precedingKey(def *configDocument, path []string) string {
+	parent := def.get(path[:len(path)-1]...)
+	if parent == nil {
+		return ""
+	}
+	prev := ""
+	for i := 0; i+1 < len(parent.Content); i += 2 {
+		if parent.Content[i].Value == path[len(path)-1] {
+			return prev
+		}
+		prev = parent.Content[i].Value
+	}
+	return ""
+}
+
+// ensureConfigMapping은 user에서 path 매핑을 찾고, 없으면 기본 설정의 키(주석 포함)로 중간 구역을 만듭니다.
+// 같은 이름의 항목이 매핑이 아니면 nil을 반환합니다.
+func ensureConfigMapping(user, def *configDocument, path []string) *yaml.Node {
+	node := user.mapping()
+	for i, key := range path {
+		_, child := mappingEntry(node, key)
+		if child == nil {
+			defKey, _ := mappingEntry(def.get(path[:i]...), key)
+			child = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			insertMappingEntry(node, copyYAMLNode(defKey), child, precedingKey(def, path[:i+1]))
+		}
+		if child.Kind != yaml.MappingNode {
+			return nil
+		}
+		node = child
+	}
+	return node
+}
+
+// mergeConfigDefaults는 새 기본 설정 항목을 user에 추가합니다. 이름이 바뀐 항목은 새 이름에 사용자의 기존 값을 넣고,
+// removeOld가 true일 때만 이전 이름의 항목을 지웁니다. 이미 있는 값은 바꾸지 않습니다. 추가한 항목 수와 옮긴 항목 수를 반환합니다.
+func mergeConfigDefaults(user, def *configDocument, d *configDiff, removeOld bool) (added, moved int) {
+	add := func(target configKey, value *yaml.Node) bool {
+		parent := ensureConfigMapping(user, def, target.path[:len(target.path)-1])
+		if parent == nil {
+			return false
+		}
+		insertMappingEntry(parent, copyYAMLNode(target.key), copyYAMLNode(value), precedingKey(def, target.path))
+		return true
+	}
+	for _, r := range d.Renamed {
+		if !add(r.to, r.from.value) {
+			continue
+		}
+		moved++
+		if parent := user.get(r.from.path[:len(r.from.path)-1]...); removeOld && parent != nil {
+			removeMappingEntry(parent, r.from.path[len(r.from.path)-1])
+		}
+	}
+	for _, a := range d.Added {
+		if add(a, a.value) {
+			added++
+		}
+	}
+	return added, moved
+}
+
+func removeMappingEntry(mapping *yaml.Node, key string) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			mapping.Content = append(mapping.Content[:i], mapping.Content[i+2:]...)
+			return
+		}
+	}
+}
+
+// reviewConfigDefaults는 업데이트 후 baseDir의 config.yaml을 기본 설정과 비교해 보고하고,
+// 새 항목이 있으면 기존 값은 그대로 둔 채 추가할지 묻습니다 (merge가 true이면 묻지 않고 추가).
+// config.yaml이 아직 없으면(처음 실행 전) SillyTavern이 기본 설정으로 만들므로 건너뜁니다.
+func reviewConfigDefaults(baseDir string, merge bool) error {
+	configPath := filepath.Join(baseDir, configFileName)
+	defaultPath := filepath.Join(baseDir, "default", configFileName)
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		return nil
+	}
+	if _, err := os.Stat(defaultPath); os.IsNotExist(err) {
+		return nil
+	}
+	user, err := loadConfig(configPath)
+	if err != nil {
+		return err
+	}
+	def, err := loadConfig(defaultPath)
+	if err != nil {
+		return err
+	}
+	fmt.Println("\n[ config.yaml과 기본 설정 비교 ]")
+	d := diffConfigs(user, def)
+	d.print()
+	if len(d.Added) == 0 && len(d.Renamed) == 0 {
+		return nil
+	}
+	if !merge && !confirm("새 기본 설정 항목을 config.yaml에 추가하시겠습니까? 기존 값은 바꾸지 않습니다 (y/n): ") {
+		fmt.Println("ℹ️ config.yaml을 그대로 두었습니다. 나중에 'config-diff --merge'로 추가할 수 있습니다.")
+		return nil
+	}
+	// 이전 이름의 항목을 지우는 것은 따로 확인합니다 (--merge만으로는 지우지 않음).
+	removeOld := len(d.Renamed) > 0 && confirm(fmt.Sprintf("이름이 바뀐 항목 %d개의 이전 이름을 config.yaml에서 지우시겠습니까? (y/n): ", len(d.Renamed)))
+	added, moved := mergeConfigDefaults(user, def, d, removeOld)
+	if err := saveConfig(configPath, user); err != nil {
+		return err
+	}
+	fmt.Printf("✅ config.yaml을 갱신했습니다 (새 항목 %d개 추가, 이름이 바뀐 항목 %d개에 기존 값 적용).\n", added, moved)
+	return nil
+}
+
+// checkConfigDefaults는 업데이트 뒤에 호출하며, 비교에 실패해도 업데이트 자체는 성공으로 둡니다.
+func checkConfigDefaults(baseDir string) {
+	if err := reviewConfigDefaults(baseDir, false); err != nil {
+		fmt.Println("⚠️ config.yaml과 기본 설정을 비교하지 못했습니다:", err)
+	}
+}
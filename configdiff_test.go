@@ -0,0 +1,92 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func mustParseConfig(t *testing.T, text string) *configDocument {
+	t.Helper()
+	doc, err := parseConfigDocument([]byte(text))
+	if err != nil {
+		t.Fatalf("parseConfigDocument: %v", err)
+	}
+	return doc
+}
+
+func TestDiffConfigsPairing(t *testing.T) {
+	user := mustParseConfig(t, `basic_auth_mode: true
+backups:
+  enabled: false
+lazyLoadCharacters: false
+`)
+	def := mustParseConfig(t, `basicAuthMode: false
+thumbnails:
+  enabled: true
+performance:
+  lazyLoadCharacters: false
+`)
+	d := diffConfigs(user, def)
+
+	if len(d.Renamed) != 1 || d.Renamed[0].from.String() != "basic_auth_mode" || d.Renamed[0].to.String() != "basicAuthMode" {
+		t.Errorf("Renamed = %v, want basic_auth_mode → basicAuthMode", d.Renamed)
+	}
+	// 다른 구역의 enabled끼리는 이름만 같으므로 묶지 않습니다.
+	for _, pairs := range [][]configRename{d.Renamed, d.Moved} {
+		for _, p := range pairs {
+			if strings.HasSuffix(p.from.String(), "enabled") {
+				t.Errorf("unrelated enabled keys paired: %s → %s", p.from, p.to)
+			}
+		}
+	}
+	if len(d.Moved) != 1 || d.Moved[0].to.String() != "performance.lazyLoadCharacters" {
+		t.Errorf("Moved = %v, want lazyLoadCharacters → performance.lazyLoadCharacters", d.Moved)
+	}
+}
+
+func TestDiffConfigsMovedNeedsEqualValue(t *testing.T) {
+	user := mustParseConfig(t, "lazyLoadCharacters: true\n")
+	def := mustParseConfig(t, "performance:\n  lazyLoadCharacters: false\n")
+	if d := diffConfigs(user, def); len(d.Moved) != 0 || len(d.Renamed) != 0 {
+		t.Errorf("pairs with different values: renamed=%v moved=%v", d.Renamed, d.Moved)
+	}
+}
+
+func TestMergeConfigDefaultsKeepsUserKeys(t *testing.T) {
+	user := mustParseConfig(t, `port: 8123
+basic_auth_mode: true
+backups:
+  enabled: false
+`)
+	def := mustParseConfig(t, `port: 8000
+basicAuthMode: false
+backups:
+  enabled: true
+thumbnails:
+  enabled: true
+`)
+	d := diffConfigs(user, def)
+	added, moved := mergeConfigDefaults(user, def, d, false)
+	if added != 1 || moved != 1 {
+		t.Fatalf("added=%d moved=%d, want 1 and 1", added, moved)
+	}
+	checks := map[string]string{
+		"port":               "8123",
+		"basicAuthMode":      "true",
+		"basic_auth_mode":    "true", // 확인 없이는 지우지 않음
+		"backups.enabled":    "false",
+		"thumbnails.enabled": "true",
+	}
+	for path, want := range checks {
+		if got, ok := user.getString(strings.Split(path, ".")...); !ok || got != want {
+			t.Errorf("%s = %q (ok=%v), want %q", path, got, ok, want)
+		}
+	}
+
+	user = mustParseConfig(t, "basic_auth_mode: true\n")
+	def = mustParseConfig(t, "basicAuthMode: false\n")
+	mergeConfigDefaults(user, def, diffConfigs(user, def), true)
+	if _, ok := user.getString("basic_auth_mode"); ok {
+		t.Error("basic_auth_mode kept although removeOld was true")
+	}
+}
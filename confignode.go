@@ -0,0 +1,219 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// configDocument는 config.yaml을 yaml.Node 트리로 보관합니다.
+// 값을 바꾼 노드만 달라지고 주석, 키 순서, 따옴표 스타일은 그대로 유지됩니다.
+type configDocument struct {
+	root     *yaml.Node // DocumentNode
+	original []byte     // 읽어온 원본 내용 (저장 시 빈 줄/공백 배치 복원용)
+}
+
+// parseConfigDocument는 YAML 내용을 노드 트리로 해석합니다. 빈 파일이면 빈 매핑 문서를 만듭니다.
+func parseConfigDocument(data []byte) (*configDocument, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	if root.Kind == 0 {
+		root = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	if root.Kind != yaml.DocumentNode || len(root.Content) == 0 || root.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("최상위 항목이 키-값 매핑이 아닙니다")
+	}
+	return &configDocument{root: &root, original: data}, nil
+}
+
+// encode는 노드 트리를 YAML로 직렬화하고, 건드리지 않은 줄은 원본 그대로 되살립니다.
+func (c *configDocument) encode() ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(detectIndent(c.original))
+	if err := encoder.Encode(c.root); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	encoded := buf.Bytes()
+
+	// 원본 배치를 되살린 결과가 인코더 출력과 같은 데이터를 나타낼 때만 사용합니다.
+	preserved := preserveOriginalLayout(c.original, encoded)
+	var want, got interface{}
+	if yaml.Unmarshal(encoded, &want) == nil && yaml.Unmarshal(preserved, &got) == nil && reflect.DeepEqual(want, got) {
+		return preserved, nil
+	}
+	return encoded, nil
+}
+
+// detectIndent는 원본 YAML에서 사용한 들여쓰기 칸 수를 추정합니다 (기본 2칸).
+func detectIndent(data []byte) int {
+	indent := 0
+	for _, l := range strings.Split(string(data), "\n") {
+		trimmed := strings.TrimLeft(l, " ")
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if n := len(l) - len(trimmed); n > 0 && (indent == 0 || n < indent) {
+			indent = n
+		}
+	}
+	if indent < 2 || indent > 8 {
+		return 2
+	}
+	return indent
+}
+
+// mapping은 최상위 매핑 노드를 반환합니다.
+func (c *configDocument) mapping() *yaml.Node {
+	return c.root.Content[0]
+}
+
+// get은 "a", "b" 처럼 주어진 키 경로의 값 노드를 반환합니다. 없으면 nil입니다.
+func (c *configDocument) get(path ...string) *yaml.Node {
+	node := c.mapping()
+	for _, key := range path {
+		if node == nil || node.Kind != yaml.MappingNode {
+			return nil
+		}
+		_, node = mappingEntry(node, key)
+	}
+	return node
+}
+
+// getString은 키 경로의 스칼라 값을 문자열로 반환합니다.
+func (c *configDocument) getString(path ...string) (string, bool) {
+	node := c.get(path...)
+	if node == nil || node.Kind != yaml.ScalarNode {
+		return "", false
+	}
+	return node.Value, true
+}
+
+// setScalar는 키 경로의 스칼라 값을 바꿉니다. 기존 노드가 있으면 스타일과 주석을 유지한 채 값만 바꾸고,
+// 없으면 중간 매핑을 포함해 새로 추가합니다.
+func (c *configDocument) setScalar(value, tag string, path ...string) {
+	parent := c.mapping()
+	for _, key := range path[:len(path)-1] {
+		_, child := mappingEntry(parent, key)
+		if child == nil || child.Kind != yaml.MappingNode {
+			child = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			setMappingValue(parent, key, child)
+		}
+		parent = child
+	}
+	key := path[len(path)-1]
+	if _, node := mappingEntry(parent, key); node != nil && node.Kind == yaml.ScalarNode {
+		node.Value = value
+		if node.Style&(yaml.SingleQuotedStyle|yaml.DoubleQuotedStyle) == 0 {
+			node.Tag = tag
+		}
+		return
+	}
+	setMappingValue(parent, key, &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: value})
+}
+
+// mappingEntry는 매핑 노드에서 key에 해당하는 키 노드와 값 노드를 찾습니다.
+func mappingEntry(mapping *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i], mapping.Content[i+1]
+		}
+	}
+	return nil, nil
+}
+
+// setMappingValue는 key의 값 노드를 교체하거나, 없으면 매핑 끝에 추가합니다.
+// 교체할 때는 기존 값 노드의 주석을 새 노드로 옮깁니다.
+func setMappingValue(mapping *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			old := mapping.Content[i+1]
+			if value.HeadComment == "" && value.LineComment == "" && value.FootComment == "" {
+				value.HeadComment, value.LineComment, value.FootComment = old.HeadComment, old.LineComment, old.FootComment
+			}
+			mapping.Content[i+1] = value
+			return
+		}
+	}
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+}
+
+// preserveOriginalLayout은 yaml.v3 인코더가 잃어버리는 빈 줄, 주석 앞 공백, 줄바꿈 형식(CRLF)을 원본에서 되살립니다.
+// 공백만 다른 줄은 원본 줄을 그대로 쓰고, 값이 바뀌었거나 새로 생긴 줄만 인코더 출력을 사용합니다.
+func preserveOriginalLayout(original, encoded []byte) []byte {
+	if len(original) == 0 {
+		return encoded
+	}
+	crlf := bytes.Contains(original, []byte("\r\n"))
+	origLines := strings.Split(strings.ReplaceAll(string(original), "\r\n", "\n"), "\n")
+	encLines := strings.Split(string(encoded), "\n")
+
+	// 빈 줄을 제외한 줄끼리 공백을 무시하고 최장 공통 부분열(LCS)로 대응시킵니다.
+	// flow 매핑/목록의 괄호 안쪽 공백({ 'bg': [160, 90] })도 인코더가 지우므로 무시합니다.
+	type line struct {
+		index int
+		key   string
+	}
+	flowSpaces := strings.NewReplacer("{ ", "{", " }", "}", "[ ", "[", " ]", "]")
+	nonBlank := func(lines []string) []line {
+		var result []line
+		for i, l := range lines {
+			if strings.TrimSpace(l) != "" {
+				result = append(result, line{i, flowSpaces.Replace(strings.Join(strings.Fields(l), " "))})
+			}
+		}
+		return result
+	}
+	orig, enc := nonBlank(origLines), nonBlank(encLines)
+	lcs := make([][]int, len(orig)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(enc)+1)
+	}
+	for i := len(orig) - 1; i >= 0; i-- {
+		for j := len(enc) - 1; j >= 0; j-- {
+			if orig[i].key == enc[j].key {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var out []string
+	i, j := 0, 0
+	for j < len(enc) {
+		if i < len(orig) && orig[i].key == enc[j].key {
+			// 원본에서 이 줄 바로 앞에 있던 빈 줄을 함께 복원합니다.
+			start := orig[i].index
+			for start > 0 && strings.TrimSpace(origLines[start-1]) == "" {
+				start--
+			}
+			out = append(out, origLines[start:orig[i].index+1]...)
+			i++
+			j++
+		} else if i < len(orig) && lcs[i+1][j] >= lcs[i][j+1] {
+			i++
+		} else {
+			out = append(out, encLines[enc[j].index])
+			j++
+		}
+	}
+
+	// 파일 끝의 줄바꿈/빈 줄도 원본과 같게 맞춥니다.
+	text := strings.ReplaceAll(string(original), "\r\n", "\n")
+	result := strings.Join(out, "\n") + text[len(strings.TrimRight(text, " \t\n")):]
+	if crlf {
+		result = strings.ReplaceAll(result, "\n", "\r\n")
+	}
+	return []byte(result)
+}
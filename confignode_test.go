@@ -0,0 +1,115 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+// testdata/default-config.yaml은 SillyTavern의 default/config.yaml 구성(구역 주석, 따옴표 섞인 값,
+// 중첩 매핑, 목록, flow 매핑)을 그대로 따른 파일입니다.
+func readDefaultConfig(t *testing.T) []byte {
+	t.Helper()
+	data, err := os.ReadFile("testdata/default-config.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func encodeConfig(t *testing.T, doc *configDocument) string {
+	t.Helper()
+	out, err := doc.encode()
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	return string(out)
+}
+
+// changedLines는 줄 수가 같은 두 텍스트에서 달라진 줄을 "원래 줄 -> 새 줄" 형식으로 모읍니다.
+func changedLines(t *testing.T, before, after, newline string) []string {
+	t.Helper()
+	a, b := strings.Split(before, newline), strings.Split(after, newline)
+	if len(a) != len(b) {
+		t.Fatalf("line count changed: %d -> %d\n%s", len(a), len(b), after)
+	}
+	var changed []string
+	for i := range a {
+		if a[i] != b[i] {
+			changed = append(changed, a[i]+" -> "+b[i])
+		}
+	}
+	return changed
+}
+
+func TestConfigDocumentRoundTripUnchanged(t *testing.T) {
+	original := readDefaultConfig(t)
+	crlf := []byte(strings.ReplaceAll(string(original), "\n", "\r\n"))
+	for name, data := range map[string][]byte{"LF": original, "CRLF": crlf} {
+		doc, err := parseConfigDocument(data)
+		if err != nil {
+			t.Fatalf("%s: parseConfigDocument: %v", name, err)
+		}
+		if got := encodeConfig(t, doc); got != string(data) {
+			t.Errorf("%s: unchanged document is not byte-identical:\n%s", name, got)
+		}
+	}
+}
+
+func TestConfigDocumentSetScalarTouchesOneLine(t *testing.T) {
+	original := string(readDefaultConfig(t))
+	tests := []struct {
+		path       []string
+		value, tag string
+		want       string
+	}{
+		{[]string{"port"}, "8123", "!!int", "port: 8000 -> port: 8123"},
+		{[]string{"listen"}, "true", "!!bool", "listen: false -> listen: true"},
+		{[]string{"autorunHostname"}, "localhost", "!!str", `autorunHostname: "auto" -> autorunHostname: "localhost"`},
+		{[]string{"performance", "memoryCacheCapacity"}, "1gb", "!!str", "  memoryCacheCapacity: '100mb' ->   memoryCacheCapacity: '1gb'"},
+		{[]string{"basicAuthUser", "password"}, `p@ss "word"`, "!!str", `  password: "password" ->   password: "p@ss \"word\""`},
+		{[]string{"backups", "chat", "throttleInterval"}, "5000", "!!int", "    throttleInterval: 10000 ->     throttleInterval: 5000"},
+	}
+	for _, newline := range []string{"\n", "\r\n"} {
+		data := strings.ReplaceAll(original, "\n", newline)
+		for _, tt := range tests {
+			doc, err := parseConfigDocument([]byte(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			doc.setScalar(tt.value, tt.tag, tt.path...)
+			got := encodeConfig(t, doc)
+			changed := changedLines(t, data, got, newline)
+			if len(changed) != 1 || changed[0] != tt.want {
+				t.Errorf("%q (newline %q): changed lines = %q, want [%q]", strings.Join(tt.path, "."), newline, changed, tt.want)
+			}
+			if newline == "\r\n" && strings.Count(got, "\n") != strings.Count(got, "\r\n") {
+				t.Errorf("%q: CRLF line endings were not kept", strings.Join(tt.path, "."))
+			}
+
+			reparsed, err := parseConfigDocument([]byte(got))
+			if err != nil {
+				t.Fatalf("re-parse: %v", err)
+			}
+			if v, ok := reparsed.getString(tt.path...); !ok || v != tt.value {
+				t.Errorf("%q = %q after round trip, want %q", strings.Join(tt.path, "."), v, tt.value)
+			}
+		}
+	}
+}
+
+func TestConfigDocumentAddKeyKeepsRest(t *testing.T) {
+	original := string(readDefaultConfig(t))
+	doc, err := parseConfigDocument([]byte(original))
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc.setScalar("true", "!!bool", "newSection", "enabled")
+	got := encodeConfig(t, doc)
+	if !strings.HasPrefix(got, original) {
+		t.Errorf("existing lines changed when a key was appended:\n%s", got)
+	}
+	if tail := got[len(original):]; tail != "newSection:\n  enabled: true\n" {
+		t.Errorf("appended = %q", tail)
+	}
+}
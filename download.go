@@ -0,0 +1,264 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	downloadMaxAttempts   = 5                // 다운로드 최대 시도 횟수 (이어받기 포함)
+	downloadStallTimeout  = 30 * time.Second // 이 시간 동안 받은 데이터가 없으면 연결을 끊고 재시도
+	downloadRetryBaseWait = 2 * time.Second  // 재시도 대기 시간 (시도마다 두 배, 최대 30초)
+	downloadProgressEvery = 200 * time.Millisecond
+)
+
+// errDownloadStalled는 downloadStallTimeout 동안 데이터를 받지 못했을 때의 오류입니다.
+var errDownloadStalled = errors.New("응답이 멈췄습니다")
+
+// permanentDownloadError는 재시도해도 소용없는 오류(404 등)를 표시합니다.
+type permanentDownloadError struct{ err error }
+
+func (e *permanentDownloadError) Error() string { return e.err.Error() }
+func (e *permanentDownloadError) Unwrap() error { return e.err }
+
+// downloadFile은 url을 targetFilepath로 내려받습니다.
+// 받는 중인 내용은 "<파일>.part"에 저장하고, 끊기면 HTTP Range 요청으로 이어받으며 대기 시간을 늘려 가며 재시도합니다.
+// 전체 제한 시간 대신, 데이터가 downloadStallTimeout 동안 들어오지 않을 때만 연결을 끊습니다.
+func downloadFile(url, targetFilepath string) error {
+	fmt.Printf("다운로드 시작: %s -> %s\n", url, targetFilepath)
+	dir := filepath.Dir(targetFilepath)
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("임시 디렉토리(%s) 생성 실패: %w", dir, err)
+		}
+	}
+
+	// 받다 만 파일이 다른 URL(예: 이전 버전 설치 파일)의 것이면 이어받지 않고 버립니다.
+	partPath := targetFilepath + ".part"
+	sourcePath := partPath + ".url"
+	if prev, err := os.ReadFile(sourcePath); err != nil || string(prev) != url {
+		os.Remove(partPath)
+	}
+	if err := os.WriteFile(sourcePath, []byte(url), 0644); err != nil {
+		return fmt.Errorf("파일 생성 실패 (%s): %w", sourcePath, err)
+	}
+
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return fmt.Errorf("stopped after 10 redirects")
+			}
+			return nil
+		},
+		Transport: &http.Transport{
+			Proxy:                 http.ProxyFromEnvironment,
+			ResponseHeaderTimeout: downloadStallTimeout,
+			TLSHandshakeTimeout:   downloadStallTimeout,
+		},
+	}
+
+	wait := downloadRetryBaseWait
+	var lastErr error
+	for attempt := 1; attempt <= downloadMaxAttempts; attempt++ {
+		if attempt > 1 {
+			fmt.Printf("⚠️ 다운로드 실패 (%v). %s 후 다시 시도합니다 (%d/%d)...\n", lastErr, wait, attempt, downloadMaxAttempts)
+			time.Sleep(wait)
+			if wait *= 2; wait > 30*time.Second {
+				wait = 30 * time.Second
+			}
+		}
+		size, err := downloadAttempt(client, url, partPath)
+		if err == nil {
+			if err := os.Rename(partPath, targetFilepath); err != nil {
+				return fmt.Errorf("파일 이름 변경 실패 (%s): %w", targetFilepath, err)
+			}
+			os.Remove(sourcePath)
+			fmt.Printf("다운로드 완료: %s (%.2f MB)\n", filepath.Base(targetFilepath), float64(size)/(1024*1024))
+			return nil
+		}
+		lastErr = err
+		var permanent *permanentDownloadError
+		if errors.As(err, &permanent) {
+			os.Remove(partPath)
+			os.Remove(sourcePath)
+			return err
+		}
+	}
+	return fmt.Errorf("%d회 시도했지만 다운로드하지 못했습니다 (받은 부분은 %s에 남겨 두었으며 다음 실행 때 이어받습니다): %w", downloadMaxAttempts, partPath, lastErr)
+}
+
+// downloadAttempt는 partPath에 이미 받은 만큼을 건너뛰고 나머지를 받아 이어 붙입니다. 파일의 전체 크기를 반환합니다.
+func downloadAttempt(client *http.Client, url, partPath string) (int64, error) {
+	var offset int64
+	if fi, err := os.Stat(partPath); err == nil {
+		offset = fi.Size()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, &permanentDownloadError{fmt.Errorf("요청 생성 실패 (%s): %w", url, err)}
+	}
+	req.Header.Set("User-Agent", "SillyTavernInstaller")
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("HTTP GET 실패 (%s): %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	flags := os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	total := int64(-1)
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		fmt.Printf("이전에 받은 %.2f MB에 이어서 받습니다.\n", float64(offset)/(1024*1024))
+		if t, ok := contentRangeTotal(resp.Header.Get("Content-Range")); ok {
+			total = t
+		}
+	case http.StatusOK:
+		if offset > 0 {
+			fmt.Println("ℹ️ 서버가 이어받기를 지원하지 않아 처음부터 다시 받습니다.")
+		}
+		offset = 0
+		flags = os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+		total = resp.ContentLength
+	case http.StatusRequestedRangeNotSatisfiable:
+		// 이미 끝까지 받은 경우입니다. 크기가 맞지 않으면 받은 부분을 버리고 다시 받습니다.
+		if t, ok := contentRangeTotal(resp.Header.Get("Content-Range")); ok && t == offset {
+			return offset, nil
+		}
+		os.Remove(partPath)
+		return 0, fmt.Errorf("이어받기 위치가 올바르지 않아 처음부터 다시 받습니다")
+	default:
+		bodyBytes, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		err := fmt.Errorf("잘못된 응답 상태코드 (%s): %s. 응답: %s", url, resp.Status, strings.TrimSpace(string(bodyBytes)))
+		if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusRequestTimeout && resp.StatusCode != http.StatusTooManyRequests {
+			return 0, &permanentDownloadError{err}
+		}
+		return 0, err
+	}
+
+	out, err := os.OpenFile(partPath, flags, 0644)
+	if err != nil {
+		return 0, &permanentDownloadError{fmt.Errorf("파일 생성 실패 (%s): %w", partPath, err)}
+	}
+	defer out.Close()
+
+	// 데이터가 들어올 때마다 타이머를 되돌리고, 멈추면 요청을 취소합니다.
+	var stalled atomic.Bool
+	watchdog := time.AfterFunc(downloadStallTimeout, func() {
+		stalled.Store(true)
+		cancel()
+	})
+	defer watchdog.Stop()
+
+	progress := newDownloadProgress(offset, total)
+	buf := make([]byte, 32*1024)
+	for {
+		n, readErr := resp.Body.Read(buf)
+		if n > 0 {
+			watchdog.Reset(downloadStallTimeout)
+			if _, err := out.Write(buf[:n]); err != nil {
+				progress.finish()
+				return 0, &permanentDownloadError{fmt.Errorf("파일 쓰기 실패 (%s): %w", partPath, err)}
+			}
+			progress.add(int64(n))
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			progress.finish()
+			if stalled.Load() {
+				return 0, fmt.Errorf("%w (%s 동안 받은 데이터 없음)", errDownloadStalled, downloadStallTimeout)
+			}
+			return 0, fmt.Errorf("파일 내용 받기 실패 (%s): %w", url, readErr)
+		}
+	}
+	progress.finish()
+	if total >= 0 && progress.done != total {
+		if progress.done > total {
+			os.Remove(partPath)
+		}
+		return 0, fmt.Errorf("받은 크기(%d)가 예상 크기(%d)와 다릅니다", progress.done, total)
+	}
+	return progress.done, nil
+}
+
+// contentRangeTotal은 "bytes 100-199/1000" 또는 "bytes */1000" 형식에서 전체 크기를 꺼냅니다.
+func contentRangeTotal(header string) (int64, bool) {
+	i := strings.LastIndex(header, "/")
+	if i < 0 {
+		return 0, false
+	}
+	total, err := strconv.ParseInt(strings.TrimSpace(header[i+1:]), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return total, true
+}
+
+// downloadProgress는 진행률 막대와 속도, 남은 시간을 한 줄에 갱신하여 출력합니다.
+type downloadProgress struct {
+	done, total int64 // total이 -1이면 전체 크기를 모름
+	resumedFrom int64
+	started     time.Time
+	lastPrinted time.Time
+}
+
+func newDownloadProgress(offset, total int64) *downloadProgress {
+	return &downloadProgress{done: offset, total: total, resumedFrom: offset, started: time.Now()}
+}
+
+func (p *downloadProgress) add(n int64) {
+	p.done += n
+	if time.Since(p.lastPrinted) >= downloadProgressEvery {
+		p.print()
+	}
+}
+
+func (p *downloadProgress) print() {
+	p.lastPrinted = time.Now()
+	const mb = 1024 * 1024
+	elapsed := time.Since(p.started).Seconds()
+	speed := 0.0
+	if elapsed > 0 {
+		speed = float64(p.done-p.resumedFrom) / elapsed
+	}
+	if p.total <= 0 {
+		fmt.Printf("\r다운로드 중... %.2f MB | %.2f MB/s   ", float64(p.done)/mb, speed/mb)
+		return
+	}
+	const width = 30
+	ratio := float64(p.done) / float64(p.total)
+	filled := int(ratio * width)
+	if filled > width {
+		filled = width
+	}
+	eta := "--:--"
+	if speed > 0 {
+		remaining := time.Duration(float64(p.total-p.done)/speed) * time.Second
+		eta = fmt.Sprintf("%02d:%02d", int(remaining.Minutes()), int(remaining.Seconds())%60)
+	}
+	fmt.Printf("\r[%s%s] %5.1f%% %.2f/%.2f MB | %.2f MB/s | 남은 시간 %s   ",
+		strings.Repeat("#", filled), strings.Repeat(".", width-filled), ratio*100,
+		float64(p.done)/mb, float64(p.total)/mb, speed/mb, eta)
+}
+
+// finish는 마지막 진행 상태를 출력하고 줄을 바꿉니다.
+func (p *downloadProgress) finish() {
+	p.print()
+	fmt.Println()
+}
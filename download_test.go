@@ -0,0 +1,213 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var downloadPayload = strings.Repeat("SillyTavern installer payload ", 4096)
+
+// fastDownloadRetries는 테스트 동안 재시도 대기와 멈춤 감지 시간을 줄입니다.
+func fastDownloadRetries(t *testing.T) {
+	t.Helper()
+	savedWait, savedStall := downloadRetryBaseWait, downloadStallTimeout
+	downloadRetryBaseWait, downloadStallTimeout = time.Millisecond, 200*time.Millisecond
+	t.Cleanup(func() { downloadRetryBaseWait, downloadStallTimeout = savedWait, savedStall })
+}
+
+// writeTruncated는 전체 크기를 알린 뒤 절반만 보내고 연결을 끊습니다.
+func writeTruncated(w http.ResponseWriter) {
+	w.Header().Set("Content-Length", fmt.Sprint(len(downloadPayload)))
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, downloadPayload[:len(downloadPayload)/2])
+}
+
+func assertDownloaded(t *testing.T, target string) {
+	t.Helper()
+	data, err := os.ReadFile(target)
+	if err != nil {
+		t.Fatalf("read %s: %v", target, err)
+	}
+	if string(data) != downloadPayload {
+		t.Errorf("downloaded %d bytes, want %d bytes of payload", len(data), len(downloadPayload))
+	}
+	for _, leftover := range []string{target + ".part", target + ".part.url"} {
+		if _, err := os.Stat(leftover); !os.IsNotExist(err) {
+			t.Errorf("%s left behind", leftover)
+		}
+	}
+}
+
+func TestDownloadFileResumesWithRange(t *testing.T) {
+	fastDownloadRetries(t)
+	half := len(downloadPayload) / 2
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			writeTruncated(w)
+			return
+		}
+		if want := fmt.Sprintf("bytes=%d-", half); r.Header.Get("Range") != want {
+			t.Errorf("Range = %q, want %q", r.Header.Get("Range"), want)
+		}
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", half, len(downloadPayload)-1, len(downloadPayload)))
+		w.Header().Set("Content-Length", fmt.Sprint(len(downloadPayload)-half))
+		w.WriteHeader(http.StatusPartialContent)
+		fmt.Fprint(w, downloadPayload[half:])
+	}))
+	defer srv.Close()
+
+	target := filepath.Join(t.TempDir(), "node.msi")
+	if err := downloadFile(srv.URL+"/node.msi", target); err != nil {
+		t.Fatalf("downloadFile: %v", err)
+	}
+	assertDownloaded(t, target)
+	if n := requests.Load(); n != 2 {
+		t.Errorf("requests = %d, want 2", n)
+	}
+}
+
+func TestDownloadFileRestartsWhenRangeIgnored(t *testing.T) {
+	fastDownloadRetries(t)
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			writeTruncated(w)
+			return
+		}
+		fmt.Fprint(w, downloadPayload) // Range를 무시하고 처음부터 보냄
+	}))
+	defer srv.Close()
+
+	target := filepath.Join(t.TempDir(), "node.msi")
+	if err := downloadFile(srv.URL+"/node.msi", target); err != nil {
+		t.Fatalf("downloadFile: %v", err)
+	}
+	assertDownloaded(t, target)
+}
+
+func TestDownloadFileAlreadyComplete(t *testing.T) {
+	fastDownloadRetries(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Range") == "" {
+			t.Error("request without Range although the part file is complete")
+		}
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes */%d", len(downloadPayload)))
+		w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+	}))
+	defer srv.Close()
+
+	url := srv.URL + "/node.msi"
+	target := filepath.Join(t.TempDir(), "node.msi")
+	if err := os.WriteFile(target+".part", []byte(downloadPayload), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(target+".part.url", []byte(url), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := downloadFile(url, target); err != nil {
+		t.Fatalf("downloadFile: %v", err)
+	}
+	assertDownloaded(t, target)
+}
+
+func TestDownloadFileRetriesAfterStall(t *testing.T) {
+	fastDownloadRetries(t)
+	half := len(downloadPayload) / 2
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			w.Header().Set("Content-Length", fmt.Sprint(len(downloadPayload)))
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprint(w, downloadPayload[:half])
+			w.(http.Flusher).Flush()
+			select { // 연결은 유지한 채 더 보내지 않음
+			case <-r.Context().Done():
+			case <-time.After(10 * time.Second):
+			}
+			return
+		}
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", half, len(downloadPayload)-1, len(downloadPayload)))
+		w.WriteHeader(http.StatusPartialContent)
+		fmt.Fprint(w, downloadPayload[half:])
+	}))
+	defer srv.Close()
+
+	target := filepath.Join(t.TempDir(), "node.msi")
+	start := time.Now()
+	if err := downloadFile(srv.URL+"/node.msi", target); err != nil {
+		t.Fatalf("downloadFile: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("stall was not detected in time (took %s)", elapsed)
+	}
+	assertDownloaded(t, target)
+}
+
+func TestDownloadAttemptReportsStall(t *testing.T) {
+	fastDownloadRetries(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", fmt.Sprint(len(downloadPayload)))
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	_, err := downloadAttempt(srv.Client(), srv.URL+"/node.msi", filepath.Join(t.TempDir(), "node.msi.part"))
+	if !errors.Is(err, errDownloadStalled) {
+		t.Errorf("downloadAttempt = %v, want errDownloadStalled", err)
+	}
+}
+
+func TestDownloadFileNoRetryOnPermanentError(t *testing.T) {
+	fastDownloadRetries(t)
+	for _, status := range []int{http.StatusNotFound, http.StatusForbidden} {
+		var requests atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			http.Error(w, "nope", status)
+		}))
+
+		target := filepath.Join(t.TempDir(), "node.msi")
+		err := downloadFile(srv.URL+"/node.msi", target)
+		srv.Close()
+		var permanent *permanentDownloadError
+		if !errors.As(err, &permanent) {
+			t.Errorf("%d: downloadFile = %v, want permanentDownloadError", status, err)
+		}
+		if n := requests.Load(); n != 1 {
+			t.Errorf("%d: requests = %d, want 1 (no retry)", status, n)
+		}
+		if _, err := os.Stat(target + ".part.url"); !os.IsNotExist(err) {
+			t.Errorf("%d: .part.url left behind", status)
+		}
+	}
+}
+
+func TestDownloadFileRetriesServerErrors(t *testing.T) {
+	fastDownloadRetries(t)
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) < 3 {
+			http.Error(w, "busy", http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, downloadPayload)
+	}))
+	defer srv.Close()
+
+	target := filepath.Join(t.TempDir(), "node.msi")
+	if err := downloadFile(srv.URL+"/node.msi", target); err != nil {
+		t.Fatalf("downloadFile: %v", err)
+	}
+	assertDownloaded(t, target)
+}
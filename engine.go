@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// rawContentBaseURL은 클론하기 전에 원격 브랜치의 package.json을 읽어올 주소입니다.
+var rawContentBaseURL = "https://raw.githubusercontent.com/SillyTavern/SillyTavern"
+
+// nodeEngineChecked는 이번 실행에서 이미 확인한 engines.node 범위입니다 (같은 범위를 반복해서 묻지 않음).
+var nodeEngineChecked string
+
+// parseNodeEngine은 package.json 내용에서 engines.node 값을 꺼냅니다. 선언되지 않았으면 빈 문자열입니다.
+func parseNodeEngine(data []byte) (string, error) {
+	var pkg struct {
+		Engines struct {
+			Node string `json:"node"`
+		} `json:"engines"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return "", fmt.Errorf("package.json 파싱 실패: %w", err)
+	}
+	return strings.TrimSpace(pkg.Engines.Node), nil
+}
+
+// localNodeEngine은 설치된 SillyTavern의 package.json에서 요구 Node.js 버전 범위를 읽습니다.
+func localNodeEngine(baseDir string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(baseDir, "package.json"))
+	if err != nil {
+		return "", fmt.Errorf("package.json 읽기 실패: %w", err)
+	}
+	return parseNodeEngine(data)
+}
+
+// remoteNodeEngine은 클론하기 전에 원격 브랜치(또는 태그)의 package.json에서 요구 Node.js 버전 범위를 읽습니다.
+func remoteNodeEngine(ref string) (string, error) {
+	data, err := httpGetSmall(strings.TrimRight(rawContentBaseURL, "/") + "/" + ref + "/package.json")
+	if err != nil {
+		return "", err
+	}
+	return parseNodeEngine(data)
+}
+
+// ensureNodeEngine은 현재 Node.js 버전이 SillyTavern의 engines.node 범위를 만족하는지 확인합니다.
+// 만족하지 않으면 Node.js (LTS) 업그레이드를 제안하며, 업그레이드하지 않으면 경고만 하고 계속 진행합니다.
+func ensureNodeEngine(engine, source string) {
+	if engine == "" || engine == nodeEngineChecked {
+		return
+	}
+	nodeEngineChecked = engine
+	version, err := commandVersion(nodeExecutablePath)
+	if err != nil {
+		fmt.Printf("⚠️ Node.js 버전을 확인하지 못해 SillyTavern 요구 버전(%s) 확인을 건너뜁니다: %v\n", engine, err)
+		return
+	}
+	ok, err := satisfiesRange(version, engine)
+	if err != nil {
+		fmt.Printf("⚠️ SillyTavern 요구 Node.js 버전을 확인하지 못했습니다: %v\n", err)
+		return
+	}
+	if ok {
+		fmt.Printf("✅ Node.js %s은(는) SillyTavern 요구 버전(%s, %s)을 만족합니다.\n", formatVersion(version), engine, source)
+		return
+	}
+
+	fmt.Printf("⚠️ SillyTavern이 요구하는 Node.js 버전은 %s이지만 현재 버전은 %s입니다 (%s).\n", engine, formatVersion(version), source)
+	fmt.Println("   이대로 진행하면 npm install 또는 실행 중에 오류가 발생할 수 있습니다.")
+	if !confirm("Node.js (LTS) 자동 설치(업그레이드)를 시도하시겠습니까? (y/n): ") {
+		fmt.Println("ℹ️ 업그레이드하지 않고 계속 진행합니다.")
+		return
+	}
+	if activePortableBase != "" {
+		if !upgradePortableNode() {
+			return
+		}
+	} else {
+		installed, nodePath, npmPath := installNodeJS()
+		if !installed {
+			fmt.Printf("❌ Node.js 업그레이드에 실패했습니다. 수동으로 설치해주세요: %s\n", nodeJSDownloadPage)
+			return
+		}
+		nodeExecutablePath, npmExecutablePath = nodePath, npmPath
+	}
+	if version, err := commandVersion(nodeExecutablePath); err == nil {
+		if ok, _ := satisfiesRange(version, engine); !ok {
+			fmt.Printf("⚠️ 업그레이드 후에도 Node.js %s이(가) 요구 버전(%s)을 만족하지 않습니다. 새 터미널에서 다시 실행해야 할 수 있습니다.\n", formatVersion(version), engine)
+		}
+	}
+}
+
+// checkLocalNodeEngine은 설치된 저장소의 package.json을 기준으로 Node.js 버전을 확인합니다.
+func checkLocalNodeEngine(baseDir string) {
+	engine, err := localNodeEngine(baseDir)
+	if err != nil {
+		fmt.Println("⚠️ SillyTavern 요구 Node.js 버전을 읽지 못했습니다:", err)
+		return
+	}
+	ensureNodeEngine(engine, "package.json")
+}
+
+// checkRemoteNodeEngine은 클론하기 전에 원격 ref의 package.json을 기준으로 Node.js 버전을 확인합니다.
+func checkRemoteNodeEngine(ref string) {
+	engine, err := remoteNodeEngine(ref)
+	if err != nil {
+		fmt.Println("ℹ️ 원격 package.json을 읽지 못해 요구 Node.js 버전은 클론 후에 확인합니다:", err)
+		return
+	}
+	ensureNodeEngine(engine, ref+" 브랜치의 package.json")
+}
@@ -0,0 +1,408 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const instancesFileName = "instances.json"
+
+var (
+	currentInstance *instance // installDir에 해당하는 등록된 인스턴스 (없으면 nil)
+	instanceFlag    string    // --instance 옵션 값
+
+	errUnknownInstance = errors.New("등록되지 않은 인스턴스입니다")
+	instanceNameRegex  = regexp.MustCompile(`^[\p{L}\p{N}._-]+$`)
+
+	// pathsCaseInsensitive는 경로를 대소문자 구분 없이 비교할지 여부입니다 (테스트에서 바꿀 수 있도록 변수로 둠).
+	pathsCaseInsensitive = runtime.GOOS == "windows"
+)
+
+// instance는 이름으로 관리되는 SillyTavern 설치본 하나입니다.
+type instance struct {
+	Name        string    `json:"name"`
+	Path        string    `json:"path"`
+	Branch      string    `json:"branch,omitempty"`
+	Port        int       `json:"port,omitempty"`
+	LastUpdated time.Time `json:"lastUpdated,omitempty"`
+	PinKind     string    `json:"pinKind,omitempty"` // "tag" 또는 "commit" (고정하지 않았으면 비어 있음)
+	PinRef      string    `json:"pinRef,omitempty"`
+	NodeRuntime string    `json:"nodeRuntime,omitempty"` // "portable"이면 인스턴스 전용 Node.js 사용 (비어 있으면 시스템 Node.js)
+}
+
+// instanceRegistry는 instances.json 상태 파일의 내용입니다.
+type instanceRegistry struct {
+	Active    string      `json:"active,omitempty"`
+	Instances []*instance `json:"instances"`
+}
+
+func registryFilePath() (string, error) {
+	dataDir, err := installerDataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dataDir, instancesFileName), nil
+}
+
+// loadRegistry는 인스턴스 목록을 읽습니다. 오류가 나도 빈 목록을 함께 반환하므로 바로 조회에 사용할 수 있습니다.
+func loadRegistry() (*instanceRegistry, error) {
+	registry := &instanceRegistry{}
+	path, err := registryFilePath()
+	if err != nil {
+		return registry, err
+	}
+	if err := readJSONFile(path, registry); err != nil {
+		return &instanceRegistry{}, err
+	}
+	return registry, nil
+}
+
+func saveRegistry(registry *instanceRegistry) error {
+	path, err := registryFilePath()
+	if err != nil {
+		return err
+	}
+	return writeJSONFile(path, registry)
+}
+
+// updateRegistry는 인스턴스 목록을 읽어 update를 적용한 뒤 저장합니다.
+func updateRegistry(update func(r *instanceRegistry) error) error {
+	registry, err := loadRegistry()
+	if err != nil {
+		return err
+	}
+	if err := update(registry); err != nil {
+		return err
+	}
+	return saveRegistry(registry)
+}
+
+func (r *instanceRegistry) find(name string) *instance {
+	if name == "" {
+		return nil
+	}
+	for _, inst := range r.Instances {
+		if inst.Name == name {
+			return inst
+		}
+	}
+	return nil
+}
+
+func (r *instanceRegistry) findByPath(path string) *instance {
+	for _, inst := range r.Instances {
+		if samePath(inst.Path, path) {
+			return inst
+		}
+	}
+	return nil
+}
+
+func (r *instanceRegistry) add(inst *instance) error {
+	if err := validateInstanceName(inst.Name); err != nil {
+		return err
+	}
+	if r.find(inst.Name) != nil {
+		return fmt.Errorf("이미 '%s' 이름의 인스턴스가 있습니다", inst.Name)
+	}
+	if existing := r.findByPath(inst.Path); existing != nil {
+		return fmt.Errorf("경로 '%s'는 이미 인스턴스 '%s'로 등록되어 있습니다", inst.Path, existing.Name)
+	}
+	r.Instances = append(r.Instances, inst)
+	return nil
+}
+
+func (r *instanceRegistry) remove(name string) bool {
+	for i, inst := range r.Instances {
+		if inst.Name == name {
+			r.Instances = append(r.Instances[:i], r.Instances[i+1:]...)
+			if r.Active == name {
+				r.Active = ""
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// uniqueName은 base를 기반으로 목록에 없는 인스턴스 이름을 만듭니다.
+func (r *instanceRegistry) uniqueName(base string) string {
+	base = strings.Map(func(c rune) rune {
+		if instanceNameRegex.MatchString(string(c)) {
+			return c
+		}
+		return '-'
+	}, base)
+	if base == "" {
+		base = "instance"
+	}
+	name := base
+	for i := 2; r.find(name) != nil; i++ {
+		name = base + "-" + strconv.Itoa(i)
+	}
+	return name
+}
+
+func validateInstanceName(name string) error {
+	if !instanceNameRegex.MatchString(name) {
+		return fmt.Errorf("인스턴스 이름 '%s'이(가) 올바르지 않습니다 (문자, 숫자, '.', '_', '-'만 사용 가능)", name)
+	}
+	return nil
+}
+
+// samePath는 두 경로가 같은 위치를 가리키는지 비교합니다 (Windows에서는 대소문자 무시).
+func samePath(a, b string) bool {
+	a, b = filepath.Clean(a), filepath.Clean(b)
+	if pathsCaseInsensitive {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
+
+// touchInstance는 baseDir에 해당하는 등록된 인스턴스 정보를 갱신합니다.
+// 등록되지 않은 경로이면 register가 true일 때 폴더 이름으로 새로 등록합니다.
+func touchInstance(baseDir string, register bool, update func(inst *instance)) {
+	absDir, err := normalizePath(baseDir)
+	if err != nil {
+		return
+	}
+	err = updateRegistry(func(r *instanceRegistry) error {
+		inst := r.findByPath(absDir)
+		if inst == nil {
+			if !register {
+				return nil
+			}
+			inst = &instance{Name: r.uniqueName(filepath.Base(absDir)), Path: absDir}
+			if err := r.add(inst); err != nil {
+				return err
+			}
+			fmt.Printf("ℹ️ '%s' 경로를 인스턴스 '%s'(으)로 등록했습니다.\n", absDir, inst.Name)
+		}
+		update(inst)
+		if currentInstance != nil && samePath(currentInstance.Path, absDir) {
+			*currentInstance = *inst
+		} else if currentInstance == nil && samePath(installDir, absDir) {
+			copied := *inst
+			currentInstance = &copied
+		}
+		return nil
+	})
+	if err != nil {
+		fmt.Printf("⚠️ 인스턴스 정보 갱신 실패: %v\n", err)
+	}
+}
+
+// recordInstanceUpdate는 설치/업데이트가 끝난 인스턴스의 브랜치와 업데이트 시간을 기록합니다.
+func recordInstanceUpdate(baseDir string) {
+	branch, _ := getCurrentGitBranch(baseDir)
+	touchInstance(baseDir, true, func(inst *instance) {
+		if branch != "" {
+			inst.Branch = branch
+		}
+		inst.LastUpdated = time.Now()
+	})
+}
+
+// installBranchForCurrent는 새로 설치할 때 사용할 브랜치를 반환합니다.
+func installBranchForCurrent() string {
+	if currentInstance != nil && currentInstance.Branch != "" {
+		return currentInstance.Branch
+	}
+	return defaultBranch
+}
+
+func currentInstanceLabel() string {
+	if currentInstance == nil {
+		return "(등록되지 않은 경로)"
+	}
+	return currentInstance.Name
+}
+
+// gitHeadSummary는 저장소의 현재 커밋(짧은 해시)과 커밋 시각을 반환합니다.
+func gitHeadSummary(repoPath string) (string, error) {
+	cmd := exec.Command(gitExecutablePath, "-C", repoPath, "log", "-1", "--format=%h (%cd)", "--date=format:%Y-%m-%d %H:%M")
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("현재 커밋 확인 실패: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// printInstanceList는 등록된 인스턴스를 현재 커밋과 마지막 업데이트 시간과 함께 출력합니다.
+func printInstanceList(registry *instanceRegistry) {
+	if len(registry.Instances) == 0 {
+		fmt.Println("등록된 인스턴스가 없습니다.")
+		return
+	}
+	for i, inst := range registry.Instances {
+		marker := ""
+		if currentInstance != nil && inst.Name == currentInstance.Name {
+			marker = " (현재 선택)"
+		}
+		fmt.Printf("[%d] %s%s\n", i+1, inst.Name, marker)
+		fmt.Printf("    경로: %s\n", inst.Path)
+
+		branch := inst.Branch
+		if cb, err := getCurrentGitBranch(inst.Path); err == nil {
+			branch = cb
+		}
+		if branch == "" {
+			branch = "-"
+		}
+		port := "-"
+		if p, err := readConfigPort(filepath.Join(inst.Path, configFileName)); err == nil {
+			port = strconv.Itoa(p)
+		} else if inst.Port != 0 {
+			port = strconv.Itoa(inst.Port)
+		}
+		if inst.PinRef != "" {
+			branch += fmt.Sprintf(" (%s %s에 고정)", pinKindLabel(inst.PinKind), inst.PinRef)
+		}
+		fmt.Printf("    브랜치: %s | 포트: %s | Node.js: %s\n", branch, port, nodeRuntimeLabel(inst.NodeRuntime))
+
+		commit := "(설치되지 않음)"
+		if _, err := os.Stat(filepath.Join(inst.Path, ".git")); err == nil {
+			if summary, err := gitHeadSummary(inst.Path); err == nil {
+				commit = summary
+			} else {
+				commit = "(확인 실패)"
+			}
+		}
+		lastUpdated := "(기록 없음)"
+		if !inst.LastUpdated.IsZero() {
+			lastUpdated = inst.LastUpdated.Local().Format("2006-01-02 15:04")
+		}
+		fmt.Printf("    커밋: %s | 마지막 업데이트: %s\n", commit, lastUpdated)
+	}
+}
+
+// selectInstance는 인스턴스를 현재 작업 대상으로 선택하고 다음 실행에도 사용하도록 저장합니다.
+func selectInstance(name string) error {
+	var selected *instance
+	err := updateRegistry(func(r *instanceRegistry) error {
+		inst := r.find(name)
+		if inst == nil {
+			return fmt.Errorf("%w: '%s'", errUnknownInstance, name)
+		}
+		r.Active = inst.Name
+		selected = inst
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	installDir, installDirSource, currentInstance = selected.Path, fmt.Sprintf("선택된 인스턴스 '%s'", selected.Name), selected
+	return nil
+}
+
+// addInstance는 새 인스턴스를 등록합니다. 경로에 아직 SillyTavern이 없어도 등록할 수 있으며,
+// 이후 선택하여 '설치|업데이트'를 실행하면 지정한 브랜치로 설치됩니다.
+func addInstance(name, path, branch string, port int) (*instance, error) {
+	absPath, err := normalizePath(path)
+	if err != nil {
+		return nil, err
+	}
+	inst := &instance{Name: name, Path: absPath, Branch: branch, Port: port}
+	if err := updateRegistry(func(r *instanceRegistry) error { return r.add(inst) }); err != nil {
+		return nil, err
+	}
+	return inst, nil
+}
+
+func removeInstance(name string) error {
+	err := updateRegistry(func(r *instanceRegistry) error {
+		if !r.remove(name) {
+			return fmt.Errorf("%w: '%s'", errUnknownInstance, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if currentInstance != nil && currentInstance.Name == name {
+		currentInstance = nil
+	}
+	return nil
+}
+
+// manageInstances는 인스턴스 목록 보기/선택/추가/삭제 메뉴입니다.
+func manageInstances() {
+	fmt.Println("\n[ 인스턴스 목록 / 선택 ]")
+	registry, err := loadRegistry()
+	if err != nil {
+		fmt.Println("❌ 인스턴스 목록을 읽지 못했습니다:", err)
+		return
+	}
+	fmt.Printf("현재 작업 대상: %s (%s)\n\n", installDir, installDirSource)
+	printInstanceList(registry)
+
+	fmt.Println("\n1. 인스턴스 선택")
+	fmt.Println("2. 인스턴스 추가")
+	fmt.Println("3. 인스턴스 등록 해제 (파일은 삭제하지 않음)")
+	fmt.Println("4. Node.js 실행 환경 변경 (시스템/인스턴스 전용)")
+	fmt.Println("5. 돌아가기")
+	fmt.Print("\n선택하세요 (1-5): ")
+
+	switch getUserChoice() {
+	case "1":
+		if len(registry.Instances) == 0 {
+			fmt.Println("선택할 인스턴스가 없습니다. 먼저 인스턴스를 추가해주세요.")
+			return
+		}
+		fmt.Print("선택할 인스턴스 번호 또는 이름을 입력하세요: ")
+		name := instanceNameFromInput(registry, getUserChoice())
+		if err := selectInstance(name); err != nil {
+			fmt.Println("❌", err)
+			return
+		}
+		fmt.Printf("✅ 인스턴스 '%s'(%s)를 선택했습니다.\n", currentInstance.Name, currentInstance.Path)
+	case "2":
+		fmt.Print("인스턴스 이름 (예: stable, testing): ")
+		name := getUserChoice()
+		fmt.Print("설치 경로 (공백 포함 가능): ")
+		path := getUserChoice()
+		fmt.Printf("브랜치 (비워두면 %s): ", defaultBranch)
+		branch := getUserChoice()
+		if branch == "" {
+			branch = defaultBranch
+		}
+		inst, err := addInstance(name, path, branch, 0)
+		if err != nil {
+			fmt.Println("❌ 인스턴스 추가 실패:", err)
+			return
+		}
+		fmt.Printf("✅ 인스턴스 '%s'을(를) 추가했습니다 (경로: %s, 브랜치: %s).\n", inst.Name, inst.Path, inst.Branch)
+		if confirm("지금 이 인스턴스를 선택하시겠습니까? (y/n): ") {
+			if err := selectInstance(inst.Name); err != nil {
+				fmt.Println("❌", err)
+			}
+		}
+	case "3":
+		fmt.Print("등록 해제할 인스턴스 번호 또는 이름을 입력하세요: ")
+		name := instanceNameFromInput(registry, getUserChoice())
+		if err := removeInstance(name); err != nil {
+			fmt.Println("❌", err)
+			return
+		}
+		fmt.Printf("✅ 인스턴스 '%s'의 등록을 해제했습니다.\n", name)
+	case "4":
+		nodeRuntimeMenu()
+	}
+}
+
+// instanceNameFromInput은 메뉴 입력(번호 또는 이름)을 인스턴스 이름으로 변환합니다.
+func instanceNameFromInput(registry *instanceRegistry, input string) string {
+	input = strings.TrimSpace(input)
+	if n, err := strconv.Atoi(input); err == nil && n >= 1 && n <= len(registry.Instances) {
+		return registry.Instances[n-1].Name
+	}
+	return input
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFindByPath(t *testing.T) {
+	withInstallerState(t)
+	root := t.TempDir()
+	tavern := filepath.Join(root, "Silly Tavern")
+	registry := &instanceRegistry{Instances: []*instance{
+		{Name: "main", Path: tavern},
+		{Name: "other", Path: filepath.Join(root, "other")},
+	}}
+	tests := []struct {
+		path            string
+		caseInsensitive bool
+		want            string
+	}{
+		{tavern, false, "main"},
+		{tavern + string(filepath.Separator), false, "main"},
+		{filepath.Join(root, "x", "..", "Silly Tavern"), false, "main"},
+		{filepath.Join(root, "silly tavern"), false, ""},
+		{filepath.Join(root, "silly tavern"), true, "main"},
+		{filepath.Join(strings.ToUpper(root), "SILLY TAVERN"), true, "main"},
+		{filepath.Join(root, "Silly"), true, ""},
+		{filepath.Join(root, "OTHER"), true, "other"},
+	}
+	for _, tt := range tests {
+		pathsCaseInsensitive = tt.caseInsensitive
+		var got string
+		if inst := registry.findByPath(tt.path); inst != nil {
+			got = inst.Name
+		}
+		if got != tt.want {
+			t.Errorf("findByPath(%q, caseInsensitive=%v) = %q, want %q", tt.path, tt.caseInsensitive, got, tt.want)
+		}
+	}
+}
+
+func TestInstanceKeyCaseInsensitive(t *testing.T) {
+	withInstallerState(t)
+	dir := filepath.Join(t.TempDir(), "Silly Tavern")
+	upper := filepath.Join(filepath.Dir(dir), "SILLY TAVERN")
+	for _, caseInsensitive := range []bool{false, true} {
+		pathsCaseInsensitive = caseInsensitive
+		a, errA := instanceKey(dir)
+		b, errB := instanceKey(upper)
+		if errA != nil || errB != nil {
+			t.Fatal(errA, errB)
+		}
+		// 폴더 이름은 그대로 두고 해시만 대소문자를 무시합니다.
+		if !strings.HasPrefix(a, "Silly Tavern-") || !strings.HasPrefix(b, "SILLY TAVERN-") {
+			t.Errorf("keys %q, %q do not keep the folder name", a, b)
+		}
+		if sameHash := a[len(a)-8:] == b[len(b)-8:]; sameHash != caseInsensitive {
+			t.Errorf("caseInsensitive=%v: keys %q and %q share a hash: %v", caseInsensitive, a, b, sameHash)
+		}
+	}
+}
+
+func TestTouchInstance(t *testing.T) {
+	home := withInstallerState(t)
+	root := t.TempDir()
+	tavern := filepath.Join(root, "Silly Tavern")
+	setPort := func(port int) func(inst *instance) {
+		return func(inst *instance) { inst.Port = port }
+	}
+	load := func() *instanceRegistry {
+		t.Helper()
+		registry, err := loadRegistry()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return registry
+	}
+
+	// 등록하지 않는 호출은 모르는 경로를 무시합니다.
+	touchInstance(tavern, false, setPort(8001))
+	if registry := load(); len(registry.Instances) != 0 {
+		t.Fatalf("registered without register=true: %+v", registry.Instances)
+	}
+
+	// 폴더 이름으로 등록하며, 인스턴스 이름에 쓸 수 없는 공백은 '-'로 바꿉니다.
+	touchInstance(tavern, true, setPort(8001))
+	registry := load()
+	if len(registry.Instances) != 1 || registry.Instances[0].Name != "Silly-Tavern" || registry.Instances[0].Path != tavern || registry.Instances[0].Port != 8001 {
+		t.Fatalf("registered %+v", registry.Instances[0])
+	}
+
+	// 같은 위치를 가리키는 다른 표기는 같은 인스턴스를 갱신합니다.
+	touchInstance(filepath.Join(root, "x", "..", "Silly Tavern")+string(filepath.Separator), true, setPort(8002))
+	if registry := load(); len(registry.Instances) != 1 || registry.Instances[0].Port != 8002 {
+		t.Fatalf("instances after touching the same path = %+v", registry.Instances)
+	}
+
+	// 폴더 이름이 같은 다른 경로는 새 이름으로 등록합니다.
+	otherTavern := filepath.Join(root, "nested", "Silly Tavern")
+	touchInstance(otherTavern, true, setPort(8003))
+	registry = load()
+	if len(registry.Instances) != 2 || registry.Instances[1].Name != "Silly-Tavern-2" || registry.Instances[1].Path != otherTavern {
+		t.Fatalf("second instance = %+v", registry.Instances)
+	}
+
+	// Windows처럼 대소문자를 구분하지 않으면 대소문자만 다른 경로도 같은 인스턴스입니다.
+	pathsCaseInsensitive = true
+	touchInstance(strings.ToUpper(tavern), true, setPort(8004))
+	registry = load()
+	if len(registry.Instances) != 2 || registry.Instances[0].Port != 8004 || registry.Instances[0].Path != tavern {
+		t.Fatalf("instances after case-insensitive touch = %+v", registry.Instances)
+	}
+	pathsCaseInsensitive = false
+
+	// '~' 경로도 홈 디렉토리 기준으로 풀어서 등록합니다.
+	touchInstance("~/home tavern", true, setPort(8005))
+	registry = load()
+	if inst := registry.findByPath(filepath.Join(home, "home tavern")); inst == nil || inst.Name != "home-tavern" || inst.Port != 8005 {
+		t.Fatalf("~ instance = %+v", inst)
+	}
+
+	// 현재 선택된 인스턴스 정보도 함께 갱신합니다.
+	installDir, currentInstance = tavern, nil
+	touchInstance(tavern, false, setPort(8006))
+	if currentInstance == nil || currentInstance.Name != "Silly-Tavern" || currentInstance.Port != 8006 {
+		t.Errorf("currentInstance = %+v", currentInstance)
+	}
+	touchInstance(tavern, false, setPort(8007))
+	if currentInstance.Port != 8007 {
+		t.Errorf("currentInstance.Port = %d, want 8007", currentInstance.Port)
+	}
+}
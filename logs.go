@@ -0,0 +1,396 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	logsDirName         = "logs"
+	logFilePrefix       = "server"
+	logFileExt          = ".log"
+	defaultLogMaxSizeMB = 10 // 이 크기를 넘으면 새 로그 파일로 바꿈
+	defaultLogMaxFiles  = 5  // 보관할 이전 로그 파일 수
+	defaultLogMaxAge    = 14 // 이전 로그 파일을 보관할 일 수
+	defaultTailLines    = 50
+)
+
+var logFollowInterval = 500 * time.Millisecond
+
+// logLimits는 로그 파일 교체/정리 기준입니다. 설정(settings.json)의 값이 없으면 기본값을 사용합니다.
+type logLimits struct {
+	maxSize  int64
+	maxFiles int
+	maxAge   time.Duration
+}
+
+func loadLogLimits() logLimits {
+	limits := logLimits{defaultLogMaxSizeMB << 20, defaultLogMaxFiles, defaultLogMaxAge * 24 * time.Hour}
+	settings, err := loadSettings()
+	if err != nil {
+		return limits
+	}
+	if settings.LogMaxSizeMB > 0 {
+		limits.maxSize = int64(settings.LogMaxSizeMB) << 20
+	}
+	if settings.LogMaxFiles > 0 {
+		limits.maxFiles = settings.LogMaxFiles
+	}
+	if settings.LogMaxAgeDays > 0 {
+		limits.maxAge = time.Duration(settings.LogMaxAgeDays) * 24 * time.Hour
+	}
+	return limits
+}
+
+// serverLogDir은 baseDir 인스턴스의 SillyTavern 로그를 보관하는 디렉토리입니다.
+func serverLogDir(baseDir string) (string, error) {
+	return instanceDataDir(baseDir, logsDirName)
+}
+
+func serverLogPath(baseDir string) (string, error) {
+	dir, err := serverLogDir(baseDir)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, logFilePrefix+logFileExt), nil
+}
+
+// rotatingLog는 크기 제한을 넘으면 현재 파일을 server-<시각>.log로 바꾸고 새 파일에 이어 쓰는 io.Writer입니다.
+// 바꿀 때마다 보관 개수와 보관 기간을 넘은 이전 파일을 지웁니다. 여러 고루틴에서 함께 쓸 수 있습니다.
+type rotatingLog struct {
+	mu     sync.Mutex
+	path   string
+	limits logLimits
+	file   *os.File
+	size   int64
+}
+
+func openRotatingLog(path string, limits logLimits) (*rotatingLog, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("디렉토리(%s) 생성 실패: %w", filepath.Dir(path), err)
+	}
+	l := &rotatingLog{path: path, limits: limits}
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+	l.prune()
+	return l, nil
+}
+
+func (l *rotatingLog) open() error {
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("로그 파일(%s) 열기 실패: %w", l.path, err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("로그 파일(%s) 확인 실패: %w", l.path, err)
+	}
+	l.file, l.size = f, info.Size()
+	return nil
+}
+
+func (l *rotatingLog) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return 0, os.ErrClosed
+	}
+	if l.size > 0 && l.size+int64(len(p)) > l.limits.maxSize {
+		if err := l.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := l.file.Write(p)
+	l.size += int64(n)
+	return n, err
+}
+
+// rotate는 현재 파일을 닫고 시각을 붙인 이름으로 바꾼 뒤 새 파일을 엽니다.
+func (l *rotatingLog) rotate() error {
+	l.file.Close()
+	l.file = nil
+	var rotated string
+	for stamp := time.Now(); ; stamp = stamp.Add(time.Millisecond) {
+		rotated = filepath.Join(filepath.Dir(l.path), logFilePrefix+"-"+stamp.Format("20060102-150405.000")+logFileExt)
+		if _, err := os.Stat(rotated); os.IsNotExist(err) {
+			break
+		}
+	}
+	renameErr := os.Rename(l.path, rotated) // Windows에서는 로그를 보는 중이면 실패할 수 있음
+	if err := l.open(); err != nil {
+		return err
+	}
+	if renameErr != nil {
+		l.size = 0 // 같은 파일에 이어 쓰고, 다시 제한만큼 쓴 뒤에 재시도
+	}
+	l.prune()
+	return nil
+}
+
+// prune은 보관 개수를 넘거나 보관 기간이 지난 이전 로그 파일을 지웁니다.
+func (l *rotatingLog) prune() {
+	rotated := rotatedLogFiles(filepath.Dir(l.path))
+	for i, path := range rotated {
+		tooMany := len(rotated)-i > l.limits.maxFiles
+		if info, err := os.Stat(path); err == nil && (tooMany || time.Since(info.ModTime()) > l.limits.maxAge) {
+			os.Remove(path)
+		}
+	}
+}
+
+func (l *rotatingLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+// rotatedLogFiles는 dir의 이전 로그 파일을 오래된 것부터 반환합니다 (이름에 시각이 들어 있어 이름순이 곧 시간순).
+func rotatedLogFiles(dir string) []string {
+	files, _ := filepath.Glob(filepath.Join(dir, logFilePrefix+"-*"+logFileExt))
+	sort.Strings(files)
+	return files
+}
+
+// serverLogFiles는 baseDir 인스턴스의 로그 파일을 오래된 것부터 반환합니다. 마지막이 현재 파일입니다.
+func serverLogFiles(baseDir string) ([]string, error) {
+	current, err := serverLogPath(baseDir)
+	if err != nil {
+		return nil, err
+	}
+	files := rotatedLogFiles(filepath.Dir(current))
+	if _, err := os.Stat(current); err == nil {
+		files = append(files, current)
+	}
+	return files, nil
+}
+
+// tailLines는 files(오래된 것부터)의 마지막 n줄을 반환합니다.
+func tailLines(files []string, n int) ([]string, error) {
+	var lines []string
+	for i := len(files) - 1; i >= 0 && len(lines) < n; i-- {
+		fileLines, err := lastLines(files[i], n-len(lines))
+		if err != nil {
+			return nil, err
+		}
+		lines = append(fileLines, lines...)
+	}
+	return lines, nil
+}
+
+func lastLines(path string, n int) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("로그 파일(%s) 열기 실패: %w", path, err)
+	}
+	defer f.Close()
+	ring := make([]string, 0, n)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if len(ring) == n {
+			ring = append(ring[1:], scanner.Text())
+		} else {
+			ring = append(ring, scanner.Text())
+		}
+	}
+	return ring, scanner.Err()
+}
+
+// printServerLog는 마지막 lines줄을 출력합니다. follow가 true이면 Ctrl+C를 누를 때까지 새로 기록되는 내용을 계속 출력합니다.
+func printServerLog(baseDir string, lines int, follow bool) error {
+	files, err := serverLogFiles(baseDir)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 && !follow {
+		fmt.Println("ℹ️ 아직 기록된 로그가 없습니다. 설치 프로그램으로 SillyTavern을 실행하면 기록됩니다.")
+		return nil
+	}
+	tail, err := tailLines(files, lines)
+	if err != nil {
+		return err
+	}
+	for _, line := range tail {
+		fmt.Println(line)
+	}
+	if !follow {
+		return nil
+	}
+	path, err := serverLogPath(baseDir)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("--- %s 실시간 보기 (Ctrl+C로 종료) ---\n", path)
+	return followLog(path)
+}
+
+// followLog는 path 끝에 추가되는 내용을 계속 출력합니다. 파일이 교체(새 파일로 바뀜)되면 새 파일을 처음부터 읽습니다.
+func followLog(path string) error {
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	defer signal.Stop(interrupts)
+
+	var f *os.File
+	var offset int64
+	if info, err := os.Stat(path); err == nil {
+		offset = info.Size()
+	}
+	defer func() {
+		if f != nil {
+			f.Close()
+		}
+	}()
+	ticker := time.NewTicker(logFollowInterval)
+	defer ticker.Stop()
+	for {
+		if f == nil {
+			if opened, err := os.Open(path); err == nil {
+				f = opened
+				f.Seek(offset, io.SeekStart)
+			}
+		}
+		if f != nil {
+			info, errFile := f.Stat()
+			current, errPath := os.Stat(path)
+			if _, err := io.Copy(os.Stdout, f); err != nil {
+				return fmt.Errorf("로그 읽기 실패: %w", err)
+			}
+			if errFile == nil && (errPath != nil || !os.SameFile(info, current)) {
+				f.Close() // 교체된 이전 파일은 끝까지 출력했으니 새 파일로 넘어감
+				f, offset = nil, 0
+			}
+		}
+		select {
+		case <-interrupts:
+			fmt.Println()
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// searchServerLog는 모든 로그 파일에서 query가 들어 있는 줄(대소문자 무시)을 찾아 출력하고, 찾은 줄 수를 반환합니다.
+func searchServerLog(baseDir, query string) (int, error) {
+	files, err := serverLogFiles(baseDir)
+	if err != nil {
+		return 0, err
+	}
+	needle := strings.ToLower(query)
+	found := 0
+	for _, path := range files {
+		f, err := os.Open(path)
+		if err != nil {
+			return found, fmt.Errorf("로그 파일(%s) 열기 실패: %w", path, err)
+		}
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for lineNo := 1; scanner.Scan(); lineNo++ {
+			if strings.Contains(strings.ToLower(scanner.Text()), needle) {
+				fmt.Printf("%s:%d: %s\n", filepath.Base(path), lineNo, scanner.Text())
+				found++
+			}
+		}
+		f.Close()
+		if err := scanner.Err(); err != nil {
+			return found, fmt.Errorf("로그 파일(%s) 읽기 실패: %w", path, err)
+		}
+	}
+	return found, nil
+}
+
+// npmLogDir은 npm이 오류 로그를 남기는 디렉토리(<npm 캐시>/_logs)를 반환합니다.
+// 'npm config get cache'로 확인하고, 실패하면 OS별 기본 위치를 사용합니다.
+func npmLogDir(env []string) string {
+	cmd := exec.Command(npmExecutablePath, "config", "get", "cache")
+	cmd.Env = env
+	if out, err := cmd.Output(); err == nil && strings.TrimSpace(string(out)) != "" {
+		return filepath.Join(strings.TrimSpace(string(out)), "_logs")
+	}
+	if runtime.GOOS == "windows" {
+		if localAppData := os.Getenv("LOCALAPPDATA"); localAppData != "" {
+			return filepath.Join(localAppData, "npm-cache", "_logs")
+		}
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	if runtime.GOOS == "windows" {
+		return filepath.Join(home, "AppData", "Local", "npm-cache", "_logs")
+	}
+	return filepath.Join(home, ".npm", "_logs")
+}
+
+// latestFile은 dir에서 가장 최근에 수정된 파일의 경로를 반환합니다. 없으면 빈 문자열입니다.
+func latestFile(dir string) string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+	var latest string
+	var latestTime time.Time
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || entry.IsDir() {
+			continue
+		}
+		if info.ModTime().After(latestTime) {
+			latest, latestTime = filepath.Join(dir, entry.Name()), info.ModTime()
+		}
+	}
+	return latest
+}
+
+// logsMenu는 메뉴의 '로그 보기' 항목입니다.
+func logsMenu() {
+	path, err := serverLogPath(installDir)
+	if err != nil {
+		fmt.Println("❌", err)
+		return
+	}
+	fmt.Println("\n[ 로그 보기 ]")
+	fmt.Printf("로그 위치: %s\n", filepath.Dir(path))
+	fmt.Printf("\n1. 최근 %d줄 보기\n", defaultTailLines)
+	fmt.Println("2. 실시간으로 보기 (Ctrl+C로 종료)")
+	fmt.Println("3. 검색")
+	fmt.Println("4. 돌아가기")
+	fmt.Print("\n선택하세요 (1-4): ")
+
+	switch getUserChoice() {
+	case "1":
+		err = printServerLog(installDir, defaultTailLines, false)
+	case "2":
+		err = printServerLog(installDir, defaultTailLines, true)
+	case "3":
+		fmt.Print("찾을 내용을 입력하세요: ")
+		query := getUserChoice()
+		if query == "" {
+			return
+		}
+		var found int
+		found, err = searchServerLog(installDir, query)
+		if err == nil {
+			fmt.Printf("\n'%s': %d줄 찾음\n", query, found)
+		}
+	}
+	if err != nil {
+		fmt.Println("❌", err)
+	}
+}
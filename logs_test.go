@@ -0,0 +1,193 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+var rotatedLogName = regexp.MustCompile(`^server-\d{8}-\d{6}\.\d{3}\.log$`)
+
+// logLine은 길이가 30바이트로 같은 로그 줄입니다.
+func logLine(i int) string {
+	return fmt.Sprintf("line %03d %s\n", i, strings.Repeat("x", 20))
+}
+
+func writeLogLines(t *testing.T, l *rotatingLog, from, to int) {
+	t.Helper()
+	for i := from; i <= to; i++ {
+		if _, err := l.Write([]byte(logLine(i))); err != nil {
+			t.Fatalf("Write(%d): %v", i, err)
+		}
+	}
+}
+
+func allLogFiles(path string) []string {
+	return append(rotatedLogFiles(filepath.Dir(path)), path)
+}
+
+func wantLogLines(from, to int) []string {
+	var lines []string
+	for i := from; i <= to; i++ {
+		lines = append(lines, strings.TrimSuffix(logLine(i), "\n"))
+	}
+	return lines
+}
+
+func TestRotatingLogRotatesPastMaxSize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", logFilePrefix+logFileExt)
+	l, err := openRotatingLog(path, logLimits{maxSize: 100, maxFiles: 10, maxAge: 24 * time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	// 한 파일에 30바이트 줄이 3개까지 들어가므로 10줄이면 이전 파일 3개와 현재 파일(1줄)이 됩니다.
+	writeLogLines(t, l, 1, 10)
+
+	rotated := rotatedLogFiles(filepath.Dir(path))
+	if len(rotated) != 3 {
+		t.Fatalf("rotated files = %q, want 3", rotated)
+	}
+	for i, file := range rotated {
+		if name := filepath.Base(file); !rotatedLogName.MatchString(name) {
+			t.Errorf("rotated file name %q does not match %s", name, rotatedLogName)
+		}
+		if got := strings.Join(wantLogLines(i*3+1, i*3+3), "\n") + "\n"; readFileT(t, file) != got {
+			t.Errorf("%s = %q, want %q", file, readFileT(t, file), got)
+		}
+	}
+	if got := readFileT(t, path); got != logLine(10) {
+		t.Errorf("current file = %q", got)
+	}
+	if lines, err := tailLines(allLogFiles(path), 100); err != nil || !reflect.DeepEqual(lines, wantLogLines(1, 10)) {
+		t.Errorf("tailLines = %q, %v", lines, err)
+	}
+
+	// 다시 열면 현재 파일 크기부터 이어서 셉니다.
+	l.Close()
+	l, err = openRotatingLog(path, logLimits{maxSize: 100, maxFiles: 10, maxAge: 24 * time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeLogLines(t, l, 11, 13)
+	if n := len(rotatedLogFiles(filepath.Dir(path))); n != 4 {
+		t.Errorf("rotated files after reopen = %d, want 4", n)
+	}
+	if got := readFileT(t, path); got != logLine(13) {
+		t.Errorf("current file after reopen = %q", got)
+	}
+}
+
+func TestRotatingLogLargeWriteIntoEmptyFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), logFilePrefix+logFileExt)
+	l, err := openRotatingLog(path, logLimits{maxSize: 10, maxFiles: 10, maxAge: 24 * time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	// 빈 파일에는 제한보다 긴 내용도 나누지 않고 씁니다.
+	writeLogLines(t, l, 1, 1)
+	if rotated := rotatedLogFiles(filepath.Dir(path)); len(rotated) != 0 {
+		t.Errorf("rotated an empty file: %q", rotated)
+	}
+	writeLogLines(t, l, 2, 2)
+	if rotated := rotatedLogFiles(filepath.Dir(path)); len(rotated) != 1 || readFileT(t, rotated[0]) != logLine(1) {
+		t.Errorf("rotated files = %q", rotated)
+	}
+}
+
+func TestRotatingLogPrunesByCount(t *testing.T) {
+	path := filepath.Join(t.TempDir(), logFilePrefix+logFileExt)
+	l, err := openRotatingLog(path, logLimits{maxSize: 100, maxFiles: 2, maxAge: 24 * time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	writeLogLines(t, l, 1, 10)
+
+	rotated := rotatedLogFiles(filepath.Dir(path))
+	if len(rotated) != 2 {
+		t.Fatalf("rotated files = %q, want the 2 newest", rotated)
+	}
+	if lines, err := tailLines(allLogFiles(path), 100); err != nil || !reflect.DeepEqual(lines, wantLogLines(4, 10)) {
+		t.Errorf("tailLines = %q, %v; want lines 4-10", lines, err)
+	}
+}
+
+func TestRotatingLogPrunesByAge(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, logFilePrefix+logFileExt)
+	now := time.Now()
+	files := map[string]time.Duration{
+		"server-20250101-000000.000.log": 30 * 24 * time.Hour,
+		"server-20250102-000000.000.log": 15 * 24 * time.Hour,
+		"server-20250103-000000.000.log": 13 * 24 * time.Hour,
+		"server-20250104-000000.000.log": time.Hour,
+		"other.log":                      30 * 24 * time.Hour, // 이전 로그 파일 이름이 아니므로 건드리지 않음
+	}
+	for name, age := range files {
+		file := filepath.Join(dir, name)
+		writeFileT(t, file, name+"\n")
+		if err := os.Chtimes(file, now.Add(-age), now.Add(-age)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	l, err := openRotatingLog(path, logLimits{maxSize: 100, maxFiles: 10, maxAge: 14 * 24 * time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	var kept []string
+	for _, file := range rotatedLogFiles(dir) {
+		kept = append(kept, filepath.Base(file))
+	}
+	if want := []string{"server-20250103-000000.000.log", "server-20250104-000000.000.log"}; !reflect.DeepEqual(kept, want) {
+		t.Errorf("kept %q, want %q", kept, want)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "other.log")); err != nil {
+		t.Errorf("unrelated file removed: %v", err)
+	}
+}
+
+func TestTailLines(t *testing.T) {
+	dir := t.TempDir()
+	rotatedA := filepath.Join(dir, "server-20250101-000000.000.log")
+	rotatedB := filepath.Join(dir, "server-20250102-000000.000.log")
+	current := filepath.Join(dir, logFilePrefix+logFileExt)
+	writeFileT(t, rotatedA, "1\n2\n3\n")
+	writeFileT(t, rotatedB, "4\n5\n6") // 마지막 줄바꿈 없이 교체된 파일
+	writeFileT(t, current, "7\n8\n")
+	files := []string{rotatedA, rotatedB, current}
+
+	tests := []struct {
+		n    int
+		want []string
+	}{
+		{1, []string{"8"}},
+		{2, []string{"7", "8"}},
+		{3, []string{"6", "7", "8"}},
+		{5, []string{"4", "5", "6", "7", "8"}},
+		{6, []string{"3", "4", "5", "6", "7", "8"}},
+		{100, []string{"1", "2", "3", "4", "5", "6", "7", "8"}},
+	}
+	for _, tt := range tests {
+		if got, err := tailLines(files, tt.n); err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("tailLines(%d) = %q, %v; want %q", tt.n, got, err, tt.want)
+		}
+	}
+
+	// 필요한 줄을 이미 모았으면 더 오래된 파일은 열지 않습니다.
+	missing := []string{filepath.Join(dir, "server-20240101-000000.000.log"), current}
+	if got, err := tailLines(missing, 2); err != nil || !reflect.DeepEqual(got, []string{"7", "8"}) {
+		t.Errorf("tailLines with a missing older file = %q, %v", got, err)
+	}
+	if _, err := tailLines(missing, 3); err == nil {
+		t.Error("tailLines ignored a missing file it had to read")
+	}
+}
@@ -3,9 +3,9 @@ package main
 import (
 	"bufio"
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
-	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -13,30 +13,21 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
-	"syscall"
 	"time"
-	"unsafe"
-
-	"golang.org/x/sys/windows/registry" // 레지스트리 접근용
-	"gopkg.in/yaml.v3"                  // YAML 처리용
-
-	기초 "golang.org/x/sys/windows" // 관리자 권한 확인 등 Windows 특정 API용
 )
 
 const (
-	repoURL          = "https://github.com/SillyTavern/SillyTavern.git"
-	defaultBranch    = "release"
-	stagingBranch    = "staging"
-	defaultBaseDir   = "SillyTavern"
-	configFileName   = "config.yaml"
-	nodeJSWindowsURL = "https://nodejs.org/dist/v22.2.0/node-v22.2.0-x64.msi"                                             // LTS 버전은 주기적으로 확인/업데이트 필요
-	gitForWindowsURL = "https://github.com/git-for-windows/git/releases/download/v2.45.2.windows.1/Git-2.45.2-64-bit.exe" // 최신 버전 확인/업데이트 필요
-
-	// Windows 레지스트리 및 메시지 관련 상수
-	regPathEnv       = `SYSTEM\CurrentControlSet\Control\Session Manager\Environment`
-	HWND_BROADCAST   = uintptr(0xFFFF)
-	WM_SETTINGCHANGE = uintptr(0x001A)
-	SMTO_ABORTIFHUNG = uintptr(0x0002)
+	repoURL               = "https://github.com/SillyTavern/SillyTavern.git"
+	defaultBranch         = "release"
+	stagingBranch         = "staging"
+	defaultBaseDir        = "SillyTavern"
+	configFileName        = "config.yaml"
+	gitForWindowsURL      = "https://github.com/git-for-windows/git/releases/download/v2.45.2.windows.1/Git-2.45.2-64-bit.exe" // 최신 릴리스를 확인할 수 없을 때 사용하는 고정 버전
+	nodeJSFallbackVersion = "v22.2.0"                                                                                          // 최신 LTS를 확인할 수 없을 때(오프라인 등) 사용하는 고정 버전
+
+	// 자동 설치 실패 시 안내할 수동 다운로드 페이지 (항상 최신 버전을 안내)
+	nodeJSDownloadPage = "https://nodejs.org/ko/download"
+	gitDownloadPage    = "https://git-scm.com/download/win"
 )
 
 var (
@@ -49,11 +40,22 @@ var (
 	defaultGitCmdPathWindows  string
 	defaultNodeExePathWindows string
 	defaultNpmCmdPathWindows  string
+
+	installDir       string = defaultBaseDir // SillyTavern 설치 경로 (applyInstallDir에서 결정)
+	installDirSource string = installDirSourceDef
+
+	// 서브커맨드(비대화형) 실행 관련
+	nonInteractive bool // true이면 사용자 입력을 기다리지 않음
+	assumeYes      bool // 비대화형 실행 시 (y/n) 질문에 'y'로 응답
+
+	errDependencyMissing = errors.New("필수 프로그램(Git/Node.js)을 사용할 수 없습니다")
+	errNotInstalled      = errors.New("SillyTavern이 설치되어 있지 않습니다")
+	errCloneFailed       = errors.New("저장소 클론 실패")
 )
 
 func init() {
+	isAdmin = host.isAdmin()
 	if runtime.GOOS == "windows" {
-		isAdmin = amIAdmin()
 
 		// Program Files 경로 설정
 		progFiles := os.Getenv("ProgramFiles")
@@ -82,27 +84,21 @@ func init() {
 	}
 }
 
-func amIAdmin() bool {
-	var sid *기초.SID
-	err := 기초.AllocateAndInitializeSid(
-		&기초.SECURITY_NT_AUTHORITY, 2,
-		기초.SECURITY_BUILTIN_DOMAIN_RID, 기초.DOMAIN_ALIAS_RID_ADMINS,
-		0, 0, 0, 0, 0, 0, &sid)
+func main() {
+	args, err := parseGlobalFlags(os.Args[1:])
 	if err != nil {
-		return false
+		os.Exit(usageError("%v", err))
 	}
-	defer 기초.FreeSid(sid)
-	token := 기초.Token(0)
-	member, err := token.IsMember(sid)
-	if err != nil {
-		return false
+	if len(args) > 0 {
+		os.Exit(runCLI(args))
 	}
-	return member
-}
 
-func main() {
 	setConsoleTitle("SillyTavern Installer & Configurator")
 	clearScreen()
+	if err := applyInstallDir(); err != nil {
+		fmt.Println("❌", err)
+		waitForExit()
+	}
 	printHeader()
 
 	if runtime.GOOS == "windows" {
@@ -121,7 +117,9 @@ func main() {
 		fmt.Println()
 	}
 
-	checkDependencies()
+	if err := checkDependencies(); err != nil {
+		waitForExit()
+	}
 
 	for {
 		printMenu()
@@ -138,6 +136,20 @@ func main() {
 		case "4":
 			updateWhitelistSetting()
 		case "5":
+			changeInstallDirSetting()
+		case "6":
+			manageInstances()
+		case "7":
+			pinVersionMenu()
+		case "8":
+			rollbackMenu()
+		case "9":
+			serverMenu()
+		case "10":
+			backupMenu()
+		case "11":
+			checkForUpdatesMenu()
+		case "0":
 			fmt.Println("\n종료합니다...")
 			return
 		default:
@@ -151,30 +163,28 @@ func main() {
 }
 
 func printMenu() {
-	fmt.Println("[ 메뉴 ]")
+	fmt.Printf("[ 메뉴 ] 대상 인스턴스: %s (%s)\n", currentInstanceLabel(), installDir)
 	fmt.Println("1. 실리태번 설치|업데이트")
 	fmt.Println("2. 브랜치 변경 (기본|Staging)")
 	fmt.Println("3. 포트(Port) 변경")
 	fmt.Println("4. 화이트리스트(Whitelist) 수정")
-	fmt.Println("5. 종료")
-	fmt.Print("\n선택하세요 (1-5): ")
+	fmt.Println("5. 설치 경로 변경")
+	fmt.Println("6. 인스턴스 목록 / 선택")
+	fmt.Println("7. 버전 고정 (태그/커밋)")
+	fmt.Println("8. 이전 버전으로 되돌리기")
+	fmt.Println("9. 실리태번 실행 / 중지")
+	fmt.Println("10. 데이터 백업 / 복원")
+	fmt.Println("11. 업데이트 확인 (변경 내역 미리보기)")
+	fmt.Println("0. 종료")
+	fmt.Print("\n선택하세요 (0-11): ")
 }
 
 func clearScreen() {
-	if runtime.GOOS == "windows" {
-		cmd := exec.Command("cmd", "/c", "cls")
-		cmd.Stdout = os.Stdout
-		cmd.Run()
-	} else {
-		fmt.Print("\033[H\033[2J")
-	}
+	host.clearScreen()
 }
 
 func setConsoleTitle(title string) {
-	if runtime.GOOS == "windows" {
-		cmd := exec.Command("cmd", "/c", "title", title)
-		cmd.Run()
-	}
+	host.setConsoleTitle(title)
 }
 
 func printHeader() {
@@ -188,9 +198,11 @@ func printHeader() {
 		fmt.Println("현재 관리자 권한으로 실행 중이므로, 직접 다운로드하여 설치하고 시스템 PATH에 추가합니다.")
 	}
 	fmt.Println("자동 설치 실패 시 아래 URL을 참고하여 수동으로 설치해주세요.")
-	fmt.Printf("Node.js (LTS) 수동 다운로드 URL: %s\n", nodeJSWindowsURL)
-	fmt.Printf("Git 수동 다운로드 URL: %s\n", gitForWindowsURL)
+	fmt.Printf("Node.js (LTS) 수동 다운로드 URL: %s\n", nodeJSDownloadPage)
+	fmt.Printf("Git 수동 다운로드 URL: %s\n", gitDownloadPage)
 	fmt.Println("SillyTavern 기본 브랜치:", defaultBranch, "(안정)", "Staging 브랜치:", stagingBranch, "(최신/테스트)")
+	fmt.Printf("SillyTavern 설치 경로: %s (%s)\n", installDir, installDirSource)
+	fmt.Println("대상 인스턴스:", currentInstanceLabel())
 	fmt.Println("         ======================================        ")
 	fmt.Println()
 }
@@ -201,46 +213,88 @@ func getUserChoice() string {
 	return strings.TrimSpace(input)
 }
 
-func checkDependencies() {
+// confirm은 (y/n) 질문을 출력하고 응답을 반환합니다.
+// 비대화형 실행 중에는 입력을 기다리지 않고 assumeYes 값을 응답으로 사용합니다.
+func confirm(prompt string) bool {
+	fmt.Print(prompt)
+	if nonInteractive {
+		if assumeYes {
+			fmt.Println("y (--yes)")
+		} else {
+			fmt.Println("n (비대화형 실행, --yes 없음)")
+		}
+		return assumeYes
+	}
+	return strings.ToLower(strings.TrimSpace(getUserChoice())) == "y"
+}
+
+func checkDependencies() error {
 	fmt.Println("필수 프로그램 (Git, Node.js) 확인 중...")
 
-	if p, err := exec.LookPath("git"); err == nil {
+	gitReady := false
+	if p, err := findGit(); err == nil {
 		gitExecutablePath = p
-		fmt.Println("✅ Git 확인 완료 (경로:", gitExecutablePath, ")")
+		if version, err := checkMinimumVersion(gitRequirement, gitExecutablePath); err != nil && errors.Is(err, errVersionTooOld) {
+			fmt.Println("❌", err)
+		} else {
+			if err != nil {
+				fmt.Println("⚠️ Git 버전을 확인하지 못했습니다:", err)
+				fmt.Println("✅ Git 확인 완료 (경로:", gitExecutablePath, ")")
+			} else {
+				fmt.Println("✅ Git 확인 완료 (경로:", gitExecutablePath, ", 버전:", formatVersion(version), ")")
+			}
+			gitReady = true
+		}
 	} else {
 		fmt.Println("❌ Git이 설치되어 있지 않거나 PATH에 없습니다.")
-		fmt.Print("Git 자동 설치를 시도하시겠습니까? (y/n): ")
-		if strings.ToLower(strings.TrimSpace(getUserChoice())) == "y" {
-			installed, foundPath := installGit()
-			if installed {
-				gitExecutablePath = foundPath
-				fmt.Println("✅ Git 설치 또는 PATH 추가 시도 완료.")
-				if gitExecutablePath == "git" {
-					fmt.Println("   PATH가 즉시 적용되지 않았을 수 있습니다. 이 세션에서는 기본 경로로 시도합니다.")
-					if runtime.GOOS == "windows" {
-						if _, errStat := os.Stat(defaultGitCmdPathWindows); errStat == nil {
-							gitExecutablePath = defaultGitCmdPathWindows
-							fmt.Println("   (Windows 기본 Git 경로 사용:", gitExecutablePath, ")")
-						} else {
-							fmt.Println("   ⚠️ Windows 기본 Git 경로도 찾을 수 없습니다. 'git' 명령이 실패할 수 있습니다.")
-						}
-					}
+	}
+	if !gitReady {
+		if !confirm("Git 자동 설치(업그레이드)를 시도하시겠습니까? (y/n): ") {
+			fmt.Println("Git 설치가 필요합니다. 프로그램을 종료합니다.")
+			return fmt.Errorf("%w: Git", errDependencyMissing)
+		}
+		installed, foundPath := installGit()
+		if !installed {
+			fmt.Println("❌ Git 자동 설치 또는 PATH 추가에 실패했습니다. 수동으로 설치 및 PATH 설정 후 다시 실행해주세요.")
+			return fmt.Errorf("%w: Git 설치 실패", errDependencyMissing)
+		}
+		gitExecutablePath = foundPath
+		fmt.Println("✅ Git 설치 또는 PATH 추가 시도 완료.")
+		if gitExecutablePath == "git" {
+			fmt.Println("   PATH가 즉시 적용되지 않았을 수 있습니다. 이 세션에서는 기본 경로로 시도합니다.")
+			if runtime.GOOS == "windows" {
+				if _, errStat := os.Stat(defaultGitCmdPathWindows); errStat == nil {
+					gitExecutablePath = defaultGitCmdPathWindows
+					fmt.Println("   (Windows 기본 Git 경로 사용:", gitExecutablePath, ")")
 				} else {
-					fmt.Println("   (사용할 Git 경로:", gitExecutablePath, ")")
-				}
-				if isAdmin && runtime.GOOS == "windows" {
-					fmt.Println("   시스템 PATH가 업데이트되었을 수 있습니다. 다음 실행부터는 자동으로 인식됩니다.")
+					fmt.Println("   ⚠️ Windows 기본 Git 경로도 찾을 수 없습니다. 'git' 명령이 실패할 수 있습니다.")
 				}
-			} else {
-				fmt.Println("❌ Git 자동 설치 또는 PATH 추가에 실패했습니다. 수동으로 설치 및 PATH 설정 후 다시 실행해주세요.")
-				waitForExit()
 			}
 		} else {
-			fmt.Println("Git 설치가 필요합니다. 프로그램을 종료합니다.")
-			waitForExit()
+			fmt.Println("   (사용할 Git 경로:", gitExecutablePath, ")")
+		}
+		if isAdmin && runtime.GOOS == "windows" {
+			fmt.Println("   시스템 PATH가 업데이트되었을 수 있습니다. 다음 실행부터는 자동으로 인식됩니다.")
+		}
+		if _, err := checkMinimumVersion(gitRequirement, gitExecutablePath); errors.Is(err, errVersionTooOld) {
+			fmt.Println("❌", err, "- 수동으로 업그레이드한 뒤 다시 실행해주세요:", gitDownloadPage)
+			return fmt.Errorf("%w: %v", errDependencyMissing, err)
 		}
 	}
 
+	if nodeRuntimeFor(installDir) == nodeRuntimePortable {
+		if err := applyNodeRuntime(installDir); err != nil {
+			fmt.Println("❌", err)
+			return fmt.Errorf("%w: %v", errDependencyMissing, err)
+		}
+		fmt.Println("✅ 인스턴스 전용 Node.js 사용 (node:", nodeExecutablePath, ", npm:", npmExecutablePath, ")")
+		if err := checkNodeVersions(); err != nil {
+			fmt.Println("⚠️", err, "- '인스턴스 목록 / 선택' 메뉴에서 다시 내려받거나 업데이트 시 업그레이드할 수 있습니다.")
+		}
+		fmt.Println()
+		return nil
+	}
+
 	nodeFoundInPath := false
 	if p, err := exec.LookPath("node"); err == nil {
 		nodeExecutablePath = p
@@ -252,62 +306,91 @@ func checkDependencies() {
 		npmFoundInPath = true
 	}
 
-	if nodeFoundInPath && npmFoundInPath {
-		fmt.Println("✅ Node.js 및 npm 확인 완료 (node:", nodeExecutablePath, ", npm:", npmExecutablePath, ")")
-	} else {
+	nodeReady := nodeFoundInPath && npmFoundInPath
+	if nodeReady {
+		if err := checkNodeVersions(); err != nil {
+			fmt.Println("❌", err)
+			nodeReady = false
+		} else {
+			fmt.Println("✅ Node.js 및 npm 확인 완료 (node:", nodeExecutablePath, ", npm:", npmExecutablePath, ")")
+		}
+	}
+	if !nodeReady {
 		if !nodeFoundInPath {
 			fmt.Println("❌ Node.js를 찾을 수 없습니다.")
 		}
 		if !npmFoundInPath {
 			fmt.Println("❌ npm을 찾을 수 없습니다.")
 		}
-		fmt.Print("Node.js (LTS) 자동 설치를 시도하시겠습니까? (y/n): ")
-		if strings.ToLower(strings.TrimSpace(getUserChoice())) == "y" {
-			installed, foundNodePath, foundNpmPath := installNodeJS()
-			if installed {
-				nodeExecutablePath = foundNodePath
-				npmExecutablePath = foundNpmPath
-				fmt.Println("✅ Node.js 설치 또는 PATH 추가 시도 완료.")
-				if nodeExecutablePath == "node" {
-					fmt.Println("   Node.js PATH가 즉시 적용되지 않았을 수 있습니다. 이 세션에서는 기본 경로로 시도합니다.")
-					if runtime.GOOS == "windows" {
-						if _, errStat := os.Stat(defaultNodeExePathWindows); errStat == nil {
-							nodeExecutablePath = defaultNodeExePathWindows
-							fmt.Println("   (Windows 기본 Node 경로 사용:", nodeExecutablePath, ")")
-						} else {
-							fmt.Println("   ⚠️ Windows 기본 Node 경로도 찾을 수 없습니다. 'node' 명령이 실패할 수 있습니다.")
-						}
-					}
+		if !confirm("Node.js (LTS) 자동 설치(업그레이드)를 시도하시겠습니까? (y/n): ") {
+			fmt.Println("Node.js 설치가 필요합니다. 프로그램을 종료합니다.")
+			return fmt.Errorf("%w: Node.js", errDependencyMissing)
+		}
+		installed, foundNodePath, foundNpmPath := installNodeJS()
+		if !installed {
+			fmt.Println("❌ Node.js 자동 설치 또는 PATH 추가에 실패했습니다. 수동으로 설치 및 PATH 설정 후 다시 실행해주세요.")
+			return fmt.Errorf("%w: Node.js 설치 실패", errDependencyMissing)
+		}
+		nodeExecutablePath = foundNodePath
+		npmExecutablePath = foundNpmPath
+		fmt.Println("✅ Node.js 설치 또는 PATH 추가 시도 완료.")
+		if nodeExecutablePath == "node" {
+			fmt.Println("   Node.js PATH가 즉시 적용되지 않았을 수 있습니다. 이 세션에서는 기본 경로로 시도합니다.")
+			if runtime.GOOS == "windows" {
+				if _, errStat := os.Stat(defaultNodeExePathWindows); errStat == nil {
+					nodeExecutablePath = defaultNodeExePathWindows
+					fmt.Println("   (Windows 기본 Node 경로 사용:", nodeExecutablePath, ")")
 				} else {
-					fmt.Println("   (사용할 Node 경로:", nodeExecutablePath, ")")
+					fmt.Println("   ⚠️ Windows 기본 Node 경로도 찾을 수 없습니다. 'node' 명령이 실패할 수 있습니다.")
 				}
-				if npmExecutablePath == "npm" {
-					fmt.Println("   npm PATH가 즉시 적용되지 않았을 수 있습니다. 이 세션에서는 기본 경로로 시도합니다.")
-					if runtime.GOOS == "windows" {
-						if _, errStat := os.Stat(defaultNpmCmdPathWindows); errStat == nil {
-							npmExecutablePath = defaultNpmCmdPathWindows
-							fmt.Println("   (Windows 기본 npm 경로 사용:", npmExecutablePath, ")")
-						} else {
-							fmt.Println("   ⚠️ Windows 기본 npm 경로도 찾을 수 없습니다. 'npm' 명령이 실패할 수 있습니다.")
-						}
-					}
+			}
+		} else {
+			fmt.Println("   (사용할 Node 경로:", nodeExecutablePath, ")")
+		}
+		if npmExecutablePath == "npm" {
+			fmt.Println("   npm PATH가 즉시 적용되지 않았을 수 있습니다. 이 세션에서는 기본 경로로 시도합니다.")
+			if runtime.GOOS == "windows" {
+				if _, errStat := os.Stat(defaultNpmCmdPathWindows); errStat == nil {
+					npmExecutablePath = defaultNpmCmdPathWindows
+					fmt.Println("   (Windows 기본 npm 경로 사용:", npmExecutablePath, ")")
 				} else {
-					fmt.Println("   (사용할 npm 경로:", npmExecutablePath, ")")
-				}
-
-				if isAdmin && runtime.GOOS == "windows" {
-					fmt.Println("   시스템 PATH가 업데이트되었을 수 있습니다. 다음 실행부터는 자동으로 인식됩니다.")
+					fmt.Println("   ⚠️ Windows 기본 npm 경로도 찾을 수 없습니다. 'npm' 명령이 실패할 수 있습니다.")
 				}
-			} else {
-				fmt.Println("❌ Node.js 자동 설치 또는 PATH 추가에 실패했습니다. 수동으로 설치 및 PATH 설정 후 다시 실행해주세요.")
-				waitForExit()
 			}
 		} else {
-			fmt.Println("Node.js 설치가 필요합니다. 프로그램을 종료합니다.")
-			waitForExit()
+			fmt.Println("   (사용할 npm 경로:", npmExecutablePath, ")")
+		}
+
+		if isAdmin && runtime.GOOS == "windows" {
+			fmt.Println("   시스템 PATH가 업데이트되었을 수 있습니다. 다음 실행부터는 자동으로 인식됩니다.")
+		}
+		if err := checkNodeVersions(); errors.Is(err, errVersionTooOld) {
+			fmt.Println("❌", err, "- 수동으로 업그레이드한 뒤 다시 실행해주세요:", nodeJSDownloadPage)
+			return fmt.Errorf("%w: %v", errDependencyMissing, err)
 		}
 	}
 	fmt.Println()
+	return nil
+}
+
+// checkNodeVersions는 Node.js와 npm이 최소 요구 버전 이상인지 확인하고 확인한 버전을 출력합니다.
+// 버전을 확인할 수 없는 경우에는 경고만 출력합니다.
+func checkNodeVersions() error {
+	for _, c := range []struct {
+		req        versionRequirement
+		executable string
+	}{{nodeRequirement, nodeExecutablePath}, {npmRequirement, npmExecutablePath}} {
+		version, err := checkMinimumVersion(c.req, c.executable)
+		if errors.Is(err, errVersionTooOld) {
+			return err
+		}
+		if err != nil {
+			fmt.Printf("⚠️ %s 버전을 확인하지 못했습니다: %v\n", c.req.name, err)
+			continue
+		}
+		fmt.Printf("   %s 버전: %s\n", c.req.name, formatVersion(version))
+	}
+	return nil
 }
 
 func isCommandAvailable(cmdKey string, args ...string) bool {
@@ -379,8 +462,33 @@ func getNodeJsDir() string {
 }
 
 func installOrUpdateSillyTavern() {
-	baseDir := defaultBaseDir
 	fmt.Println("\n[ 실리태번 설치/업데이트 ]")
+	if err := installOrUpdate(installDir, installBranchForCurrent()); err != nil {
+		if errors.Is(err, errCloneFailed) {
+			waitForExit()
+		}
+		fmt.Println("\n❌ 설치/업데이트를 완료하지 못했습니다:", err)
+		return
+	}
+	fmt.Println("\n✅ 설치/업데이트 완료!")
+}
+
+// installOrUpdate는 baseDir에 SillyTavern이 없으면 installBranch로 새로 설치하고,
+// 이미 있으면 현재 브랜치 기준으로 업데이트한 뒤 npm 패키지를 설치합니다.
+func installOrUpdate(baseDir, installBranch string) error {
+	if kind, ref := pinFor(baseDir); ref != "" {
+		fmt.Printf("📌 %s %s에 고정되어 있어 해당 버전으로 설치/업데이트합니다. (해제: '버전 고정' 메뉴 또는 pin clear)\n", pinKindLabel(kind), ref)
+		if err := checkoutPinnedRef(baseDir, kind, ref); err != nil {
+			return err
+		}
+		if err := installSillyTavernDependencies(baseDir); err != nil {
+			return err
+		}
+		checkConfigDefaults(baseDir)
+		recordInstanceUpdate(baseDir)
+		return nil
+	}
+
 	gitDir := filepath.Join(baseDir, ".git")
 	_, errSt := os.Stat(baseDir)
 	_, errGit := os.Stat(gitDir)
@@ -400,8 +508,11 @@ func installOrUpdateSillyTavern() {
 	}
 
 	if !stDirExists {
-		fmt.Printf("%s 디렉토리에 실리태번을 새로 설치합니다 (기본 브랜치: %s)...\n", baseDir, defaultBranch)
-		cloneRepo(baseDir, defaultBranch)
+		fmt.Printf("%s 디렉토리에 실리태번을 새로 설치합니다 (브랜치: %s)...\n", baseDir, installBranch)
+		checkRemoteNodeEngine(installBranch)
+		if err := cloneRepo(baseDir, installBranch); err != nil {
+			return err
+		}
 	} else {
 		if currentBranch == "" {
 			fmt.Printf("⚠️ 현재 브랜치를 알 수 없어 기본 브랜치(%s) 기준으로 업데이트를 시도합니다.\n", defaultBranch)
@@ -409,67 +520,67 @@ func installOrUpdateSillyTavern() {
 			currentBranch = defaultBranch
 		}
 		fmt.Printf("%s 디렉토리의 실리태번을 업데이트합니다 (브랜치: %s)...\n", baseDir, currentBranch)
-		updateRepo(baseDir, currentBranch)
+		snapshotBeforeChange(baseDir, "업데이트 이전")
+		if err := updateRepo(baseDir, currentBranch); err != nil {
+			return err
+		}
 	}
-	installSillyTavernDependencies(baseDir)
-	fmt.Println("\n✅ 설치/업데이트 완료!")
+	if err := installSillyTavernDependencies(baseDir); err != nil {
+		return err
+	}
+	checkConfigDefaults(baseDir)
+	recordInstanceUpdate(baseDir)
+	return nil
 }
 
-func cloneRepo(baseDir, branch string) {
+func cloneRepo(baseDir, branch string) error {
 	fmt.Printf("실리태번 저장소를 '%s' 브랜치로 클론 중 (using: %s)...\n", branch, gitExecutablePath)
 	cmd := exec.Command(gitExecutablePath, "clone", "-b", branch, repoURL, baseDir)
 	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	var errBuffer bytes.Buffer
+	cmd.Stderr = io.MultiWriter(os.Stderr, &errBuffer)
 	if err := cmd.Run(); err != nil {
 		fmt.Println("\n❌ 저장소 클론에 실패했습니다:", err)
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			errMsg := string(exitErr.Stderr)
-			if strings.Contains(errMsg, "detected dubious ownership") {
-				fmt.Println("\n‼️ Git 소유권 문제 감지됨:")
-				fmt.Println("   이 문제를 해결하려면, Git Bash 또는 명령 프롬프트에서 다음 명령을 실행하세요:")
-				re := regexp.MustCompile(`safe\.directory ([^\s]+)`)
-				matches := re.FindStringSubmatch(errMsg)
-				if len(matches) > 1 {
-					fmt.Printf("   git config --global --add safe.directory %s\n", strings.TrimSpace(matches[1]))
-				} else {
-					absPath, _ := filepath.Abs(baseDir)
-					fmt.Printf("   git config --global --add safe.directory \"%s\"\n", absPath)
-				}
-				fmt.Println("\n   위 명령어 실행 후 이 프로그램을 다시 시작해주세요.")
+		errMsg := errBuffer.String()
+		if strings.Contains(errMsg, "detected dubious ownership") {
+			fmt.Println("\n‼️ Git 소유권 문제 감지됨:")
+			fmt.Println("   이 문제를 해결하려면, Git Bash 또는 명령 프롬프트에서 다음 명령을 실행하세요:")
+			re := regexp.MustCompile(`safe\.directory ([^\s]+)`)
+			matches := re.FindStringSubmatch(errMsg)
+			if len(matches) > 1 {
+				fmt.Printf("   git config --global --add safe.directory %s\n", strings.TrimSpace(matches[1]))
+			} else {
+				absPath, _ := filepath.Abs(baseDir)
+				fmt.Printf("   git config --global --add safe.directory \"%s\"\n", absPath)
 			}
+			fmt.Println("\n   위 명령어 실행 후 이 프로그램을 다시 시작해주세요.")
 		}
-		waitForExit()
+		return fmt.Errorf("%w: %v", errCloneFailed, err)
 	}
+	return nil
 }
 
-func updateRepo(baseDir, branchToUpdate string) {
+func updateRepo(baseDir, branchToUpdate string) error {
 	fmt.Println("저장소 업데이트 중...")
-	originalWd, _ := os.Getwd()
-	if err := os.Chdir(baseDir); err != nil {
-		fmt.Printf("❌ 디렉토리 변경 실패 (%s): %v\n", baseDir, err)
-		return
+	if branchToUpdate == "" {
+		fmt.Println("\n❌ 업데이트할 브랜치 정보가 없습니다.")
+		return fmt.Errorf("업데이트할 브랜치 정보가 없습니다")
+	}
+	if err := backupBeforeChange(baseDir, backupReasonUpdate); err != nil {
+		return err
 	}
-	defer os.Chdir(originalWd)
-
-	fmt.Println("로컬 변경사항 임시 저장 (git stash push -u)...")
-	stashCmd := exec.Command(gitExecutablePath, "stash", "push", "-u", "-m", "AutoStash_BeforeUpdate_"+time.Now().Format("20060102150405"))
-	stashOutput, stashErr := stashCmd.CombinedOutput()
 
-	if stashErr != nil {
-		fmt.Printf("⚠️ 로컬 변경사항 임시 저장(stash) 중 오류 발생: %v\n", stashErr)
-		fmt.Printf("   Git Stash 출력:\n%s\n", string(stashOutput))
-		if strings.Contains(string(stashOutput), "detected dubious ownership") {
-			fmt.Println("\n‼️ Git 소유권 문제 감지됨. `git config --global --add safe.directory ...` 명령을 실행하고 재시도해주세요.")
-			return
-		}
-	} else if strings.Contains(string(stashOutput), "No local changes to save") || strings.Contains(string(stashOutput), "No stash entries found") {
-		fmt.Println("ℹ️ 임시 저장할 로컬 변경사항이 없습니다.")
-	} else {
-		fmt.Println("로컬 변경사항 임시 저장 완료.")
+	preUpdate, err := gitHeadCommit(baseDir)
+	if err != nil {
+		return err
+	}
+	stash, err := stashLocalChanges(baseDir, "AutoStash_BeforeUpdate")
+	if err != nil {
+		return err
 	}
 
 	fmt.Println("원격 저장소 정보 가져오기 (git fetch origin)...")
-	fetchCmd := exec.Command(gitExecutablePath, "fetch", "origin")
+	fetchCmd := exec.Command(gitExecutablePath, "-C", baseDir, "fetch", "origin")
 	var fetchErrBuffer bytes.Buffer
 	fetchCmd.Stderr = &fetchErrBuffer
 	fetchCmd.Stdout = os.Stdout
@@ -479,17 +590,12 @@ func updateRepo(baseDir, branchToUpdate string) {
 		fmt.Printf("   Git Fetch 오류:\n%s\n", errMsg)
 		if strings.Contains(errMsg, "detected dubious ownership") {
 			fmt.Println("\n‼️ Git 소유권 문제 감지됨. `git config --global --add safe.directory ...` 명령을 실행하고 재시도해주세요.")
-			return
+			return fmt.Errorf("Git 소유권 문제로 fetch 실패: %w", err)
 		}
 	}
 
-	if branchToUpdate == "" {
-		fmt.Println("\n❌ 업데이트할 브랜치 정보가 없습니다.")
-		return
-	}
-
 	fmt.Printf("브랜치 (%s) 를 원격 저장소(origin/%s) 기준으로 업데이트 (git pull origin %s)...\n", branchToUpdate, branchToUpdate, branchToUpdate)
-	pullCmd := exec.Command(gitExecutablePath, "pull", "origin", branchToUpdate)
+	pullCmd := exec.Command(gitExecutablePath, "-C", baseDir, "pull", "--no-rebase", "origin", branchToUpdate)
 	var pullErrBuffer bytes.Buffer
 	pullCmd.Stderr = &pullErrBuffer
 	pullCmd.Stdout = os.Stdout
@@ -497,97 +603,39 @@ func updateRepo(baseDir, branchToUpdate string) {
 		fmt.Println("\n❌ 저장소 업데이트(pull)에 실패했습니다:", err)
 		errMsg := pullErrBuffer.String()
 		fmt.Printf("   Git Pull 오류:\n%s\n", errMsg)
+		if conflicts := conflictedFiles(baseDir); len(conflicts) > 0 {
+			return resolveMergeConflict(baseDir, branchToUpdate, preUpdate, conflicts, stash)
+		}
 		if strings.Contains(errMsg, "detected dubious ownership") {
 			fmt.Println("\n‼️ Git 소유권 문제 감지됨. `git config --global --add safe.directory ...` 명령을 실행하고 재시도해주세요.")
-			return
-		}
-		fmt.Println("ℹ️  만약 로컬 변경사항과 충돌이 발생했다면, 수동으로 해결해야 할 수 있습니다.")
-	} else {
-		fmt.Println("✅ 저장소 업데이트 완료.")
-		tryApplyStash(".")
-	}
-}
-
-func tryApplyStash(repoPath string) {
-	checkStashCmd := exec.Command(gitExecutablePath, "-C", repoPath, "stash", "list")
-	stashListOutput, err := checkStashCmd.Output()
-	if err != nil {
-		fmt.Printf("⚠️ stash 목록 확인 실패: %v\n", err)
-		if exitErr, ok := err.(*exec.ExitError); ok && strings.Contains(string(exitErr.Stderr), "detected dubious ownership") {
-			fmt.Println("‼️ Git 소유권 문제로 stash 목록 확인 실패. `git config --global --add safe.directory ...` 실행 필요.")
 		}
-		return
-	}
-	if strings.TrimSpace(string(stashListOutput)) == "" {
-		fmt.Println("ℹ️ 적용할 로컬 변경사항(stash)이 없습니다.")
-		return
-	}
-
-	fmt.Println("저장된 로컬 변경사항(stash) 자동 복원 시도 (git stash pop)...")
-	cmd := exec.Command(gitExecutablePath, "-C", repoPath, "stash", "pop")
-	var outBuffer, errBuffer bytes.Buffer
-	cmd.Stdout = &outBuffer
-	cmd.Stderr = &errBuffer
-	err = cmd.Run()
-	outputCombined := outBuffer.String() + errBuffer.String()
-
-	if err != nil {
-		fmt.Println("\n❌ 자동 복원 실패: 로컬 변경사항을 다시 적용하는 중 문제가 발생했습니다.")
-		fmt.Println("Git 메시지:")
-		fmt.Println("---------------------------------------------------------")
-		fmt.Println(strings.TrimSpace(outputCombined))
-		fmt.Println("---------------------------------------------------------")
-		if strings.Contains(outputCombined, "detected dubious ownership") {
-			fmt.Println("\n‼️ Git 소유권 문제 감지됨. `git config --global --add safe.directory ...` 명령을 실행하고 재시도해주세요.")
-		} else {
-			fmt.Println("ℹ️ 충돌(Conflict)이 발생했을 수 있습니다. 수동으로 해결해야 합니다.")
+		// 업데이트하지 못했으므로 임시 저장한 변경사항을 원래 자리에 되돌려 놓습니다.
+		if restoreErr := stash.restore(); restoreErr != nil {
+			fmt.Println("⚠️", restoreErr)
 		}
-	} else {
-		fmt.Println("\n✅ 저장된 로컬 변경사항(stash)이 성공적으로 복원/적용되었거나, 적용할 내용이 없었습니다.")
+		return fmt.Errorf("git pull 실패: %w", err)
 	}
+	fmt.Println("✅ 저장소 업데이트 완료.")
+	return stash.restore()
 }
 
-func installSillyTavernDependencies(baseDir string) {
-	fmt.Printf("\nSillyTavern에 필요한 패키지 설치 중 (npm install, using: %s)...\n", npmExecutablePath)
-	originalWd, _ := os.Getwd()
-	if err := os.Chdir(baseDir); err != nil {
-		fmt.Printf("❌ 디렉토리 변경 실패 (%s): %v\n", baseDir, err)
-		return
+func installSillyTavernDependencies(baseDir string) error {
+	if err := applyNodeRuntime(baseDir); err != nil {
+		return err
 	}
-	defer os.Chdir(originalWd)
-
+	checkLocalNodeEngine(baseDir)
+	fmt.Printf("\nSillyTavern에 필요한 패키지 설치 중 (npm install, using: %s)...\n", npmExecutablePath)
 	npmCmd := exec.Command(npmExecutablePath, "install")
+	npmCmd.Dir = baseDir
 
-	if runtime.GOOS == "windows" { // Windows에서 특히 PATH 문제가 발생하므로 명시적 처리
-		nodeDir := getNodeJsDir() // 위에서 추가한 헬퍼 함수 사용
-
-		if nodeDir != "" {
-			fmt.Printf("ℹ️ Node.js 디렉토리 확인: %s\n", nodeDir)
-			currentEnv := os.Environ() // 현재 환경 변수 가져오기
-			newEnv := make([]string, 0, len(currentEnv)+1)
-			pathVarSet := false
-
-			for _, envVar := range currentEnv {
-				if strings.HasPrefix(strings.ToUpper(envVar), "PATH=") {
-					originalPath := envVar[len("PATH="):]
-					// 새 Node.js 경로를 기존 PATH의 맨 앞에 추가
-					newPathValue := nodeDir + string(os.PathListSeparator) + originalPath
-					newEnv = append(newEnv, "PATH="+newPathValue)
-					pathVarSet = true
-					fmt.Printf("   npm 실행을 위해 PATH에 '%s'를 우선 적용합니다.\n", nodeDir)
-				} else {
-					newEnv = append(newEnv, envVar)
-				}
-			}
-
-			if !pathVarSet { // PATH 변수가 아예 없는 경우 (매우 드묾)
-				newEnv = append(newEnv, "PATH="+nodeDir)
-				fmt.Printf("   npm 실행을 위해 PATH에 '%s'를 설정합니다.\n", nodeDir)
-			}
-			npmCmd.Env = newEnv // 수정된 환경 변수를 Command 객체에 설정
-		} else {
-			fmt.Println("⚠️ Node.js 디렉토리를 찾지 못해 npm 실행 시 PATH를 명시적으로 설정하지 못했습니다. 기존 PATH에 의존합니다.")
-		}
+	// npm은 내부에서 'node'를 PATH로 찾으므로, 사용할 Node.js 디렉토리를 PATH 맨 앞에 둡니다.
+	// (Windows에서 새로 설치해 PATH가 아직 반영되지 않은 경우와 인스턴스 전용 Node.js를 쓰는 경우)
+	if nodeDir := getNodeJsDir(); nodeDir != "" {
+		fmt.Printf("ℹ️ Node.js 디렉토리 확인: %s\n", nodeDir)
+		fmt.Printf("   npm 실행을 위해 PATH에 '%s'를 우선 적용합니다.\n", nodeDir)
+		npmCmd.Env = environWithPathPrefix(nodeDir)
+	} else if runtime.GOOS == "windows" {
+		fmt.Println("⚠️ Node.js 디렉토리를 찾지 못해 npm 실행 시 PATH를 명시적으로 설정하지 못했습니다. 기존 PATH에 의존합니다.")
 	}
 	npmCmd.Stdout = os.Stdout
 	npmCmd.Stderr = os.Stderr
@@ -595,16 +643,37 @@ func installSillyTavernDependencies(baseDir string) {
 	if err := npmCmd.Run(); err != nil {
 
 		fmt.Println("\n❌ SillyTavern 패키지 설치(npm install)에 실패했습니다:", err)
-		fmt.Println("   자세한 오류는 위의 npm 출력 및 다음 경로의 로그 파일을 확인해 보세요:")
-		userHomeDir, homeErr := os.UserHomeDir()
-		if homeErr == nil {
-			fmt.Printf("   (예상 로그 경로: %s)\\AppData\\Local\\npm-cache\\_logs\\\n", userHomeDir)
+		fmt.Println("   자세한 오류는 위의 npm 출력 및 npm 로그 파일을 확인해 보세요:")
+		if logDir := npmLogDir(npmCmd.Env); logDir == "" {
+			fmt.Println("   (npm 로그는 npm 캐시 폴더의 _logs 폴더에 생성됩니다. 'npm config get cache'로 위치를 확인할 수 있습니다.)")
+		} else if latest := latestFile(logDir); latest != "" {
+			fmt.Printf("   최근 로그 파일: %s\n", latest)
 		} else {
-			fmt.Println("   (npm 로그는 보통 사용자 AppData\\Local\\npm-cache\\_logs 폴더에 생성됩니다.)")
+			fmt.Printf("   로그 폴더: %s\n", logDir)
 		}
-	} else {
-		fmt.Println("✅ SillyTavern 패키지 설치 완료.")
+		return fmt.Errorf("npm install 실패: %w", err)
 	}
+	fmt.Println("✅ SillyTavern 패키지 설치 완료.")
+	return nil
+}
+
+// environWithPathPrefix는 현재 환경 변수에서 PATH 맨 앞에 dir을 추가한 목록을 반환합니다.
+func environWithPathPrefix(dir string) []string {
+	currentEnv := os.Environ()
+	newEnv := make([]string, 0, len(currentEnv)+1)
+	pathVarSet := false
+	for _, envVar := range currentEnv {
+		if strings.HasPrefix(strings.ToUpper(envVar), "PATH=") {
+			newEnv = append(newEnv, envVar[:len("PATH=")]+dir+string(os.PathListSeparator)+envVar[len("PATH="):])
+			pathVarSet = true
+		} else {
+			newEnv = append(newEnv, envVar)
+		}
+	}
+	if !pathVarSet { // PATH 변수가 아예 없는 경우 (매우 드묾)
+		newEnv = append(newEnv, "PATH="+dir)
+	}
+	return newEnv
 }
 
 func getCurrentGitBranch(repoPath string) (string, error) {
@@ -630,7 +699,7 @@ func getCurrentGitBranch(repoPath string) (string, error) {
 }
 
 func switchBranch() {
-	baseDir := defaultBaseDir
+	baseDir := installDir
 	if _, err := os.Stat(filepath.Join(baseDir, ".git")); os.IsNotExist(err) {
 		fmt.Println("\n❌ 실리태번이 설치되어 있지 않거나 Git 저장소가 아닙니다. 먼저 설치해주세요.")
 		return
@@ -653,51 +722,74 @@ func switchBranch() {
 		return
 	}
 
-	originalWd, _ := os.Getwd()
-	if err := os.Chdir(baseDir); err != nil {
-		fmt.Printf("❌ 디렉토리 변경 실패 (%s): %v\n", baseDir, err)
-		return
+	if err := switchBranchTo(baseDir, targetBranch); err != nil {
+		fmt.Println("\n❌ 브랜치 변경을 완료하지 못했습니다:", err)
+	}
+}
+
+// switchBranchTo는 baseDir 저장소를 targetBranch로 전환하고 최신화한 뒤 npm 패키지를 설치합니다.
+// 이미 targetBranch를 사용 중이면 업데이트만 수행합니다.
+func switchBranchTo(baseDir, targetBranch string) error {
+	if _, err := os.Stat(filepath.Join(baseDir, ".git")); os.IsNotExist(err) {
+		fmt.Println("\n❌ 실리태번이 설치되어 있지 않거나 Git 저장소가 아닙니다. 먼저 설치해주세요.")
+		return errNotInstalled
 	}
-	defer os.Chdir(originalWd)
 
-	currentBranch, err := getCurrentGitBranch(".")
+	kind, ref := pinFor(baseDir)
+	if ref != "" {
+		fmt.Printf("📌 현재 %s %s에 고정되어 있습니다.\n", pinKindLabel(kind), ref)
+		if !confirm("   고정을 해제하고 브랜치를 전환하시겠습니까? (y/n): ") {
+			return fmt.Errorf("버전 고정 상태에서는 브랜치를 전환하지 않습니다")
+		}
+	}
+	snapshotBeforeChange(baseDir, "브랜치 변경 이전")
+	return checkoutBranch(baseDir, targetBranch, ref != "")
+}
+
+// checkoutBranch는 switchBranchTo의 실제 전환 작업입니다. unpin이면 브랜치 전환(최신화)이 성공한 뒤에만
+// 버전 고정을 해제하므로, 도중에 실패하면 고정 정보가 그대로 남습니다.
+func checkoutBranch(baseDir, targetBranch string, unpin bool) error {
+	clearPinRecord := func() {
+		if unpin {
+			touchInstance(baseDir, false, func(inst *instance) {
+				inst.PinKind, inst.PinRef = "", ""
+			})
+		}
+	}
+
+	currentBranch, err := getCurrentGitBranch(baseDir)
 	if err != nil {
 		fmt.Printf("\n⚠️ 현재 브랜치를 확인하는데 실패했습니다: %v\n", err)
 		if strings.Contains(err.Error(), "Git 소유권 문제") {
-			fmt.Println("   계속 진행하시겠습니까? (y/n): ")
-			if strings.ToLower(strings.TrimSpace(getUserChoice())) != "y" {
-				return
+			if !confirm("   계속 진행하시겠습니까? (y/n): ") {
+				return err
 			}
 		}
 	}
 
 	if currentBranch == targetBranch {
 		fmt.Printf("\n이미 %s 브랜치를 사용 중입니다. 최신 버전으로 업데이트를 시도합니다...\n", targetBranch)
-		updateRepo(".", targetBranch)
-		installSillyTavernDependencies(".")
-		return
+		if err := updateRepo(baseDir, targetBranch); err != nil {
+			return err
+		}
+		clearPinRecord()
+		if err := installSillyTavernDependencies(baseDir); err != nil {
+			return err
+		}
+		checkConfigDefaults(baseDir)
+		recordInstanceUpdate(baseDir)
+		return nil
 	}
 
 	fmt.Printf("\n%s 브랜치로 전환 중...\n", targetBranch)
-	fmt.Println("브랜치 전환 전 로컬 변경사항 임시 저장 (git stash push -u)...")
-	stashCmd := exec.Command(gitExecutablePath, "stash", "push", "-u", "-m", "AutoStash_BeforeBranchSwitch_"+time.Now().Format("20060102150405"))
-	stashOutput, stashErr := stashCmd.CombinedOutput()
-	stashedSomething := false
-	if stashErr != nil {
-		fmt.Printf("⚠️ 로컬 변경사항 임시 저장(stash) 중 오류 발생: %v\n", stashErr)
-		fmt.Printf("   Git Stash 출력:\n%s\n", string(stashOutput))
-		if strings.Contains(string(stashOutput), "detected dubious ownership") {
-			fmt.Println("\n‼️ Git 소유권 문제 감지됨. 이전 안내를 따라 명령 실행 후 재시도해주세요.")
-		}
-	} else if !strings.Contains(string(stashOutput), "No local changes to save") && !strings.Contains(string(stashOutput), "No stash entries found") {
-		fmt.Println("로컬 변경사항 임시 저장 완료.")
-		stashedSomething = true
-	} else {
-		fmt.Println("ℹ️ 임시 저장할 로컬 변경사항이 없었습니다.")
+	fmt.Print("브랜치 전환 전 ")
+	stash, err := stashLocalChanges(baseDir, "AutoStash_BeforeBranchSwitch")
+	if err != nil {
+		return err
 	}
 
 	fmt.Printf("원격 저장소에서 %s 브랜치 정보 가져오기 (git fetch origin %s)...\n", targetBranch, targetBranch)
-	fetchBranchCmd := exec.Command(gitExecutablePath, "fetch", "origin", targetBranch+":"+targetBranch)
+	fetchBranchCmd := exec.Command(gitExecutablePath, "-C", baseDir, "fetch", "origin", targetBranch+":"+targetBranch)
 	var fetchErrBuffer bytes.Buffer
 	fetchBranchCmd.Stderr = &fetchErrBuffer
 	if err := fetchBranchCmd.Run(); err != nil {
@@ -710,7 +802,7 @@ func switchBranch() {
 	}
 
 	fmt.Printf("브랜치 전환 (git checkout %s)...\n", targetBranch)
-	checkoutCmd := exec.Command(gitExecutablePath, "checkout", targetBranch)
+	checkoutCmd := exec.Command(gitExecutablePath, "-C", baseDir, "checkout", targetBranch)
 	var checkoutStdErr bytes.Buffer
 	checkoutCmd.Stderr = &checkoutStdErr
 	checkoutCmd.Stdout = os.Stdout
@@ -722,25 +814,51 @@ func switchBranch() {
 		if strings.Contains(errMsg, "detected dubious ownership") {
 			fmt.Println("\n‼️ Git 소유권 문제 감지됨. 명령 실행 후 재시도해주세요.")
 		}
-		if stashedSomething {
-			fmt.Println("ℹ️  'git stash pop'으로 임시 저장된 변경사항을 현재 브랜치에 복원 시도해볼 수 있습니다.")
+		if restoreErr := stash.restore(); restoreErr != nil {
+			fmt.Println("⚠️", restoreErr)
 		}
-	} else {
-		fmt.Printf("\n✅ %s 브랜치로 전환 완료!\n", targetBranch)
-		fmt.Println("전환된 브랜치 최신화 (git pull origin)...")
-		updateRepo(".", targetBranch)
-		if stashedSomething {
-			fmt.Println("\n이전 브랜치에서 가져온 로컬 변경사항 자동 복원 시도...")
-			tryApplyStash(".")
+		return fmt.Errorf("git checkout %s 실패: %w", targetBranch, err)
+	}
+
+	fmt.Printf("\n✅ %s 브랜치로 전환 완료!\n", targetBranch)
+	fmt.Println("전환된 브랜치 최신화 (git pull origin)...")
+	if err := updateRepo(baseDir, targetBranch); err != nil {
+		if restoreErr := stash.restore(); restoreErr != nil {
+			fmt.Println("⚠️", restoreErr)
+		}
+		return err
+	}
+	clearPinRecord()
+	if stash != nil {
+		fmt.Println("\n이전 브랜치에서 가져온 소스 변경사항 다시 적용...")
+		if err := stash.restore(); err != nil {
+			return err
 		}
-		installSillyTavernDependencies(".")
 	}
+	if err := installSillyTavernDependencies(baseDir); err != nil {
+		return err
+	}
+	checkConfigDefaults(baseDir)
+	recordInstanceUpdate(baseDir)
+	return nil
 }
 
 // --- `installGit` 함수 (이전 답변의 수정된 버전) ---
 func installGit() (bool, string) {
 	foundGitPath := "git"
-	installed := installProgram("Git", "Git.Git", "git.install", gitForWindowsURL, "git_installer.exe", "/VERYSILENT /NORESTART /NOCANCEL /SP- /CLOSEAPPLICATIONS /RESTARTAPPLICATIONS /MERGETASKS=!desktopicon", nil)
+	var installed bool
+	if runtime.GOOS == "windows" {
+		if !isAdmin && !isCommandAvailable("winget", "--version") && !isCommandAvailable("choco", "--version") {
+			fmt.Println("ℹ️ 관리자 권한이 없고 Winget/Chocolatey도 없어, 설치 없이 사용할 수 있는 portable Git(MinGit)을 내려받습니다.")
+			return installGitPortableFallback()
+		}
+		installed = installProgram("Git", "Git.Git", "git.install", gitInstallerURL(), "git_installer.exe", "/VERYSILENT /NORESTART /NOCANCEL /SP- /CLOSEAPPLICATIONS /RESTARTAPPLICATIONS /MERGETASKS=!desktopicon", nil)
+		if !installed && !isAdmin && confirm("설치 없이 사용할 수 있는 portable Git(MinGit)을 내려받아 사용하시겠습니까? (y/n): ") {
+			return installGitPortableFallback()
+		}
+	} else {
+		installed = installGitUnix()
+	}
 
 	if p, err := exec.LookPath("git"); err == nil {
 		foundGitPath = p
@@ -780,7 +898,12 @@ func installGit() (bool, string) {
 func installNodeJS() (bool, string, string) {
 	foundNodePath := "node"
 	foundNpmPath := "npm"
-	installed := installProgram("Node.js LTS", "OpenJS.NodeJS.LTS", "nodejs-lts", nodeJSWindowsURL, "nodejs_lts_installer.msi", "", nil)
+	var installed bool
+	if runtime.GOOS == "windows" {
+		installed = installProgram("Node.js LTS", "OpenJS.NodeJS.LTS", "nodejs-lts", nodeInstallerURL(), "nodejs_lts_installer.msi", "", nil)
+	} else {
+		installed = installNodeUnix()
+	}
 
 	nodePathOk := false
 	npmPathOk := false
@@ -843,57 +966,15 @@ func installNodeJS() (bool, string, string) {
 	return false, foundNodePath, foundNpmPath
 }
 
-func downloadFile(url, targetFilepath string) error {
-	fmt.Printf("다운로드 시작: %s -> %s\n", url, targetFilepath)
-	dir := filepath.Dir(targetFilepath)
-	if _, err := os.Stat(dir); os.IsNotExist(err) {
-		if err := os.MkdirAll(dir, 0755); err != nil {
-			return fmt.Errorf("임시 디렉토리(%s) 생성 실패: %w", dir, err)
-		}
-	}
-
-	client := http.Client{
-		CheckRedirect: func(req *http.Request, via []*http.Request) error {
-			if len(via) >= 10 {
-				return fmt.Errorf("stopped after 10 redirects")
-			}
-			return nil
-		},
-		Timeout: 120 * time.Second,
-	}
-	resp, err := client.Get(url)
-	if err != nil {
-		return fmt.Errorf("HTTP GET 실패 (%s): %w", url, err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("잘못된 응답 상태코드 (%s): %s. 응답: %s", url, resp.Status, string(bodyBytes))
-	}
-
-	out, err := os.Create(targetFilepath)
-	if err != nil {
-		return fmt.Errorf("파일 생성 실패 (%s): %w", targetFilepath, err)
-	}
-	defer out.Close()
-
-	fmt.Println("다운로드 중...")
-	size, err := io.Copy(out, resp.Body)
-	if err != nil {
-		os.Remove(targetFilepath)
-		return fmt.Errorf("파일 내용 복사 실패 (%s): %w", targetFilepath, err)
-	}
-	fmt.Printf("다운로드 완료: %s (%.2f MB)\n", filepath.Base(targetFilepath), float64(size)/(1024*1024))
-	return nil
-}
-
 func waitForExit() {
 	fmt.Println("\n오류가 발생하여 프로그램을 계속 진행할 수 없습니다.")
 	fmt.Println("자세한 오류 메시지는 위 내용을 참고하세요.")
+	if nonInteractive {
+		os.Exit(exitFailure)
+	}
 	fmt.Println("종료하려면 엔터를 누르세요...")
 	bufio.NewReader(os.Stdin).ReadString('\n')
-	os.Exit(1)
+	os.Exit(exitFailure)
 }
 
 func installProgram(name, wingetID, chocoID, downloadURL, installerName, installerArgs string, _ [][]string) bool {
@@ -938,6 +1019,11 @@ func installProgram(name, wingetID, chocoID, downloadURL, installerName, install
 				os.Remove(installerPath)
 				return false
 			}
+			if err := verifyDownload(downloadURL, installerPath); err != nil {
+				fmt.Printf("❌ %s 설치 파일 검증 실패: %v\n", name, err)
+				fmt.Printf("   수동 설치 URL: %s\n", downloadURL)
+				return false
+			}
 
 			fmt.Printf("%s 설치 프로그램 실행 중... (UAC 프롬프트가 나타날 수 있습니다)\n", name)
 			var installCmd *exec.Cmd
@@ -967,7 +1053,7 @@ func installProgram(name, wingetID, chocoID, downloadURL, installerName, install
 					installCmd = exec.Command(fullArgs[0], fullArgs[1:]...)
 				}
 			} else {
-				fmt.Printf("Linux/macOS용 %s 설치 로직은 여기에 구현해야 합니다.\n", name)
+				// Linux/macOS는 installGitUnix/installNodeUnix(패키지 관리자)로 설치합니다.
 				os.Remove(installerPath)
 				return false
 			}
@@ -995,19 +1081,13 @@ func installProgram(name, wingetID, chocoID, downloadURL, installerName, install
 }
 
 func addProgramToPathPermanent(programName string, commonPaths []string) bool {
-	if runtime.GOOS != "windows" {
-		return false
-	}
-	if !isAdmin {
-		return false
-	}
 
 	fmt.Printf("시스템 PATH에 %s 설치 경로 추가 시도 중...\n", programName)
 	pathSuccessfullyAdded := false
 	for _, progPath := range commonPaths {
 		if fi, err := os.Stat(progPath); err == nil && fi.IsDir() {
 			fmt.Printf("   발견된 %s 관련 경로: %s\n", programName, progPath)
-			err := addToSystemPathRegistry(progPath)
+			err := host.addToPersistentPath(progPath)
 			if err != nil {
 				fmt.Printf("   ⚠️ '%s' 경로를 시스템 PATH에 추가하는데 실패했습니다: %v\n", progPath, err)
 			} else {
@@ -1025,31 +1105,28 @@ func addProgramToPathPermanent(programName string, commonPaths []string) bool {
 }
 
 func getConfigPath() (string, error) {
-	sillyTavernDir := filepath.Join(".", defaultBaseDir)
-	if _, err := os.Stat(sillyTavernDir); os.IsNotExist(err) {
-		if _, err2 := os.Stat(defaultBaseDir); os.IsNotExist(err2) {
-			return "", fmt.Errorf("SillyTavern 디렉토리(%s 또는 %s)를 찾을 수 없습니다. 먼저 설치해주세요.", sillyTavernDir, defaultBaseDir)
-		}
-		sillyTavernDir = defaultBaseDir
+	if _, err := os.Stat(installDir); os.IsNotExist(err) {
+		return "", fmt.Errorf("%w: SillyTavern 디렉토리(%s)를 찾을 수 없습니다. 먼저 설치해주세요.", errNotInstalled, installDir)
 	}
-	return filepath.Join(sillyTavernDir, configFileName), nil
+	return filepath.Join(installDir, configFileName), nil
 }
 
-func loadConfig(filePath string) (map[string]interface{}, error) {
+// loadConfig는 설정 파일을 주석/순서를 유지하는 노드 트리로 읽습니다.
+func loadConfig(filePath string) (*configDocument, error) {
 	data, err := os.ReadFile(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("'%s' 파일 읽기 실패: %w", filePath, err)
 	}
-	var config map[string]interface{}
-	err = yaml.Unmarshal(data, &config)
+	config, err := parseConfigDocument(data)
 	if err != nil {
 		return nil, fmt.Errorf("'%s' YAML 파싱 실패: %w", filePath, err)
 	}
 	return config, nil
 }
 
-func saveConfig(filePath string, config map[string]interface{}) error {
-	data, err := yaml.Marshal(config)
+// saveConfig는 변경한 키만 바뀌도록 노드 트리를 저장합니다. 기존 파일은 .bak.<시각>으로 백업됩니다.
+func saveConfig(filePath string, config *configDocument) error {
+	data, err := config.encode()
 	if err != nil {
 		return fmt.Errorf("YAML 마샬링 실패: %w", err)
 	}
@@ -1087,18 +1164,10 @@ func changePortSetting() {
 		fmt.Println("설정 파일 로드 오류:", err)
 		return
 	}
-	currentPortVal, exists := config["port"]
-	if exists {
-		switch v := currentPortVal.(type) {
-		case int:
-			fmt.Printf("현재 설정된 포트: %d\n", v)
-		case float64:
-			fmt.Printf("현재 설정된 포트: %.0f\n", v)
-		case string:
-			fmt.Printf("현재 설정된 포트: %s\n", v)
-		default:
-			fmt.Println("현재 포트 정보를 읽을 수 없거나 알 수 없는 형식입니다.")
-		}
+	if currentPort, exists := config.getString("port"); exists {
+		fmt.Printf("현재 설정된 포트: %s\n", currentPort)
+	} else if config.get("port") != nil {
+		fmt.Println("현재 포트 정보를 읽을 수 없거나 알 수 없는 형식입니다.")
 	} else {
 		fmt.Println("현재 설정된 포트 정보가 없습니다. 기본값(예: 8000)으로 간주됩니다.")
 	}
@@ -1108,217 +1177,51 @@ func changePortSetting() {
 		fmt.Println("입력이 없어 포트를 변경하지 않습니다.")
 		return
 	}
-	newPort, err := strconv.Atoi(inputPortStr)
-	if err != nil || newPort < 1 || newPort > 65535 {
-		fmt.Println("잘못된 포트 번호입니다. 1에서 65535 사이의 숫자를 입력해주세요.")
-		return
-	}
-	config["port"] = newPort
-	err = saveConfig(configPath, config)
-	if err != nil {
-		fmt.Println("❌ 설정 파일 저장 오류:", err)
-	} else {
-		fmt.Printf("✅ 포트가 %d로 변경되었습니다. SillyTavern을 재시작해야 적용됩니다.\n", newPort)
-	}
-}
-
-func updateWhitelistSetting() {
-	fmt.Println("\n[ 화이트리스트 수정 ]")
-	configPath, err := getConfigPath()
+	newPort, err := parsePort(inputPortStr)
 	if err != nil {
-		fmt.Println("오류:", err)
+		fmt.Println(err)
 		return
 	}
-	config, err := loadConfig(configPath)
-	if err != nil {
-		fmt.Println("설정 파일 로드 오류:", err)
-		return
-	}
-	currentWhitelistSet := make(map[string]bool)
-	currentWhitelistDisplay := []string{}
-	if wlNode, ok := config["whitelist"]; ok {
-		if wlSlice, okSlice := wlNode.([]interface{}); okSlice {
-			for _, item := range wlSlice {
-				if ipStr, okStr := item.(string); okStr {
-					trimmedIP := strings.TrimSpace(ipStr)
-					if trimmedIP != "" && !currentWhitelistSet[trimmedIP] {
-						currentWhitelistSet[trimmedIP] = true
-						currentWhitelistDisplay = append(currentWhitelistDisplay, trimmedIP)
-					}
-				}
-			}
-		}
-	}
-	if len(currentWhitelistDisplay) > 0 {
-		fmt.Println("현재 화이트리스트:", strings.Join(currentWhitelistDisplay, ", "))
-	} else {
-		fmt.Println("현재 화이트리스트: (설정된 IP 없음)")
-	}
-	fmt.Print("추가할 화이트리스트 IP 주소를 입력하세요 (쉼표(,)로 구분, 비워두면 추가 안 함): ")
-	inputIPsStr := getUserChoice()
-	if strings.TrimSpace(inputIPsStr) == "" {
-		fmt.Println("입력이 없어 화이트리스트에 IP를 추가하지 않습니다.")
-		return
-	}
-	addedIPs := 0
-	ipsToAdd := strings.Split(inputIPsStr, ",")
-	ipRegex := regexp.MustCompile(`^((25[0-5]|2[0-4][0-9]|[01]?[0-9][0-9]?)\.){3}(25[0-5]|2[0-4][0-9]|[01]?[0-9][0-9]?)$`)
-	for _, ip := range ipsToAdd {
-		trimmedIP := strings.TrimSpace(ip)
-		if trimmedIP != "" {
-			if !ipRegex.MatchString(trimmedIP) {
-				fmt.Printf("⚠️ 잘못된 IP 주소 형식입니다: '%s' (무시됨)\n", trimmedIP)
-				continue
-			}
-			if !currentWhitelistSet[trimmedIP] {
-				currentWhitelistSet[trimmedIP] = true
-				addedIPs++
-			} else {
-				fmt.Printf("ℹ️ IP 주소 '%s'는 이미 화이트리스트에 존재합니다.\n", trimmedIP)
-			}
-		}
-	}
-	if addedIPs == 0 && len(ipsToAdd) > 0 && strings.TrimSpace(inputIPsStr) != "" {
-		fmt.Println("새로 추가된 IP 주소가 없습니다 (모두 유효하지 않거나 이미 존재).")
-	}
-
-	finalWhitelistForYAML := make([]interface{}, 0, len(currentWhitelistSet))
-	// 순서 유지를 위해 currentWhitelistDisplay (기존 + 새로 유효하게 추가된 IP) 사용
-	// 먼저 기존 목록을 넣고
-	for _, ip := range currentWhitelistDisplay {
-		finalWhitelistForYAML = append(finalWhitelistForYAML, ip)
-	}
-	// 그 다음, 새로 추가된 (그리고 currentWhitelistDisplay에 아직 없는) IP들을 추가
-	// (currentWhitelistSet을 순회하면 순서가 보장되지 않으므로, ipsToAdd를 다시 순회하며 currentWhitelistSet으로 중복 체크)
-	tempAddedSet := make(map[string]bool) // 이미 finalWhitelistForYAML에 추가된 새 IP 추적용
-	for _, ip := range currentWhitelistDisplay {
-		tempAddedSet[ip] = true
-	}
-
-	for _, ip := range ipsToAdd {
-		trimmedIP := strings.TrimSpace(ip)
-		if trimmedIP != "" && ipRegex.MatchString(trimmedIP) && !tempAddedSet[trimmedIP] {
-			finalWhitelistForYAML = append(finalWhitelistForYAML, trimmedIP)
-			tempAddedSet[trimmedIP] = true
-		}
-	}
-
-	config["whitelist"] = finalWhitelistForYAML
-
-	err = saveConfig(configPath, config)
-	if err != nil {
+	if err := setPort(configPath, newPort); err != nil {
 		fmt.Println("❌ 설정 파일 저장 오류:", err)
 	} else {
-		fmt.Println("✅ 화이트리스트가 업데이트되었습니다. SillyTavern을 재시작해야 적용됩니다.")
-		if len(finalWhitelistForYAML) > 0 {
-			displaySlice := make([]string, len(finalWhitelistForYAML))
-			for i, v := range finalWhitelistForYAML {
-				displaySlice[i] = v.(string)
-			}
-			fmt.Println("   최종 화이트리스트:", strings.Join(displaySlice, ", "))
-		} else {
-			fmt.Println("   (화이트리스트 비워짐)")
-		}
+		fmt.Printf("✅ 포트가 %d로 변경되었습니다. SillyTavern을 재시작해야 적용됩니다.\n", newPort)
+		offerRestart(installDir)
 	}
 }
 
-func getSystemPathRegistry() (string, error) {
-	if runtime.GOOS != "windows" {
-		return os.Getenv("PATH"), nil
-	}
-	k, err := registry.OpenKey(registry.LOCAL_MACHINE, regPathEnv, registry.QUERY_VALUE)
-	if err != nil {
-		return "", fmt.Errorf("레지스트리 키 열기 실패 (HKLM\\%s): %w", regPathEnv, err)
+// parsePort는 입력 문자열을 1-65535 범위의 포트 번호로 변환합니다.
+func parsePort(input string) (int, error) {
+	port, err := strconv.Atoi(strings.TrimSpace(input))
+	if err != nil || port < 1 || port > 65535 {
+		return 0, fmt.Errorf("잘못된 포트 번호입니다. 1에서 65535 사이의 숫자를 입력해주세요.")
 	}
-	defer k.Close()
-	s, _, err := k.GetStringValue("Path")
-	if err != nil {
-		if err == registry.ErrNotExist {
-			return "", nil
-		}
-		return "", fmt.Errorf("레지스트리에서 Path 값 읽기 실패: %w", err)
-	}
-	return s, nil
+	return port, nil
 }
 
-func addToSystemPathRegistry(newPathEntry string) error {
-	if runtime.GOOS != "windows" {
-		return nil
-	}
-	if !isAdmin {
-		return fmt.Errorf("시스템 PATH 수정은 관리자 권한 필요")
-	}
-
-	currentPath, err := getSystemPathRegistry()
-	if err != nil {
-		return fmt.Errorf("기존 시스템 PATH 읽기 실패: %w", err)
-	}
-
-	paths := strings.Split(currentPath, ";")
-	cleanedNewPathEntry := filepath.Clean(newPathEntry)
-	for _, p := range paths {
-		if strings.EqualFold(filepath.Clean(p), cleanedNewPathEntry) {
-			fmt.Printf("   ℹ️ 경로 '%s'는 이미 시스템 PATH에 존재합니다.\n", cleanedNewPathEntry)
-			return nil
-		}
-	}
-	var newFullPathString string
-	if strings.TrimSpace(currentPath) == "" {
-		newFullPathString = cleanedNewPathEntry
-	} else {
-		trimmedCurrentPath := strings.TrimRight(currentPath, ";")
-		newFullPathString = trimmedCurrentPath + ";" + cleanedNewPathEntry
-	}
-	k, err := registry.OpenKey(registry.LOCAL_MACHINE, regPathEnv, registry.SET_VALUE)
+// setPort는 configPath 설정 파일의 port 값을 변경하여 저장합니다.
+func setPort(configPath string, port int) error {
+	config, err := loadConfig(configPath)
 	if err != nil {
-		return fmt.Errorf("레지스트리 쓰기 위해 키 열기 실패 (HKLM\\%s, 권한 확인): %w", regPathEnv, err)
+		return err
 	}
-	defer k.Close()
-	err = k.SetExpandStringValue("Path", newFullPathString)
-	if err != nil {
-		errSz := k.SetStringValue("Path", newFullPathString)
-		if errSz != nil {
-			return fmt.Errorf("레지스트리 Path 값 쓰기 실패 (EXPAND_SZ: %v, SZ: %v)", err, errSz)
-		}
-		fmt.Println("   (참고: Path 값을 REG_SZ 타입으로 설정했습니다.)")
-	}
-	fmt.Printf("   ✅ 레지스트리의 시스템 PATH에 '%s' 추가 요청됨.\n", cleanedNewPathEntry)
-	errBroadcast := broadcastEnvironmentChange()
-	if errBroadcast != nil {
-		fmt.Printf("   ⚠️ 환경 변수 변경 브로드캐스트 실패: %v\n", errBroadcast)
-		fmt.Println("      PATH는 변경되었을 수 있으나, 일부 프로그램이 즉시 인지하지 못할 수 있습니다.")
-		fmt.Println("      가장 확실한 방법은 시스템을 재시작하는 것입니다.")
-	} else {
-		fmt.Println("   ✅ 환경 변수 변경 사항이 시스템에 알려졌습니다.")
+	config.setScalar(strconv.Itoa(port), "!!int", "port")
+	if err := saveConfig(configPath, config); err != nil {
+		return err
 	}
+	touchInstance(filepath.Dir(configPath), false, func(inst *instance) { inst.Port = port })
 	return nil
 }
 
-func broadcastEnvironmentChange() error {
-	if runtime.GOOS != "windows" {
-		return nil
-	}
-	user32 := syscall.NewLazyDLL("user32.dll")
-	if user32.Load() != nil {
-		return fmt.Errorf("user32.dll 로드 실패")
-	}
-	sendMessageTimeout := user32.NewProc("SendMessageTimeoutW")
-	if sendMessageTimeout.Find() != nil {
-		return fmt.Errorf("SendMessageTimeoutW 프로시저 찾기 실패")
-	}
-	envStr, err := syscall.UTF16PtrFromString("Environment")
+// readConfigPort는 configPath 설정 파일의 port 값을 읽습니다.
+func readConfigPort(configPath string) (int, error) {
+	config, err := loadConfig(configPath)
 	if err != nil {
-		return fmt.Errorf("UTF16PtrFromString(\"Environment\") 변환 실패: %w", err)
+		return 0, err
 	}
-	var result uintptr
-	ret, _, callErr := sendMessageTimeout.Call(
-		HWND_BROADCAST, WM_SETTINGCHANGE, 0, uintptr(unsafe.Pointer(envStr)),
-		SMTO_ABORTIFHUNG, 5000, uintptr(unsafe.Pointer(&result)))
-	if ret == 0 {
-		if callErr != nil && callErr.Error() != "The operation completed successfully." {
-			return fmt.Errorf("SendMessageTimeoutW 호출 시스템 오류 (ret 0): %w", callErr)
-		}
-		return fmt.Errorf("SendMessageTimeoutW API 호출 실패 (반환값 0)")
+	value, ok := config.getString("port")
+	if !ok {
+		return 0, fmt.Errorf("'%s'에 포트 정보가 없거나 알 수 없는 형식입니다", configPath)
 	}
-	return nil
+	return parsePort(value)
 }
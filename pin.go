@@ -0,0 +1,258 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	pinKindTag    = "tag"
+	pinKindCommit = "commit"
+
+	pinTagListLimit = 20 // 메뉴에 보여줄 최근 태그 수
+)
+
+var commitSHARegex = regexp.MustCompile(`^[0-9a-fA-F]{7,40}$`)
+
+func pinKindLabel(kind string) string {
+	if kind == pinKindCommit {
+		return "커밋"
+	}
+	return "태그"
+}
+
+// pinFor는 baseDir 인스턴스에 기록된 고정 정보를 반환합니다. 고정되지 않았으면 ref가 빈 문자열입니다.
+func pinFor(baseDir string) (kind, ref string) {
+	registry, err := loadRegistry()
+	if err != nil {
+		return "", ""
+	}
+	if inst := registry.findByPath(baseDir); inst != nil {
+		return inst.PinKind, inst.PinRef
+	}
+	return "", ""
+}
+
+// listRemoteTags는 git ls-remote --tags로 원격 저장소의 태그를 가져와 버전 내림차순으로 반환합니다.
+func listRemoteTags() ([]string, error) {
+	cmd := exec.Command(gitExecutablePath, "ls-remote", "--tags", "--refs", repoURL)
+	var errBuffer bytes.Buffer
+	cmd.Stderr = &errBuffer
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("원격 태그 목록 조회 실패 (git ls-remote): %w\n%s", err, strings.TrimSpace(errBuffer.String()))
+	}
+	var tags []string
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && strings.HasPrefix(fields[1], "refs/tags/") {
+			tags = append(tags, strings.TrimPrefix(fields[1], "refs/tags/"))
+		}
+	}
+	sort.SliceStable(tags, func(i, j int) bool {
+		vi, okI := parseVersion(tags[i])
+		vj, okJ := parseVersion(tags[j])
+		if okI && okJ && compareVersions(vi, vj) != 0 {
+			return compareVersions(vi, vj) > 0
+		}
+		if okI != okJ {
+			return okI
+		}
+		return tags[i] > tags[j]
+	})
+	return tags, nil
+}
+
+// checkoutPinnedRef는 baseDir을 지정한 태그/커밋으로 설치하거나 전환합니다 (Detached HEAD 상태가 됩니다).
+func checkoutPinnedRef(baseDir, kind, ref string) error {
+	if _, err := os.Stat(filepath.Join(baseDir, ".git")); os.IsNotExist(err) {
+		cloneBranch := installBranchForCurrent()
+		if kind == pinKindTag {
+			cloneBranch = ref // git clone -b 는 태그도 받을 수 있습니다.
+		}
+		fmt.Printf("%s 디렉토리에 실리태번을 새로 설치합니다 (%s %s)...\n", baseDir, pinKindLabel(kind), ref)
+		if err := cloneRepo(baseDir, cloneBranch); err != nil {
+			return err
+		}
+		if kind == pinKindTag {
+			return nil
+		}
+	}
+
+	snapshotBeforeChange(baseDir, "버전 고정 이전")
+	stash, err := stashLocalChanges(baseDir, "AutoStash_BeforePin")
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("원격 저장소의 태그 정보 가져오기 (git fetch origin --tags)...")
+	fetchCmd := exec.Command(gitExecutablePath, "-C", baseDir, "fetch", "origin", "--tags")
+	fetchCmd.Stdout = os.Stdout
+	fetchCmd.Stderr = os.Stderr
+	if err := fetchCmd.Run(); err != nil {
+		fmt.Println("⚠️ 원격 저장소 정보 가져오기에 실패했습니다:", err)
+	}
+	if kind == pinKindCommit {
+		// 브랜치 끝이 아닌 커밋도 받을 수 있도록 해당 커밋을 직접 요청합니다 (실패해도 이미 받은 이력에서 찾습니다).
+		exec.Command(gitExecutablePath, "-C", baseDir, "fetch", "origin", ref).Run()
+	}
+
+	target := ref
+	if kind == pinKindTag {
+		target = "refs/tags/" + ref
+	}
+	fmt.Printf("%s %s(으)로 전환 (git checkout --detach)...\n", pinKindLabel(kind), ref)
+	checkoutCmd := exec.Command(gitExecutablePath, "-C", baseDir, "checkout", "--detach", target)
+	var checkoutErr bytes.Buffer
+	checkoutCmd.Stdout = os.Stdout
+	checkoutCmd.Stderr = &checkoutErr
+	if err := checkoutCmd.Run(); err != nil {
+		fmt.Printf("❌ %s %s(으)로 전환하지 못했습니다: %v\n   Git 오류: %s\n", pinKindLabel(kind), ref, err, strings.TrimSpace(checkoutErr.String()))
+		if restoreErr := stash.restore(); restoreErr != nil {
+			fmt.Println("⚠️", restoreErr)
+		}
+		return fmt.Errorf("git checkout %s 실패: %w", ref, err)
+	}
+	return stash.restore()
+}
+
+// pinVersion은 baseDir을 태그/커밋으로 고정하고 npm 패키지를 설치합니다.
+// 고정 정보는 인스턴스 목록에 기록되어, 해제할 때까지 이후 업데이트가 같은 버전을 유지합니다.
+func pinVersion(baseDir, kind, ref string) error {
+	if kind == pinKindCommit && !commitSHARegex.MatchString(ref) {
+		return fmt.Errorf("커밋 SHA 형식이 올바르지 않습니다: '%s' (16진수 7-40자리)", ref)
+	}
+	if kind == pinKindTag {
+		tags, err := listRemoteTags()
+		if err != nil {
+			return err
+		}
+		found := false
+		for _, tag := range tags {
+			if tag == ref {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("원격 저장소에 '%s' 태그가 없습니다", ref)
+		}
+	}
+
+	if err := checkoutPinnedRef(baseDir, kind, ref); err != nil {
+		return err
+	}
+	touchInstance(baseDir, true, func(inst *instance) {
+		inst.PinKind, inst.PinRef = kind, ref
+	})
+	fmt.Printf("📌 %s %s에 고정했습니다. 고정을 해제할 때까지 업데이트는 이 버전을 유지합니다.\n", pinKindLabel(kind), ref)
+	if err := installSillyTavernDependencies(baseDir); err != nil {
+		return err
+	}
+	checkConfigDefaults(baseDir)
+	recordInstanceUpdate(baseDir)
+	return nil
+}
+
+// clearPin은 고정을 해제하고 인스턴스 브랜치의 최신 버전으로 돌아갑니다.
+func clearPin(baseDir string) error {
+	kind, ref := pinFor(baseDir)
+	if ref == "" {
+		fmt.Println("ℹ️ 고정된 버전이 없습니다.")
+		return nil
+	}
+	if _, err := os.Stat(filepath.Join(baseDir, ".git")); os.IsNotExist(err) {
+		touchInstance(baseDir, false, func(inst *instance) {
+			inst.PinKind, inst.PinRef = "", ""
+		})
+		fmt.Printf("📌 %s %s 고정을 해제했습니다.\n", pinKindLabel(kind), ref)
+		return nil
+	}
+	snapshotBeforeChange(baseDir, "고정 해제 이전")
+	// 브랜치로 돌아가는 데 성공해야 고정 정보를 지웁니다 (실패하면 고정된 버전 그대로 남음).
+	if err := checkoutBranch(baseDir, installBranchForCurrent(), true); err != nil {
+		if _, still := pinFor(baseDir); still == "" {
+			return err // 전환은 끝났고 그 뒤(npm 설치 등)에서 실패함
+		}
+		return fmt.Errorf("브랜치로 돌아가지 못해 %s %s 고정을 유지합니다: %w", pinKindLabel(kind), ref, err)
+	}
+	fmt.Printf("📌 %s %s 고정을 해제했습니다.\n", pinKindLabel(kind), ref)
+	return nil
+}
+
+func printPinStatus(baseDir string) {
+	if kind, ref := pinFor(baseDir); ref != "" {
+		fmt.Printf("현재 상태: %s %s에 고정됨\n", pinKindLabel(kind), ref)
+	} else {
+		fmt.Printf("현재 상태: 고정 안 됨 (브랜치 %s의 최신 버전을 따라감)\n", installBranchForCurrent())
+	}
+}
+
+// pinVersionMenu는 태그/커밋 고정 메뉴입니다.
+func pinVersionMenu() {
+	fmt.Println("\n[ 버전 고정 (태그/커밋) ]")
+	baseDir := installDir
+	printPinStatus(baseDir)
+	fmt.Println("\n1. 태그(vX.Y.Z)로 설치/업데이트 후 고정")
+	fmt.Println("2. 커밋 SHA로 체크아웃 후 고정")
+	fmt.Println("3. 고정 해제 (브랜치 최신 버전으로 복귀)")
+	fmt.Println("4. 돌아가기")
+	fmt.Print("\n선택하세요 (1-4): ")
+
+	var err error
+	switch getUserChoice() {
+	case "1":
+		fmt.Println("원격 태그 목록을 가져오는 중 (git ls-remote --tags)...")
+		tags, errTags := listRemoteTags()
+		if errTags != nil {
+			fmt.Println("❌", errTags)
+			return
+		}
+		if len(tags) == 0 {
+			fmt.Println("원격 저장소에 태그가 없습니다.")
+			return
+		}
+		shown := tags
+		if len(shown) > pinTagListLimit {
+			shown = shown[:pinTagListLimit]
+		}
+		for i, tag := range shown {
+			fmt.Printf("  %d) %s\n", i+1, tag)
+		}
+		if len(tags) > len(shown) {
+			fmt.Printf("  ... 외 %d개 (목록에 없는 태그는 이름으로 입력)\n", len(tags)-len(shown))
+		}
+		fmt.Print("고정할 태그 번호 또는 이름을 입력하세요: ")
+		input := strings.TrimSpace(getUserChoice())
+		if n, errNum := strconv.Atoi(input); errNum == nil && n >= 1 && n <= len(shown) {
+			input = shown[n-1]
+		}
+		if input == "" {
+			fmt.Println("입력이 없어 취소합니다.")
+			return
+		}
+		err = pinVersion(baseDir, pinKindTag, input)
+	case "2":
+		fmt.Print("고정할 커밋 SHA를 입력하세요: ")
+		input := strings.TrimSpace(getUserChoice())
+		if input == "" {
+			fmt.Println("입력이 없어 취소합니다.")
+			return
+		}
+		err = pinVersion(baseDir, pinKindCommit, input)
+	case "3":
+		err = clearPin(baseDir)
+	default:
+		return
+	}
+	if err != nil {
+		fmt.Println("\n❌ 버전 고정 작업을 완료하지 못했습니다:", err)
+	}
+}
@@ -0,0 +1,295 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// packageManager는 Linux/macOS 패키지 관리자 하나의 설치 방법입니다.
+type packageManager struct {
+	name      string              // 실행 파일 이름 (감지용)
+	refresh   []string            // 설치 전에 실행할 패키지 목록 갱신 명령 (없으면 nil)
+	install   []string            // 설치 명령 (패키지 이름은 뒤에 붙음)
+	needsRoot bool                // 관리자(root) 권한이 필요한지 여부
+	packages  map[string][]string // "git", "node" -> 실제 패키지 이름
+}
+
+// packageManagers는 감지 순서대로 나열한 지원 패키지 관리자입니다.
+var packageManagers = []packageManager{
+	{"apt-get", []string{"apt-get", "update"}, []string{"apt-get", "install", "-y"}, true,
+		map[string][]string{"git": {"git"}, "node": {"nodejs", "npm"}}},
+	{"dnf", nil, []string{"dnf", "install", "-y"}, true,
+		map[string][]string{"git": {"git"}, "node": {"nodejs", "npm"}}},
+	{"pacman", nil, []string{"pacman", "-S", "--needed", "--noconfirm"}, true,
+		map[string][]string{"git": {"git"}, "node": {"nodejs", "npm"}}},
+	{"zypper", nil, []string{"zypper", "--non-interactive", "install"}, true,
+		map[string][]string{"git": {"git"}, "node": {"nodejs-default", "npm-default"}}},
+	{"apk", nil, []string{"apk", "add"}, true,
+		map[string][]string{"git": {"git"}, "node": {"nodejs", "npm"}}},
+	{"brew", nil, []string{"brew", "install"}, false,
+		map[string][]string{"git": {"git"}, "node": {"node"}}},
+}
+
+// detectPackageManager는 시스템에서 사용할 수 있는 첫 번째 패키지 관리자를 반환합니다.
+func detectPackageManager() *packageManager {
+	for i := range packageManagers {
+		if _, err := exec.LookPath(packageManagers[i].name); err == nil {
+			return &packageManagers[i]
+		}
+	}
+	return nil
+}
+
+// privilegedCommand는 root 권한이 필요한 명령이면 sudo를 붙여 반환합니다. 이미 root이면 그대로 실행합니다.
+// 비대화형 실행 중에는 sudo가 암호를 묻지 않도록 -n 옵션을 사용합니다.
+func privilegedCommand(needsRoot bool, args []string) (*exec.Cmd, error) {
+	if needsRoot && !isAdmin {
+		sudo, err := exec.LookPath("sudo")
+		if err != nil {
+			return nil, fmt.Errorf("관리자 권한이 필요하지만 sudo를 찾을 수 없습니다 (root로 실행하거나 직접 설치해주세요)")
+		}
+		prefix := []string{sudo}
+		if nonInteractive {
+			prefix = append(prefix, "-n")
+		}
+		args = append(prefix, args...)
+	}
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd, nil
+}
+
+// installUnixPackage는 감지한 패키지 관리자로 git 또는 node("git", "node")를 설치합니다.
+func installUnixPackage(key string) error {
+	pm := detectPackageManager()
+	if pm == nil {
+		return fmt.Errorf("지원하는 패키지 관리자(apt, dnf, pacman, zypper, apk, brew)를 찾지 못했습니다")
+	}
+	packages := pm.packages[key]
+	fmt.Printf("%s(으)로 설치 중: %s\n", pm.name, strings.Join(packages, " "))
+	if pm.needsRoot && !isAdmin {
+		fmt.Println("ℹ️ 관리자 권한이 필요하여 sudo로 실행합니다 (암호를 물을 수 있습니다).")
+	}
+	if pm.refresh != nil {
+		cmd, err := privilegedCommand(pm.needsRoot, pm.refresh)
+		if err != nil {
+			return err
+		}
+		if err := cmd.Run(); err != nil {
+			fmt.Printf("⚠️ 패키지 목록 갱신 실패 (%s): %v\n", strings.Join(pm.refresh, " "), err)
+		}
+	}
+	cmd, err := privilegedCommand(pm.needsRoot, append(append([]string{}, pm.install...), packages...))
+	if err != nil {
+		return err
+	}
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s 설치 실패: %w", pm.name, err)
+	}
+	return nil
+}
+
+// installGitUnix는 Linux/macOS에서 패키지 관리자로 Git을 설치합니다.
+// brew가 없는 macOS에서는 Git이 포함된 Xcode 명령줄 도구 설치를 요청합니다.
+func installGitUnix() bool {
+	fmt.Println("\n--- Git 자동 설치 시도 ---")
+	err := installUnixPackage("git")
+	if err == nil {
+		fmt.Println("✅ Git 설치 명령 성공.")
+		return true
+	}
+	fmt.Println("❌", err)
+	if runtime.GOOS == "darwin" {
+		fmt.Println("Xcode 명령줄 도구 설치를 요청합니다 (xcode-select --install). 설치 창이 끝난 뒤 다시 실행해주세요.")
+		exec.Command("xcode-select", "--install").Run()
+	}
+	return false
+}
+
+// installNodeUnix는 Linux/macOS에서 패키지 관리자로 Node.js를 설치합니다.
+// 패키지 관리자가 없거나, 설치된 버전이 최소 요구 버전보다 낮으면 홈 디렉토리에 최신 LTS tar.gz를 풀어 사용합니다.
+func installNodeUnix() bool {
+	fmt.Println("\n--- Node.js LTS 자동 설치 시도 ---")
+	if err := installUnixPackage("node"); err != nil {
+		fmt.Println("❌", err)
+	} else if p, errLook := exec.LookPath("node"); errLook == nil {
+		_, errVer := checkMinimumVersion(nodeRequirement, p)
+		if errVer == nil {
+			fmt.Println("✅ Node.js 설치 명령 성공.")
+			return true
+		}
+		fmt.Println("⚠️ 패키지 관리자로 설치한 Node.js를 사용할 수 없습니다:", errVer)
+	}
+
+	fmt.Println("홈 디렉토리에 Node.js LTS를 내려받아 설치합니다 (관리자 권한 불필요)...")
+	binDir, err := installUserLocalNode()
+	if err != nil {
+		fmt.Println("❌ Node.js 사용자 설치 실패:", err)
+		return false
+	}
+	// npm은 'node'를 PATH에서 찾으므로 이번 실행의 PATH 맨 앞에 추가합니다.
+	os.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	fmt.Printf("✅ Node.js를 %s에 설치했습니다.\n", filepath.Dir(binDir))
+	if confirm("터미널에서도 이 Node.js를 사용하도록 셸 설정 파일에 PATH를 추가하시겠습니까? (y/n): ") {
+		if err := host.addToPersistentPath(binDir); err != nil {
+			fmt.Println("⚠️ PATH 등록 실패:", err)
+		}
+	} else {
+		fmt.Printf("   직접 추가하려면 셸 설정 파일(~/.bashrc, ~/.zshrc 등)에 다음 줄을 추가하세요:\n   export PATH=\"%s:$PATH\"\n", binDir)
+	}
+	return true
+}
+
+// userLocalNodeDir은 사용자 설치 Node.js를 보관하는 디렉토리입니다 (~/.local/share/SillyTavernInstaller/node).
+func userLocalNodeDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("홈 디렉토리 확인 실패: %w", err)
+	}
+	return filepath.Join(home, ".local", "share", installerDirName, "node"), nil
+}
+
+// installUserLocalNode는 최신 LTS tar.gz를 userLocalNodeDir 아래에 내려받아 풀고, bin 디렉토리를 반환합니다.
+func installUserLocalNode() (string, error) {
+	_, url, err := resolveNodeTarballURL(nodeMirrorURL())
+	if err != nil {
+		return "", err
+	}
+	baseDir, err := userLocalNodeDir()
+	if err != nil {
+		return "", err
+	}
+	nodeHome, err := fetchNodeArchive(url, baseDir)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(nodeHome, "bin"), nil
+}
+
+// extractTarGz는 tar.gz 파일을 destDir 아래에 풉니다. 심볼릭 링크(npm 등)도 그대로 만들며,
+// destDir 밖을 가리키는 경로는 거부합니다.
+func extractTarGz(archivePath, destDir string) error {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return fmt.Errorf("압축 파일 열기 실패: %w", err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("gzip 해제 실패: %w", err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("tar 읽기 실패: %w", err)
+		}
+		target := filepath.Join(destDir, filepath.FromSlash(hdr.Name))
+		if !strings.HasPrefix(target, filepath.Clean(destDir)+string(os.PathSeparator)) {
+			return fmt.Errorf("압축 파일에 잘못된 경로가 있습니다: %s", hdr.Name)
+		}
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			if !realPathWithin(destDir, filepath.Dir(target)) {
+				return fmt.Errorf("압축 파일에 심볼릭 링크를 통해 %s 밖에 쓰는 경로가 있습니다: %s", destDir, hdr.Name)
+			}
+			out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.FileMode(hdr.Mode)&0777)
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(out, tr); err != nil {
+				out.Close()
+				return fmt.Errorf("'%s' 압축 해제 실패: %w", hdr.Name, err)
+			}
+			out.Close()
+		case tar.TypeSymlink:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			if err := checkSymlinkTarget(destDir, target, hdr.Linkname); err != nil {
+				return fmt.Errorf("압축 파일의 심볼릭 링크 '%s'를 거부합니다: %w", hdr.Name, err)
+			}
+			os.Remove(target)
+			if err := os.Symlink(hdr.Linkname, target); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// checkSymlinkTarget은 linkPath에 만들 심볼릭 링크가 가리킬 위치(linkname)가 destDir 안인지 확인합니다.
+// 절대 경로는 거부하고, 경로 문자열뿐 아니라 이미 만든 링크를 거쳐 가는 ".."까지 실제로 따라가 본 위치도 검사합니다.
+func checkSymlinkTarget(destDir, linkPath, linkname string) error {
+	if linkname == "" || filepath.IsAbs(linkname) || strings.HasPrefix(linkname, "/") || filepath.VolumeName(linkname) != "" {
+		return fmt.Errorf("절대 경로를 가리킵니다: %s", linkname)
+	}
+	if !pathWithin(destDir, filepath.Join(filepath.Dir(linkPath), filepath.FromSlash(linkname))) {
+		return fmt.Errorf("%s 밖을 가리킵니다: %s", destDir, linkname)
+	}
+	realDest, err := filepath.EvalSymlinks(destDir)
+	if err != nil {
+		return err
+	}
+	cur, err := filepath.EvalSymlinks(filepath.Dir(linkPath))
+	if err != nil {
+		return err
+	}
+	for _, part := range strings.Split(filepath.ToSlash(linkname), "/") {
+		switch part {
+		case "", ".":
+			continue
+		case "..":
+			cur = filepath.Dir(cur)
+		default:
+			cur = filepath.Join(cur, part)
+			if real, err := filepath.EvalSymlinks(cur); err == nil {
+				cur = real
+			}
+		}
+	}
+	if !pathWithin(realDest, cur) {
+		return fmt.Errorf("%s 밖을 가리킵니다: %s", destDir, linkname)
+	}
+	return nil
+}
+
+// realPathWithin은 심볼릭 링크를 모두 푼 path가 심볼릭 링크를 푼 root 안에 있는지 확인합니다.
+func realPathWithin(root, path string) bool {
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return false
+	}
+	realPath, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return false
+	}
+	return pathWithin(realRoot, realPath)
+}
+
+// pathWithin은 path가 root와 같거나 root 아래에 있는지 경로 문자열만으로 확인합니다.
+func pathWithin(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(os.PathSeparator)) && !filepath.IsAbs(rel)
+}
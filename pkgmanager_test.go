@@ -0,0 +1,94 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// tarEntry는 테스트용 tar.gz에 넣을 항목입니다. link가 있으면 심볼릭 링크, 없으면 일반 파일입니다.
+type tarEntry struct {
+	name, body, link string
+}
+
+func writeTestTarGz(t *testing.T, entries []tarEntry) string {
+	t.Helper()
+	archivePath := filepath.Join(t.TempDir(), "test.tar.gz")
+	f, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for _, e := range entries {
+		hdr := &tar.Header{Name: e.name, Mode: 0644, Typeflag: tar.TypeReg, Size: int64(len(e.body))}
+		if e.link != "" {
+			hdr = &tar.Header{Name: e.name, Mode: 0777, Typeflag: tar.TypeSymlink, Linkname: e.link}
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(e.body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return archivePath
+}
+
+func TestExtractTarGzSymlinks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("심볼릭 링크를 만들려면 권한이 필요합니다")
+	}
+	archive := writeTestTarGz(t, []tarEntry{
+		{name: "node/lib/node_modules/npm/bin/npm-cli.js", body: "// npm"},
+		{name: "node/bin/npm", link: "../lib/node_modules/npm/bin/npm-cli.js"},
+		{name: "node/bin/self", link: "."},
+	})
+	destDir := t.TempDir()
+	if err := extractTarGz(archive, destDir); err != nil {
+		t.Fatalf("extractTarGz: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(destDir, "node", "bin", "npm"))
+	if err != nil || string(data) != "// npm" {
+		t.Errorf("read through bin/npm = %q, %v", data, err)
+	}
+}
+
+func TestExtractTarGzRejectsEscapingSymlinks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("심볼릭 링크를 만들려면 권한이 필요합니다")
+	}
+	tests := map[string][]tarEntry{
+		"absolute":  {{name: "node/bin/evil", link: "/etc/passwd"}},
+		"relative":  {{name: "node/bin/evil", link: "../../../outside"}},
+		"to parent": {{name: "evil", link: ".."}},
+		// d/e/up은 destDir을 가리키므로 문자열로는 d/e/up/../..가 d이지만, 실제로는 destDir의 부모의 부모입니다.
+		"through link": {
+			{name: "d/e/up", link: "../.."},
+			{name: "d/e/evil", link: "up/../.."},
+		},
+	}
+	for name, entries := range tests {
+		root := t.TempDir()
+		destDir := filepath.Join(root, "a", "b", "dest")
+		if err := os.MkdirAll(destDir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := extractTarGz(writeTestTarGz(t, entries), destDir); err == nil {
+			t.Errorf("%s: extractTarGz accepted a link that leaves destDir", name)
+		}
+		if _, err := os.Lstat(filepath.Join(destDir, "d", "e", "evil")); err == nil {
+			t.Errorf("%s: escaping link was created", name)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+)
+
+// platform은 운영체제마다 구현이 다른 기능을 모은 인터페이스입니다.
+// Windows 구현은 platform_windows.go, Linux/macOS 구현은 platform_unix.go에 있습니다.
+type platform interface {
+	// isAdmin은 관리자(Windows) 또는 root(Linux/macOS) 권한으로 실행 중인지 확인합니다.
+	isAdmin() bool
+	// addToPersistentPath는 dir을 새 터미널에서도 유지되는 PATH에 추가합니다. 이미 있으면 아무것도 하지 않습니다.
+	addToPersistentPath(dir string) error
+	setConsoleTitle(title string)
+	clearScreen()
+	// openBrowser는 기본 브라우저로 url을 엽니다 (브라우저가 닫힐 때까지 기다리지 않음).
+	openBrowser(url string) error
+	// detachProcess는 cmd가 현재 콘솔/터미널과 분리되어 설치 프로그램이 끝난 뒤에도 계속 실행되도록 설정합니다.
+	detachProcess(cmd *exec.Cmd)
+	// processAlive는 pid 프로세스가 실행 중인지 확인합니다.
+	processAlive(pid int) bool
+	// terminate는 프로세스에 정상 종료를 요청합니다 (요청 방법이 없으면 강제 종료).
+	terminate(p *os.Process) error
+	// installService는 svc를 부팅 시 실행되는 서비스(systemd 유닛 / 예약 작업)로 등록하고 정의 파일 경로를 반환합니다.
+	// enable이 false이면 정의 파일만 작성합니다.
+	installService(svc *serviceSpec, enable bool) (string, error)
+	// uninstallService는 name 서비스를 중지하고 등록을 해제합니다.
+	uninstallService(name string) error
+	// serviceStatus는 name 서비스의 상태를 출력합니다.
+	serviceStatus(name string) error
+}
+
+// host는 현재 운영체제의 platform 구현입니다.
+var host platform = newPlatform()
@@ -0,0 +1,197 @@
+//go:build !windows
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+)
+
+// unixPlatform은 셸 설정 파일과 ANSI 이스케이프 코드를 사용합니다.
+type unixPlatform struct{}
+
+func newPlatform() platform { return unixPlatform{} }
+
+func (unixPlatform) isAdmin() bool { return os.Geteuid() == 0 }
+
+// addToPersistentPath는 로그인 셸 설정 파일(~/.zshrc, ~/.bashrc 또는 ~/.profile)에 PATH 설정 줄을 추가합니다.
+func (unixPlatform) addToPersistentPath(dir string) error {
+	profile, err := shellProfilePath()
+	if err != nil {
+		return err
+	}
+	line := fmt.Sprintf("export PATH=\"%s:$PATH\"", dir)
+	data, err := os.ReadFile(profile)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("'%s' 읽기 실패: %w", profile, err)
+	}
+	if strings.Contains(string(data), line) {
+		fmt.Printf("   ℹ️ 경로 '%s'는 이미 %s에 등록되어 있습니다.\n", dir, profile)
+		return nil
+	}
+	f, err := os.OpenFile(profile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("'%s' 열기 실패: %w", profile, err)
+	}
+	defer f.Close()
+	prefix := ""
+	if len(data) > 0 && !strings.HasSuffix(string(data), "\n") {
+		prefix = "\n"
+	}
+	if _, err := fmt.Fprintf(f, "%s# SillyTavern Installer\n%s\n", prefix, line); err != nil {
+		return fmt.Errorf("'%s' 쓰기 실패: %w", profile, err)
+	}
+	fmt.Printf("   ✅ %s에 '%s' 경로를 추가했습니다. 새 터미널부터 적용됩니다.\n", profile, dir)
+	return nil
+}
+
+// shellProfilePath는 $SHELL에 맞는 셸 설정 파일 경로를 반환합니다.
+func shellProfilePath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("홈 디렉토리 확인 실패: %w", err)
+	}
+	switch filepath.Base(os.Getenv("SHELL")) {
+	case "zsh":
+		return filepath.Join(home, ".zshrc"), nil
+	case "bash":
+		return filepath.Join(home, ".bashrc"), nil
+	default:
+		return filepath.Join(home, ".profile"), nil
+	}
+}
+
+func (unixPlatform) setConsoleTitle(title string) {
+	fmt.Printf("\033]0;%s\007", title)
+}
+
+func (unixPlatform) clearScreen() {
+	fmt.Print("\033[H\033[2J")
+}
+
+func (unixPlatform) detachProcess(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+}
+
+func (unixPlatform) processAlive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || err == syscall.EPERM
+}
+
+func (unixPlatform) terminate(p *os.Process) error {
+	return p.Signal(syscall.SIGTERM)
+}
+
+func (unixPlatform) openBrowser(url string) error {
+	opener := "xdg-open"
+	if runtime.GOOS == "darwin" {
+		opener = "open"
+	}
+	return exec.Command(opener, url).Start()
+}
+
+// installService는 systemd 유닛 파일을 작성하고 활성화합니다. 사용자 유닛은 로그인하지 않아도 부팅 시 실행되도록
+// loginctl enable-linger를 함께 설정합니다.
+func (unixPlatform) installService(svc *serviceSpec, enable bool) (string, error) {
+	if runtime.GOOS != "linux" {
+		return "", fmt.Errorf("이 운영체제에서는 서비스 등록을 지원하지 않습니다")
+	}
+	if svc.System && !isAdmin {
+		return "", fmt.Errorf("시스템 서비스를 등록하려면 root 권한이 필요합니다 (sudo로 실행해주세요)")
+	}
+	unitDir, err := systemdUnitDir(svc.System)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(unitDir, 0755); err != nil {
+		return "", fmt.Errorf("디렉토리(%s) 생성 실패: %w", unitDir, err)
+	}
+	unitPath := filepath.Join(unitDir, svc.Name+".service")
+	if err := os.WriteFile(unitPath, []byte(renderSystemdUnit(svc)), 0644); err != nil {
+		return "", fmt.Errorf("유닛 파일(%s) 쓰기 실패: %w", unitPath, err)
+	}
+	if !enable {
+		return unitPath, nil
+	}
+	if err := systemctl(svc.System, "daemon-reload"); err != nil {
+		return unitPath, err
+	}
+	if err := systemctl(svc.System, "enable", "--now", svc.Name+".service"); err != nil {
+		return unitPath, err
+	}
+	if !svc.System {
+		if err := exec.Command("loginctl", "enable-linger", svc.User).Run(); err != nil {
+			fmt.Printf("⚠️ 로그인하지 않아도 실행되도록 설정하지 못했습니다 (loginctl enable-linger %s): %v\n", svc.User, err)
+			fmt.Println("   로그인해야 실행됩니다. 관리자에게 위 명령 실행을 요청하거나 시스템 서비스로 등록해주세요.")
+		}
+	}
+	return unitPath, nil
+}
+
+func (unixPlatform) uninstallService(name string) error {
+	unitPath, system, err := findSystemdUnit(name)
+	if err != nil {
+		return err
+	}
+	if system && !isAdmin {
+		return fmt.Errorf("시스템 서비스를 해제하려면 root 권한이 필요합니다 (sudo로 실행해주세요)")
+	}
+	if err := systemctl(system, "disable", "--now", name+".service"); err != nil {
+		fmt.Println("⚠️", err)
+	}
+	if err := os.Remove(unitPath); err != nil {
+		return fmt.Errorf("유닛 파일(%s) 삭제 실패: %w", unitPath, err)
+	}
+	if err := systemctl(system, "daemon-reload"); err != nil {
+		fmt.Println("⚠️", err)
+	}
+	return nil
+}
+
+func (unixPlatform) serviceStatus(name string) error {
+	unitPath, system, err := findSystemdUnit(name)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("유닛 파일: %s\n", unitPath)
+	args := []string{"status", "--no-pager", name + ".service"}
+	if !system {
+		args = append([]string{"--user"}, args...)
+	}
+	cmd := exec.Command("systemctl", args...)
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	cmd.Run() // 중지된 서비스는 0이 아닌 종료 코드를 반환하므로 무시
+	return nil
+}
+
+// findSystemdUnit은 name 유닛 파일을 사용자 유닛, 시스템 유닛 순서로 찾습니다.
+func findSystemdUnit(name string) (string, bool, error) {
+	for _, system := range []bool{false, true} {
+		unitDir, err := systemdUnitDir(system)
+		if err != nil {
+			continue
+		}
+		unitPath := filepath.Join(unitDir, name+".service")
+		if _, err := os.Stat(unitPath); err == nil {
+			return unitPath, system, nil
+		}
+	}
+	return "", false, fmt.Errorf("등록된 서비스(%s)가 없습니다", name)
+}
+
+func systemctl(system bool, args ...string) error {
+	if !system {
+		args = append([]string{"--user"}, args...)
+	}
+	cmd := exec.Command("systemctl", args...)
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("systemctl %s 실패: %w", strings.Join(args, " "), err)
+	}
+	return nil
+}
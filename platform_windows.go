@@ -0,0 +1,243 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"unicode/utf16"
+	"unsafe"
+
+	"golang.org/x/sys/windows/registry" // 레지스트리 접근용
+
+	기초 "golang.org/x/sys/windows" // 관리자 권한 확인 등 Windows 특정 API용
+)
+
+// Windows 레지스트리 및 메시지 관련 상수
+const (
+	regPathEnv       = `SYSTEM\CurrentControlSet\Control\Session Manager\Environment`
+	HWND_BROADCAST   = uintptr(0xFFFF)
+	WM_SETTINGCHANGE = uintptr(0x001A)
+	SMTO_ABORTIFHUNG = uintptr(0x0002)
+
+	stillActiveExitCode = 259 // GetExitCodeProcess가 실행 중인 프로세스에 대해 반환하는 값 (STILL_ACTIVE)
+)
+
+// windowsPlatform은 레지스트리의 시스템 PATH와 cmd 내장 명령을 사용합니다.
+type windowsPlatform struct{}
+
+func newPlatform() platform { return windowsPlatform{} }
+
+func (windowsPlatform) isAdmin() bool { return amIAdmin() }
+
+func (windowsPlatform) addToPersistentPath(dir string) error { return addToSystemPathRegistry(dir) }
+
+func (windowsPlatform) setConsoleTitle(title string) {
+	cmd := exec.Command("cmd", "/c", "title", title)
+	cmd.Run()
+}
+
+func (windowsPlatform) clearScreen() {
+	cmd := exec.Command("cmd", "/c", "cls")
+	cmd.Stdout = os.Stdout
+	cmd.Run()
+}
+
+func (windowsPlatform) openBrowser(url string) error {
+	return exec.Command("rundll32", "url.dll,FileProtocolHandler", url).Start()
+}
+
+func (windowsPlatform) detachProcess(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		CreationFlags: 기초.CREATE_NEW_PROCESS_GROUP | 기초.DETACHED_PROCESS,
+		HideWindow:    true,
+	}
+}
+
+func (windowsPlatform) processAlive(pid int) bool {
+	h, err := 기초.OpenProcess(기초.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+	if err != nil {
+		return false
+	}
+	defer 기초.CloseHandle(h)
+	var code uint32
+	if err := 기초.GetExitCodeProcess(h, &code); err != nil {
+		return false
+	}
+	return code == stillActiveExitCode
+}
+
+// terminate는 Windows에서 다른 프로세스에 Ctrl+C를 보낼 수 없으므로 강제 종료합니다.
+func (windowsPlatform) terminate(p *os.Process) error {
+	return p.Kill()
+}
+
+func amIAdmin() bool {
+	var sid *기초.SID
+	err := 기초.AllocateAndInitializeSid(
+		&기초.SECURITY_NT_AUTHORITY, 2,
+		기초.SECURITY_BUILTIN_DOMAIN_RID, 기초.DOMAIN_ALIAS_RID_ADMINS,
+		0, 0, 0, 0, 0, 0, &sid)
+	if err != nil {
+		return false
+	}
+	defer 기초.FreeSid(sid)
+	token := 기초.Token(0)
+	member, err := token.IsMember(sid)
+	if err != nil {
+		return false
+	}
+	return member
+}
+
+func getSystemPathRegistry() (string, error) {
+	k, err := registry.OpenKey(registry.LOCAL_MACHINE, regPathEnv, registry.QUERY_VALUE)
+	if err != nil {
+		return "", fmt.Errorf("레지스트리 키 열기 실패 (HKLM\\%s): %w", regPathEnv, err)
+	}
+	defer k.Close()
+	s, _, err := k.GetStringValue("Path")
+	if err != nil {
+		if err == registry.ErrNotExist {
+			return "", nil
+		}
+		return "", fmt.Errorf("레지스트리에서 Path 값 읽기 실패: %w", err)
+	}
+	return s, nil
+}
+
+func addToSystemPathRegistry(newPathEntry string) error {
+	if !isAdmin {
+		return fmt.Errorf("시스템 PATH 수정은 관리자 권한 필요")
+	}
+
+	currentPath, err := getSystemPathRegistry()
+	if err != nil {
+		return fmt.Errorf("기존 시스템 PATH 읽기 실패: %w", err)
+	}
+
+	paths := strings.Split(currentPath, ";")
+	cleanedNewPathEntry := filepath.Clean(newPathEntry)
+	for _, p := range paths {
+		if strings.EqualFold(filepath.Clean(p), cleanedNewPathEntry) {
+			fmt.Printf("   ℹ️ 경로 '%s'는 이미 시스템 PATH에 존재합니다.\n", cleanedNewPathEntry)
+			return nil
+		}
+	}
+	var newFullPathString string
+	if strings.TrimSpace(currentPath) == "" {
+		newFullPathString = cleanedNewPathEntry
+	} else {
+		trimmedCurrentPath := strings.TrimRight(currentPath, ";")
+		newFullPathString = trimmedCurrentPath + ";" + cleanedNewPathEntry
+	}
+	k, err := registry.OpenKey(registry.LOCAL_MACHINE, regPathEnv, registry.SET_VALUE)
+	if err != nil {
+		return fmt.Errorf("레지스트리 쓰기 위해 키 열기 실패 (HKLM\\%s, 권한 확인): %w", regPathEnv, err)
+	}
+	defer k.Close()
+	err = k.SetExpandStringValue("Path", newFullPathString)
+	if err != nil {
+		errSz := k.SetStringValue("Path", newFullPathString)
+		if errSz != nil {
+			return fmt.Errorf("레지스트리 Path 값 쓰기 실패 (EXPAND_SZ: %v, SZ: %v)", err, errSz)
+		}
+		fmt.Println("   (참고: Path 값을 REG_SZ 타입으로 설정했습니다.)")
+	}
+	fmt.Printf("   ✅ 레지스트리의 시스템 PATH에 '%s' 추가 요청됨.\n", cleanedNewPathEntry)
+	errBroadcast := broadcastEnvironmentChange()
+	if errBroadcast != nil {
+		fmt.Printf("   ⚠️ 환경 변수 변경 브로드캐스트 실패: %v\n", errBroadcast)
+		fmt.Println("      PATH는 변경되었을 수 있으나, 일부 프로그램이 즉시 인지하지 못할 수 있습니다.")
+		fmt.Println("      가장 확실한 방법은 시스템을 재시작하는 것입니다.")
+	} else {
+		fmt.Println("   ✅ 환경 변수 변경 사항이 시스템에 알려졌습니다.")
+	}
+	return nil
+}
+
+func broadcastEnvironmentChange() error {
+	user32 := syscall.NewLazyDLL("user32.dll")
+	if user32.Load() != nil {
+		return fmt.Errorf("user32.dll 로드 실패")
+	}
+	sendMessageTimeout := user32.NewProc("SendMessageTimeoutW")
+	if sendMessageTimeout.Find() != nil {
+		return fmt.Errorf("SendMessageTimeoutW 프로시저 찾기 실패")
+	}
+	envStr, err := syscall.UTF16PtrFromString("Environment")
+	if err != nil {
+		return fmt.Errorf("UTF16PtrFromString(\"Environment\") 변환 실패: %w", err)
+	}
+	var result uintptr
+	ret, _, callErr := sendMessageTimeout.Call(
+		HWND_BROADCAST, WM_SETTINGCHANGE, 0, uintptr(unsafe.Pointer(envStr)),
+		SMTO_ABORTIFHUNG, 5000, uintptr(unsafe.Pointer(&result)))
+	if ret == 0 {
+		if callErr != nil && callErr.Error() != "The operation completed successfully." {
+			return fmt.Errorf("SendMessageTimeoutW 호출 시스템 오류 (ret 0): %w", callErr)
+		}
+		return fmt.Errorf("SendMessageTimeoutW API 호출 실패 (반환값 0)")
+	}
+	return nil
+}
+
+// installService는 작업 정의 XML을 설치 프로그램 데이터 디렉토리에 저장하고, enable이면 작업 스케줄러에 등록한 뒤 바로 실행합니다.
+func (windowsPlatform) installService(svc *serviceSpec, enable bool) (string, error) {
+	xmlDir := filepath.Join(svc.DataDir, serviceDirName)
+	if err := os.MkdirAll(xmlDir, 0755); err != nil {
+		return "", fmt.Errorf("디렉토리(%s) 생성 실패: %w", xmlDir, err)
+	}
+	xmlPath := filepath.Join(xmlDir, svc.Name+".xml")
+	// schtasks는 UTF-16(BOM 포함) XML을 요구합니다.
+	encoded := utf16.Encode([]rune("\uFEFF" + strings.ReplaceAll(renderTaskXML(svc), "\n", "\r\n")))
+	data := make([]byte, 0, len(encoded)*2)
+	for _, c := range encoded {
+		data = append(data, byte(c), byte(c>>8))
+	}
+	if err := os.WriteFile(xmlPath, data, 0644); err != nil {
+		return "", fmt.Errorf("작업 정의(%s) 쓰기 실패: %w", xmlPath, err)
+	}
+	if !enable {
+		return xmlPath, nil
+	}
+	if !svc.System {
+		fmt.Println("ℹ️ 관리자 권한이 없어 이 사용자가 로그온할 때 실행하도록 등록합니다 (로그온 없이 부팅 시 실행하려면 관리자 권한으로 다시 등록하세요).")
+	}
+	taskName := serviceTaskFolder + svc.Name
+	if err := schtasks("/Create", "/TN", taskName, "/XML", xmlPath, "/F"); err != nil {
+		return xmlPath, err
+	}
+	if err := schtasks("/Run", "/TN", taskName); err != nil {
+		fmt.Println("⚠️ 작업을 바로 실행하지 못했습니다 (다음 부팅/로그온 때 실행됩니다):", err)
+	}
+	return xmlPath, nil
+}
+
+func (windowsPlatform) uninstallService(name string) error {
+	taskName := serviceTaskFolder + name
+	if exec.Command("schtasks", "/Query", "/TN", taskName).Run() != nil {
+		return fmt.Errorf("등록된 서비스(%s)가 없습니다", taskName)
+	}
+	schtasks("/End", "/TN", taskName)
+	return schtasks("/Delete", "/TN", taskName, "/F")
+}
+
+func (windowsPlatform) serviceStatus(name string) error {
+	cmd := exec.Command("schtasks", "/Query", "/TN", serviceTaskFolder+name, "/V", "/FO", "LIST")
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("등록된 서비스(%s)가 없습니다", serviceTaskFolder+name)
+	}
+	return nil
+}
+
+func schtasks(args ...string) error {
+	out, err := exec.Command("schtasks", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("schtasks %s 실패: %w\n%s", args[0], err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,303 @@
+package main
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+const (
+	nodeRuntimeSystem   = ""         // PATH 또는 시스템에 설치된 Node.js 사용 (기본값)
+	nodeRuntimePortable = "portable" // 인스턴스 옆 runtime/ 폴더에 내려받은 Node.js 사용
+
+	portableRuntimeDirName = "runtime"
+)
+
+var (
+	activePortableBase string // 현재 nodeExecutablePath가 가리키는 portable Node.js의 인스턴스 경로 (시스템 Node.js면 비어 있음)
+	systemNodePath     string // portable Node.js로 바꾸기 전의 node/npm 경로 (시스템 인스턴스로 돌아갈 때 복원)
+	systemNpmPath      string
+)
+
+func nodeRuntimeLabel(mode string) string {
+	if mode == nodeRuntimePortable {
+		return "인스턴스 전용 (portable)"
+	}
+	return "시스템"
+}
+
+// nodeRuntimeFor는 baseDir 인스턴스에 기록된 Node.js 실행 환경을 반환합니다.
+func nodeRuntimeFor(baseDir string) string {
+	registry, err := loadRegistry()
+	if err != nil {
+		return nodeRuntimeSystem
+	}
+	if inst := registry.findByPath(baseDir); inst != nil {
+		return inst.NodeRuntime
+	}
+	return nodeRuntimeSystem
+}
+
+// setNodeRuntime은 baseDir 인스턴스의 Node.js 실행 환경을 기록합니다. 등록되지 않은 경로이면 새로 등록합니다.
+func setNodeRuntime(baseDir, mode string) error {
+	absDir, err := normalizePath(baseDir)
+	if err != nil {
+		return err
+	}
+	return updateRegistry(func(r *instanceRegistry) error {
+		inst := r.findByPath(absDir)
+		if inst == nil {
+			inst = &instance{Name: r.uniqueName(filepath.Base(absDir)), Path: absDir}
+			if err := r.add(inst); err != nil {
+				return err
+			}
+		}
+		inst.NodeRuntime = mode
+		return nil
+	})
+}
+
+// portableRuntimeDir은 baseDir 인스턴스 전용 Node.js를 보관하는 폴더입니다.
+// 저장소 안에 두면 git stash -u 등에 함께 휩쓸리므로 인스턴스 폴더 옆의 runtime/<폴더 이름>을 사용합니다.
+func portableRuntimeDir(baseDir string) string {
+	return filepath.Join(filepath.Dir(baseDir), portableRuntimeDirName, filepath.Base(baseDir))
+}
+
+// portableNodePaths는 압축을 푼 Node.js 폴더 안의 node/npm 실행 파일 경로를 반환합니다.
+func portableNodePaths(nodeHome string) (nodePath, npmPath string) {
+	if runtime.GOOS == "windows" {
+		return filepath.Join(nodeHome, "node.exe"), filepath.Join(nodeHome, "npm.cmd")
+	}
+	return filepath.Join(nodeHome, "bin", "node"), filepath.Join(nodeHome, "bin", "npm")
+}
+
+// findPortableNode는 runtimeDir에 이미 풀어 둔 Node.js 중 가장 높은 버전의 폴더를 찾습니다.
+func findPortableNode(runtimeDir string) string {
+	matches, _ := filepath.Glob(filepath.Join(runtimeDir, "node-v*"))
+	best, bestVersion := "", [3]int{}
+	for _, m := range matches {
+		nodePath, _ := portableNodePaths(m)
+		if _, err := os.Stat(nodePath); err != nil {
+			continue
+		}
+		version, ok := parseVersion(filepath.Base(m))
+		if ok && (best == "" || compareVersions(version, bestVersion) > 0) {
+			best, bestVersion = m, version
+		}
+	}
+	return best
+}
+
+// installPortableNode는 최신 Node.js LTS 압축 파일을 내려받아 baseDir 인스턴스의 runtime 폴더에 풀고 Node.js 폴더를 반환합니다.
+// upgrade가 false이면 이미 풀어 둔 버전을 그대로 사용하며, 새 버전을 설치하면 이전 버전 폴더는 삭제합니다.
+func installPortableNode(baseDir string, upgrade bool) (string, error) {
+	runtimeDir := portableRuntimeDir(baseDir)
+	existing := findPortableNode(runtimeDir)
+	if existing != "" && !upgrade {
+		return existing, nil
+	}
+
+	baseURL := nodeMirrorURL()
+	fmt.Printf("최신 Node.js LTS 버전 확인 중 (%s/index.json)...\n", strings.TrimRight(baseURL, "/"))
+	version, url, err := resolveNodeArchiveURL(baseURL)
+	if err != nil {
+		if existing != "" {
+			fmt.Println("⚠️ 최신 버전을 확인하지 못해 기존 인스턴스 전용 Node.js를 계속 사용합니다:", err)
+			return existing, nil
+		}
+		return "", err
+	}
+	if existing != "" && filepath.Base(existing) == nodeArchiveDirName(url) {
+		fmt.Printf("ℹ️ 인스턴스 전용 Node.js가 이미 최신 LTS(%s)입니다.\n", version)
+		return existing, nil
+	}
+
+	fmt.Printf("인스턴스 전용 Node.js %s 설치 중 -> %s\n", version, runtimeDir)
+	nodeHome, err := fetchNodeArchive(url, runtimeDir)
+	if err != nil {
+		return "", err
+	}
+	if existing != "" && existing != nodeHome {
+		if err := os.RemoveAll(existing); err != nil {
+			fmt.Printf("⚠️ 이전 Node.js 폴더(%s) 삭제 실패: %v\n", existing, err)
+		}
+	}
+	fmt.Printf("✅ 인스턴스 전용 Node.js %s 설치 완료 (%s)\n", version, nodeHome)
+	return nodeHome, nil
+}
+
+// nodeArchiveDirName은 Node.js 압축 파일 URL에서 압축을 풀었을 때의 최상위 폴더 이름을 구합니다.
+func nodeArchiveDirName(url string) string {
+	name := path.Base(url)
+	for _, ext := range []string{".tar.gz", ".zip"} {
+		name = strings.TrimSuffix(name, ext)
+	}
+	return name
+}
+
+// fetchNodeArchive는 Node.js 압축 파일(zip 또는 tar.gz)을 내려받아 SHA-256을 확인한 뒤 destDir에 풀고,
+// 압축 안의 최상위 폴더(node-vX-플랫폼) 경로를 반환합니다.
+func fetchNodeArchive(url, destDir string) (string, error) {
+	archivePath := filepath.Join(os.TempDir(), path.Base(url))
+	if err := downloadFile(url, archivePath); err != nil {
+		return "", err
+	}
+	defer os.Remove(archivePath)
+	if err := verifyDownload(url, archivePath); err != nil {
+		return "", err
+	}
+
+	fmt.Printf("압축 해제 중 -> %s\n", destDir)
+	var err error
+	if strings.HasSuffix(archivePath, ".zip") {
+		err = extractZip(archivePath, destDir)
+	} else {
+		err = extractTarGz(archivePath, destDir)
+	}
+	if err != nil {
+		return "", err
+	}
+	nodeHome := filepath.Join(destDir, nodeArchiveDirName(url))
+	nodePath, _ := portableNodePaths(nodeHome)
+	if _, err := os.Stat(nodePath); err != nil {
+		return "", fmt.Errorf("압축을 푼 위치에서 node 실행 파일을 찾지 못했습니다 (%s): %w", nodeHome, err)
+	}
+	return nodeHome, nil
+}
+
+// extractZip은 zip 파일을 destDir 아래에 풉니다. destDir 밖을 가리키는 경로는 거부합니다.
+func extractZip(archivePath, destDir string) error {
+	zr, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return fmt.Errorf("압축 파일 열기 실패: %w", err)
+	}
+	defer zr.Close()
+
+	for _, f := range zr.File {
+		target := filepath.Join(destDir, filepath.FromSlash(f.Name))
+		if !strings.HasPrefix(target, filepath.Clean(destDir)+string(os.PathSeparator)) {
+			return fmt.Errorf("압축 파일에 잘못된 경로가 있습니다: %s", f.Name)
+		}
+		if f.FileInfo().IsDir() {
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		if err := extractZipFile(f, target); err != nil {
+			return fmt.Errorf("'%s' 압축 해제 실패: %w", f.Name, err)
+		}
+	}
+	return nil
+}
+
+func extractZipFile(f *zip.File, target string) error {
+	in, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, f.Mode().Perm()|0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// applyNodeRuntime은 baseDir 인스턴스의 설정에 맞게 nodeExecutablePath/npmExecutablePath를 전환합니다.
+// portable이면 인스턴스 전용 Node.js를 (없으면 내려받아) 사용하고, 시스템이면 전환 전의 경로로 되돌립니다.
+func applyNodeRuntime(baseDir string) error {
+	if nodeRuntimeFor(baseDir) != nodeRuntimePortable {
+		if activePortableBase != "" {
+			nodeExecutablePath, npmExecutablePath = systemNodePath, systemNpmPath
+			activePortableBase = ""
+		}
+		return nil
+	}
+	nodeHome, err := installPortableNode(baseDir, false)
+	if err != nil {
+		return fmt.Errorf("인스턴스 전용 Node.js 준비 실패: %w", err)
+	}
+	if activePortableBase == "" {
+		systemNodePath, systemNpmPath = nodeExecutablePath, npmExecutablePath
+	}
+	nodeExecutablePath, npmExecutablePath = portableNodePaths(nodeHome)
+	activePortableBase = baseDir
+	return nil
+}
+
+// upgradePortableNode는 현재 사용 중인 인스턴스 전용 Node.js를 최신 LTS로 교체합니다.
+func upgradePortableNode() bool {
+	nodeHome, err := installPortableNode(activePortableBase, true)
+	if err != nil {
+		fmt.Println("❌ 인스턴스 전용 Node.js 업그레이드 실패:", err)
+		return false
+	}
+	nodeExecutablePath, npmExecutablePath = portableNodePaths(nodeHome)
+	return true
+}
+
+// changeNodeRuntime은 baseDir 인스턴스의 Node.js 실행 환경을 바꾸고 바로 적용합니다.
+func changeNodeRuntime(baseDir, mode string) error {
+	if err := setNodeRuntime(baseDir, mode); err != nil {
+		return err
+	}
+	if err := applyNodeRuntime(baseDir); err != nil {
+		return err
+	}
+	if mode == nodeRuntimePortable {
+		fmt.Printf("✅ '%s'은(는) 이제 인스턴스 전용 Node.js를 사용합니다 (%s).\n", baseDir, nodeExecutablePath)
+	} else {
+		fmt.Printf("✅ '%s'은(는) 이제 시스템 Node.js를 사용합니다. (인스턴스 전용 Node.js 폴더 %s는 삭제하지 않았습니다.)\n", baseDir, portableRuntimeDir(baseDir))
+	}
+	fmt.Println("   다음 설치/업데이트부터 npm install에 적용됩니다.")
+	return nil
+}
+
+// printNodeRuntimeStatus는 baseDir 인스턴스의 Node.js 실행 환경을 출력합니다.
+func printNodeRuntimeStatus(baseDir string) {
+	mode := nodeRuntimeFor(baseDir)
+	fmt.Printf("Node.js 실행 환경: %s\n", nodeRuntimeLabel(mode))
+	if mode == nodeRuntimePortable {
+		if nodeHome := findPortableNode(portableRuntimeDir(baseDir)); nodeHome != "" {
+			fmt.Printf("   위치: %s\n", nodeHome)
+		} else {
+			fmt.Printf("   위치: %s (아직 내려받지 않음)\n", portableRuntimeDir(baseDir))
+		}
+	}
+}
+
+// nodeRuntimeMenu는 현재 인스턴스의 Node.js 실행 환경(시스템/portable)을 바꾸는 메뉴입니다.
+func nodeRuntimeMenu() {
+	fmt.Println("\n[ Node.js 실행 환경 ]")
+	printNodeRuntimeStatus(installDir)
+	fmt.Println("\n1. 시스템 Node.js 사용 (PATH 또는 설치 프로그램)")
+	fmt.Println("2. 인스턴스 전용 Node.js 사용 (관리자 권한/전역 설치 불필요)")
+	fmt.Println("3. 돌아가기")
+	fmt.Print("\n선택하세요 (1-3): ")
+
+	var mode string
+	switch getUserChoice() {
+	case "1":
+		mode = nodeRuntimeSystem
+	case "2":
+		mode = nodeRuntimePortable
+	default:
+		return
+	}
+	if err := changeNodeRuntime(installDir, mode); err != nil {
+		fmt.Println("❌", err)
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+const portableGitDirName = "git" // 설치 프로그램 데이터 디렉토리 안의 portable Git 폴더
+
+// savedGitPath는 이전에 내려받아 저장해 둔 portable Git 경로를 반환합니다. 없거나 파일이 사라졌으면 빈 문자열입니다.
+func savedGitPath() string {
+	settings, err := loadSettings()
+	if err != nil || settings.GitPath == "" {
+		return ""
+	}
+	if _, err := os.Stat(settings.GitPath); err != nil {
+		fmt.Printf("⚠️ 저장된 portable Git(%s)을 찾을 수 없어 PATH의 Git을 사용합니다.\n", settings.GitPath)
+		return ""
+	}
+	return settings.GitPath
+}
+
+func saveGitPath(gitPath string) error {
+	settings, err := loadSettings()
+	if err != nil {
+		return err
+	}
+	settings.GitPath = gitPath
+	return saveSettings(settings)
+}
+
+// findGit은 사용할 Git 실행 파일을 찾습니다. 저장된 portable Git이 있으면 PATH보다 우선합니다.
+func findGit() (string, error) {
+	if p := savedGitPath(); p != "" {
+		return p, nil
+	}
+	return exec.LookPath("git")
+}
+
+// installPortableGit은 Git for Windows의 MinGit zip을 내려받아 설치 프로그램 데이터 디렉토리에 풀고,
+// git.exe 경로를 설정에 저장합니다. 관리자 권한이나 시스템 PATH 변경이 필요하지 않습니다.
+func installPortableGit() (string, error) {
+	if runtime.GOOS != "windows" {
+		return "", fmt.Errorf("portable Git(MinGit)은 Windows에서만 지원합니다")
+	}
+	dataDir, err := installerDataDir()
+	if err != nil {
+		return "", err
+	}
+	gitRoot := filepath.Join(dataDir, portableGitDirName)
+
+	fmt.Println("최신 MinGit 버전 확인 중 (GitHub 릴리스 정보)...")
+	version, url, err := resolveMinGitURL(githubAPIBaseURL)
+	if err != nil {
+		return "", err
+	}
+	archivePath := filepath.Join(os.TempDir(), path.Base(url))
+	if err := downloadFile(url, archivePath); err != nil {
+		return "", err
+	}
+	defer os.Remove(archivePath)
+	if err := verifyDownload(url, archivePath); err != nil {
+		return "", err
+	}
+
+	// MinGit zip에는 최상위 폴더가 없으므로 파일 이름으로 폴더를 만들어 풉니다.
+	gitHome := filepath.Join(gitRoot, strings.TrimSuffix(path.Base(url), ".zip"))
+	fmt.Printf("MinGit %s 압축 해제 중 -> %s\n", version, gitHome)
+	if err := os.RemoveAll(gitHome); err != nil {
+		return "", fmt.Errorf("기존 폴더(%s) 삭제 실패: %w", gitHome, err)
+	}
+	if err := extractZip(archivePath, gitHome); err != nil {
+		return "", err
+	}
+	gitPath := filepath.Join(gitHome, "cmd", "git.exe")
+	if _, err := commandVersion(gitPath); err != nil {
+		return "", fmt.Errorf("압축을 푼 Git을 실행할 수 없습니다: %w", err)
+	}
+	if err := saveGitPath(gitPath); err != nil {
+		return "", fmt.Errorf("portable Git 경로 저장 실패: %w", err)
+	}
+
+	// 이전에 받아 둔 다른 버전은 정리합니다.
+	if old, _ := filepath.Glob(filepath.Join(gitRoot, "MinGit-*")); len(old) > 0 {
+		for _, dir := range old {
+			if dir != gitHome {
+				os.RemoveAll(dir)
+			}
+		}
+	}
+	fmt.Printf("✅ portable Git %s 설치 완료 (%s)\n", version, gitPath)
+	return gitPath, nil
+}
+
+// installGitPortableFallback은 installGit에서 portable Git 설치를 시도하고 결과를 installGit 형식으로 반환합니다.
+func installGitPortableFallback() (bool, string) {
+	gitPath, err := installPortableGit()
+	if err != nil {
+		fmt.Println("❌ portable Git 설치 실패:", err)
+		return false, "git"
+	}
+	return true, gitPath
+}
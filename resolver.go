@@ -0,0 +1,210 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+const nodeMirrorEnv = "STINSTALLER_NODE_MIRROR" // Node.js 배포 미러 주소 지정용 환경 변수 (예: 사내 미러)
+
+// nodeDistBaseURL은 Node.js 배포 파일과 index.json을 받아올 주소입니다.
+var nodeDistBaseURL = "https://nodejs.org/dist"
+
+// hostArch는 받을 설치 파일의 아키텍처를 고를 때 쓰는 값입니다 (테스트에서 바꿀 수 있도록 변수로 둠).
+var hostArch = runtime.GOARCH
+
+var (
+	resolvedNodeURL string // 확인한 최신 LTS 설치 파일 URL (실행 중 한 번만 확인)
+	resolvedGitURL  string
+)
+
+// resolveNodeInstallerURL은 baseURL의 index.json에서 최신 LTS 버전을 찾아 Windows 설치 파일(MSI) URL을 반환합니다.
+func resolveNodeInstallerURL(baseURL string) (version, url string, err error) {
+	arch := nodeWindowsArch()
+	version, err = resolveNodeLTS(baseURL, "win-"+arch+"-msi")
+	if err != nil {
+		return "", "", err
+	}
+	return version, fmt.Sprintf("%s/%s/node-%s-%s.msi", strings.TrimRight(baseURL, "/"), version, version, arch), nil
+}
+
+// resolveNodeTarballURL은 baseURL의 index.json에서 최신 LTS 버전을 찾아 Linux/macOS용 tar.gz 배포 파일 URL을 반환합니다.
+func resolveNodeTarballURL(baseURL string) (version, url string, err error) {
+	platform, fileKey := nodeUnixPlatform()
+	if platform == "" {
+		return "", "", fmt.Errorf("%s/%s용 Node.js 배포 파일이 없습니다", runtime.GOOS, hostArch)
+	}
+	version, err = resolveNodeLTS(baseURL, fileKey)
+	if err != nil {
+		return "", "", err
+	}
+	return version, fmt.Sprintf("%s/%s/node-%s-%s.tar.gz", strings.TrimRight(baseURL, "/"), version, version, platform), nil
+}
+
+// resolveNodeArchiveURL은 portable 설치에 사용할 Node.js 압축 파일 URL을 반환합니다 (Windows: zip, Linux/macOS: tar.gz).
+func resolveNodeArchiveURL(baseURL string) (version, url string, err error) {
+	if runtime.GOOS != "windows" {
+		return resolveNodeTarballURL(baseURL)
+	}
+	arch := nodeWindowsArch()
+	version, err = resolveNodeLTS(baseURL, "win-"+arch+"-zip")
+	if err != nil {
+		return "", "", err
+	}
+	return version, fmt.Sprintf("%s/%s/node-%s-win-%s.zip", strings.TrimRight(baseURL, "/"), version, version, arch), nil
+}
+
+// resolveNodeLTS는 baseURL의 index.json에서 fileKey(예: "win-x64-msi", "linux-x64") 배포 파일이 있는 최신 LTS 버전을 찾습니다.
+// index.json은 최신 버전부터 나열되므로 조건에 맞는 첫 항목을 사용합니다.
+func resolveNodeLTS(baseURL, fileKey string) (string, error) {
+	baseURL = strings.TrimRight(baseURL, "/")
+	data, err := httpGetSmall(baseURL + "/index.json")
+	if err != nil {
+		return "", err
+	}
+	var releases []struct {
+		Version string          `json:"version"`
+		LTS     json.RawMessage `json:"lts"` // LTS가 아니면 false, LTS이면 코드명 문자열
+		Files   []string        `json:"files"`
+	}
+	if err := json.Unmarshal(data, &releases); err != nil {
+		return "", fmt.Errorf("Node.js 버전 목록 파싱 실패: %w", err)
+	}
+	for _, r := range releases {
+		if string(r.LTS) == "false" || len(r.LTS) == 0 {
+			continue
+		}
+		for _, f := range r.Files {
+			if f == fileKey {
+				return r.Version, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("%s/index.json에서 %s용 LTS 배포 파일을 찾지 못했습니다", baseURL, fileKey)
+}
+
+// resolveGitInstallerURL은 apiBaseURL(GitHub API)의 Git for Windows 최신 릴리스 정보에서 설치 파일 URL을 찾습니다.
+func resolveGitInstallerURL(apiBaseURL string) (version, url string, err error) {
+	return resolveGitForWindowsAsset(apiBaseURL, `^Git-[0-9.]+(-rc[0-9]+)?-`+regexp.QuoteMeta(gitWindowsArch())+`\.exe$`)
+}
+
+// resolveMinGitURL은 Git for Windows 최신 릴리스에서 설치 없이 쓸 수 있는 MinGit zip URL을 찾습니다 (busybox 변형 제외).
+func resolveMinGitURL(apiBaseURL string) (version, url string, err error) {
+	return resolveGitForWindowsAsset(apiBaseURL, `^MinGit-[0-9.]+(-rc[0-9]+)?-`+regexp.QuoteMeta(gitWindowsArch())+`\.zip$`)
+}
+
+// resolveGitForWindowsAsset은 Git for Windows 최신 릴리스에서 이름이 namePattern에 맞는 첫 파일의 URL을 찾습니다.
+func resolveGitForWindowsAsset(apiBaseURL, namePattern string) (version, url string, err error) {
+	data, err := httpGetSmall(strings.TrimRight(apiBaseURL, "/") + "/repos/git-for-windows/git/releases/latest")
+	if err != nil {
+		return "", "", err
+	}
+	var release struct {
+		TagName string `json:"tag_name"`
+		Assets  []struct {
+			Name string `json:"name"`
+			URL  string `json:"browser_download_url"`
+		} `json:"assets"`
+	}
+	if err := json.Unmarshal(data, &release); err != nil {
+		return "", "", fmt.Errorf("Git for Windows 릴리스 정보 파싱 실패: %w", err)
+	}
+	pattern := regexp.MustCompile(namePattern)
+	for _, asset := range release.Assets {
+		if pattern.MatchString(asset.Name) {
+			return release.TagName, asset.URL, nil
+		}
+	}
+	return "", "", fmt.Errorf("Git for Windows 릴리스 %s에서 %s용 파일을 찾지 못했습니다", release.TagName, gitWindowsArch())
+}
+
+func nodeWindowsArch() string {
+	switch hostArch {
+	case "arm64":
+		return "arm64"
+	case "386":
+		return "x86"
+	default:
+		return "x64"
+	}
+}
+
+// nodeUnixPlatform은 tar.gz 파일 이름에 쓰이는 플랫폼(예: "linux-x64")과 index.json의 파일 키를 반환합니다.
+func nodeUnixPlatform() (platform, fileKey string) {
+	arch := map[string]string{"amd64": "x64", "arm64": "arm64", "arm": "armv7l", "ppc64le": "ppc64le", "s390x": "s390x"}[hostArch]
+	if arch == "" {
+		return "", ""
+	}
+	switch runtime.GOOS {
+	case "linux":
+		return "linux-" + arch, "linux-" + arch
+	case "darwin":
+		return "darwin-" + arch, "osx-" + arch + "-tar"
+	}
+	return "", ""
+}
+
+func gitWindowsArch() string {
+	switch hostArch {
+	case "arm64":
+		return "arm64"
+	case "386":
+		return "32-bit"
+	default:
+		return "64-bit"
+	}
+}
+
+// nodeMirrorURL은 Node.js 배포 주소를 반환합니다 (STINSTALLER_NODE_MIRROR 환경 변수가 우선).
+func nodeMirrorURL() string {
+	if mirror := strings.TrimSpace(os.Getenv(nodeMirrorEnv)); mirror != "" {
+		return mirror
+	}
+	return nodeDistBaseURL
+}
+
+// nodeInstallerURL은 설치할 Node.js LTS 설치 파일 URL을 반환합니다.
+// 최신 버전을 확인할 수 없으면(오프라인 등) 고정 버전의 설치 파일(fallbackNodeInstallerURL)을 사용합니다.
+func nodeInstallerURL() string {
+	if resolvedNodeURL != "" {
+		return resolvedNodeURL
+	}
+	baseURL := nodeMirrorURL()
+	fmt.Printf("최신 Node.js LTS 버전 확인 중 (%s/index.json)...\n", strings.TrimRight(baseURL, "/"))
+	version, url, err := resolveNodeInstallerURL(baseURL)
+	if err != nil {
+		fmt.Printf("⚠️ 최신 버전을 확인하지 못해 고정 버전을 사용합니다: %v\n", err)
+		resolvedNodeURL = fallbackNodeInstallerURL()
+	} else {
+		fmt.Printf("ℹ️ 최신 Node.js LTS: %s\n", version)
+		resolvedNodeURL = url
+	}
+	return resolvedNodeURL
+}
+
+// fallbackNodeInstallerURL은 고정 버전(nodeJSFallbackVersion)의 이 PC 아키텍처용 MSI URL을 반환합니다 (미러를 지정했으면 미러 주소).
+func fallbackNodeInstallerURL() string {
+	return fmt.Sprintf("%s/%s/node-%s-%s.msi", strings.TrimRight(nodeMirrorURL(), "/"), nodeJSFallbackVersion, nodeJSFallbackVersion, nodeWindowsArch())
+}
+
+// gitInstallerURL은 설치할 Git for Windows 설치 파일 URL을 반환합니다.
+// 최신 버전을 확인할 수 없으면(오프라인 등) 고정된 gitForWindowsURL을 사용합니다.
+func gitInstallerURL() string {
+	if resolvedGitURL != "" {
+		return resolvedGitURL
+	}
+	fmt.Println("최신 Git for Windows 버전 확인 중 (GitHub 릴리스 정보)...")
+	version, url, err := resolveGitInstallerURL(githubAPIBaseURL)
+	if err != nil {
+		fmt.Printf("⚠️ 최신 버전을 확인하지 못해 고정 버전을 사용합니다: %v\n", err)
+		resolvedGitURL = gitForWindowsURL
+	} else {
+		fmt.Printf("ℹ️ 최신 Git for Windows: %s\n", version)
+		resolvedGitURL = url
+	}
+	return resolvedGitURL
+}
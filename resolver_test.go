@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const testNodeIndex = `[
+{"version":"v23.1.0","lts":false,"files":["win-x64-msi","win-arm64-msi","linux-x64"]},
+{"version":"v22.11.0","lts":"Jod","files":["win-x64-msi","win-x86-msi","win-x64-zip","linux-x64","osx-arm64-tar"]},
+{"version":"v22.10.0","lts":false,"files":["win-arm64-msi"]},
+{"version":"v20.18.0","lts":"Iron","files":["win-x64-msi","win-arm64-msi","linux-x64"]}
+]`
+
+const testGitRelease = `{"tag_name":"v2.47.0.windows.1","assets":[
+{"name":"MinGit-2.47.0-busybox-64-bit.zip","browser_download_url":"https://example.invalid/MinGit-2.47.0-busybox-64-bit.zip"},
+{"name":"Git-2.47.0-64-bit.tar.bz2","browser_download_url":"https://example.invalid/Git-2.47.0-64-bit.tar.bz2"},
+{"name":"PortableGit-2.47.0-64-bit.7z.exe","browser_download_url":"https://example.invalid/PortableGit-2.47.0-64-bit.7z.exe"},
+{"name":"Git-2.47.0-64-bit.exe","browser_download_url":"https://example.invalid/Git-2.47.0-64-bit.exe"},
+{"name":"Git-2.47.0-32-bit.exe","browser_download_url":"https://example.invalid/Git-2.47.0-32-bit.exe"},
+{"name":"Git-2.47.0-arm64.exe","browser_download_url":"https://example.invalid/Git-2.47.0-arm64.exe"},
+{"name":"MinGit-2.47.0-busybox-arm64.zip","browser_download_url":"https://example.invalid/MinGit-2.47.0-busybox-arm64.zip"},
+{"name":"MinGit-2.47.0-64-bit.zip","browser_download_url":"https://example.invalid/MinGit-2.47.0-64-bit.zip"},
+{"name":"MinGit-2.47.0-arm64.zip","browser_download_url":"https://example.invalid/MinGit-2.47.0-arm64.zip"}
+]}`
+
+func withHostArch(t *testing.T, arch string) {
+	t.Helper()
+	saved := hostArch
+	hostArch = arch
+	t.Cleanup(func() { hostArch = saved })
+}
+
+func newResolverServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/dist/index.json", func(w http.ResponseWriter, r *http.Request) { fmt.Fprint(w, testNodeIndex) })
+	mux.HandleFunc("/api/repos/git-for-windows/git/releases/latest", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, testGitRelease)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestResolveNodeLTS(t *testing.T) {
+	srv := newResolverServer(t)
+	tests := []struct{ fileKey, want string }{
+		{"win-x64-msi", "v22.11.0"},   // 더 새로운 v23은 LTS가 아님
+		{"win-arm64-msi", "v20.18.0"}, // 최신 LTS에 파일이 없으면 이전 LTS
+		{"osx-arm64-tar", "v22.11.0"},
+	}
+	for _, tt := range tests {
+		got, err := resolveNodeLTS(srv.URL+"/dist/", tt.fileKey)
+		if err != nil || got != tt.want {
+			t.Errorf("resolveNodeLTS(%s) = %q, %v; want %q", tt.fileKey, got, err, tt.want)
+		}
+	}
+	if _, err := resolveNodeLTS(srv.URL+"/dist", "win-riscv64-msi"); err == nil {
+		t.Error("resolveNodeLTS found a release for a missing file key")
+	}
+}
+
+func TestResolveNodeInstallerURL(t *testing.T) {
+	srv := newResolverServer(t)
+	tests := []struct{ arch, want string }{
+		{"amd64", "/dist/v22.11.0/node-v22.11.0-x64.msi"},
+		{"386", "/dist/v22.11.0/node-v22.11.0-x86.msi"},
+		{"arm64", "/dist/v20.18.0/node-v20.18.0-arm64.msi"},
+	}
+	for _, tt := range tests {
+		withHostArch(t, tt.arch)
+		_, url, err := resolveNodeInstallerURL(srv.URL + "/dist")
+		if err != nil || url != srv.URL+tt.want {
+			t.Errorf("%s: resolveNodeInstallerURL = %q, %v; want %q", tt.arch, url, err, srv.URL+tt.want)
+		}
+	}
+}
+
+func TestResolveGitForWindowsAssets(t *testing.T) {
+	srv := newResolverServer(t)
+	tests := []struct{ arch, installer, minGit string }{
+		{"amd64", "Git-2.47.0-64-bit.exe", "MinGit-2.47.0-64-bit.zip"},
+		{"arm64", "Git-2.47.0-arm64.exe", "MinGit-2.47.0-arm64.zip"},
+		{"386", "Git-2.47.0-32-bit.exe", ""},
+	}
+	for _, tt := range tests {
+		withHostArch(t, tt.arch)
+		version, url, err := resolveGitInstallerURL(srv.URL + "/api")
+		if err != nil || version != "v2.47.0.windows.1" || !strings.HasSuffix(url, "/"+tt.installer) {
+			t.Errorf("%s: resolveGitInstallerURL = %q, %q, %v; want %s", tt.arch, version, url, err, tt.installer)
+		}
+		_, url, err = resolveMinGitURL(srv.URL + "/api")
+		switch {
+		case tt.minGit == "" && err == nil:
+			t.Errorf("%s: resolveMinGitURL = %q, want error", tt.arch, url)
+		case tt.minGit != "" && (err != nil || !strings.HasSuffix(url, "/"+tt.minGit)):
+			t.Errorf("%s: resolveMinGitURL = %q, %v; want %s (busybox excluded)", tt.arch, url, err, tt.minGit)
+		}
+	}
+}
+
+func TestInstallerURLOfflineFallback(t *testing.T) {
+	offline := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	offline.Close() // 연결할 수 없는 주소
+
+	savedDist, savedAPI := nodeDistBaseURL, githubAPIBaseURL
+	t.Cleanup(func() {
+		nodeDistBaseURL, githubAPIBaseURL = savedDist, savedAPI
+		resolvedNodeURL, resolvedGitURL = "", ""
+	})
+	nodeDistBaseURL, githubAPIBaseURL = offline.URL+"/dist", offline.URL+"/api"
+	msi := "/" + nodeJSFallbackVersion + "/node-" + nodeJSFallbackVersion
+	tests := []struct{ arch, mirror, want string }{
+		{"amd64", "", offline.URL + "/dist" + msi + "-x64.msi"},
+		{"386", "", offline.URL + "/dist" + msi + "-x86.msi"},
+		{"arm64", "", offline.URL + "/dist" + msi + "-arm64.msi"},
+		// 미러를 지정했으면 대체 URL도 미러에서 받습니다.
+		{"amd64", offline.URL + "/mirror/", offline.URL + "/mirror" + msi + "-x64.msi"},
+	}
+	for _, tt := range tests {
+		withHostArch(t, tt.arch)
+		t.Setenv(nodeMirrorEnv, tt.mirror)
+		resolvedNodeURL = ""
+		if got := nodeInstallerURL(); got != tt.want {
+			t.Errorf("%s (mirror %q): nodeInstallerURL() = %q, want %q", tt.arch, tt.mirror, got, tt.want)
+		}
+	}
+	resolvedGitURL = ""
+	if got := gitInstallerURL(); got != gitForWindowsURL {
+		t.Errorf("gitInstallerURL() = %q, want fallback %q", got, gitForWindowsURL)
+	}
+}
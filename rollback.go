@@ -0,0 +1,340 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	snapshotsDirName  = "snapshots"
+	snapshotIndexName = "snapshots.json"
+	lockFileName      = "package-lock.json"
+	maxSnapshots      = 5 // 인스턴스별로 보관할 업데이트 이전 스냅샷 수
+)
+
+// snapshot은 업데이트 직전 저장소 상태의 기록입니다. '되돌리기'로 이 상태를 복원할 수 있습니다.
+type snapshot struct {
+	ID       string    `json:"id"` // 생성 시각 기반 (예: 20260101-153000)
+	Created  time.Time `json:"created"`
+	Reason   string    `json:"reason"`
+	Commit   string    `json:"commit"`
+	Branch   string    `json:"branch,omitempty"` // 비어 있으면 Detached HEAD (버전 고정 등)
+	PinKind  string    `json:"pinKind,omitempty"`
+	PinRef   string    `json:"pinRef,omitempty"`
+	LockFile bool      `json:"lockFile,omitempty"` // package-lock.json 사본을 저장했는지 여부
+}
+
+// snapshotIndex는 인스턴스 하나의 스냅샷 목록입니다 (최신순).
+type snapshotIndex struct {
+	Path      string      `json:"path"`
+	Snapshots []*snapshot `json:"snapshots"`
+}
+
+func (s *snapshot) shortCommit() string {
+	if len(s.Commit) > 7 {
+		return s.Commit[:7]
+	}
+	return s.Commit
+}
+
+// snapshotDir은 baseDir 설치본의 스냅샷을 보관하는 디렉토리를 반환합니다.
+func snapshotDir(baseDir string) (string, error) {
+	return instanceDataDir(baseDir, snapshotsDirName)
+}
+
+// instanceDataDir은 설치 프로그램 데이터 디렉토리의 kind 폴더 아래에서 baseDir 설치본 전용 디렉토리를 반환합니다.
+// 같은 폴더 이름을 쓰는 설치본끼리 섞이지 않도록 전체 경로의 해시를 붙입니다.
+func instanceDataDir(baseDir, kind string) (string, error) {
+	key, err := instanceKey(baseDir)
+	if err != nil {
+		return "", err
+	}
+	dataDir, err := installerDataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dataDir, kind, key), nil
+}
+
+// instanceKey는 baseDir 설치본을 구분하는 이름(<폴더 이름>-<전체 경로 해시 8자리>)입니다.
+func instanceKey(baseDir string) (string, error) {
+	absDir, err := normalizePath(baseDir)
+	if err != nil {
+		return "", err
+	}
+	key := absDir
+	if pathsCaseInsensitive {
+		key = strings.ToLower(key)
+	}
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Base(absDir) + "-" + hex.EncodeToString(sum[:])[:8], nil
+}
+
+// loadSnapshots는 baseDir의 스냅샷 목록과 보관 디렉토리를 반환합니다.
+func loadSnapshots(baseDir string) (*snapshotIndex, string, error) {
+	dir, err := snapshotDir(baseDir)
+	if err != nil {
+		return nil, "", err
+	}
+	index := &snapshotIndex{}
+	if err := readJSONFile(filepath.Join(dir, snapshotIndexName), index); err != nil {
+		return nil, dir, err
+	}
+	return index, dir, nil
+}
+
+// gitHeadCommit은 저장소의 현재 커밋 전체 해시를 반환합니다.
+func gitHeadCommit(repoPath string) (string, error) {
+	out, err := exec.Command(gitExecutablePath, "-C", repoPath, "rev-parse", "HEAD").Output()
+	if err != nil {
+		return "", fmt.Errorf("현재 커밋 확인 실패: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// takeSnapshot은 baseDir의 현재 커밋, 브랜치, 고정 정보와 package-lock.json 사본을 기록합니다.
+// 직전 스냅샷과 커밋이 같으면 새로 기록하지 않으며, maxSnapshots개를 넘는 오래된 스냅샷은 삭제합니다.
+func takeSnapshot(baseDir, reason string) error {
+	if _, err := os.Stat(filepath.Join(baseDir, ".git")); os.IsNotExist(err) {
+		return nil
+	}
+	commit, err := gitHeadCommit(baseDir)
+	if err != nil {
+		return err
+	}
+	index, dir, err := loadSnapshots(baseDir)
+	if err != nil {
+		return err
+	}
+	branch, _ := getCurrentGitBranch(baseDir)
+	if len(index.Snapshots) > 0 && index.Snapshots[0].Commit == commit && index.Snapshots[0].Branch == branch {
+		return nil
+	}
+
+	now := time.Now()
+	snap := &snapshot{ID: now.Format("20060102-150405"), Created: now, Reason: reason, Commit: commit, Branch: branch}
+	snap.PinKind, snap.PinRef = pinFor(baseDir)
+	for i := 2; index.find(snap.ID) != nil; i++ {
+		snap.ID = now.Format("20060102-150405") + "-" + strconv.Itoa(i)
+	}
+
+	if lock, err := os.ReadFile(filepath.Join(baseDir, lockFileName)); err == nil {
+		if err := os.MkdirAll(filepath.Join(dir, snap.ID), 0755); err != nil {
+			return fmt.Errorf("스냅샷 디렉토리 생성 실패: %w", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, snap.ID, lockFileName), lock, 0644); err != nil {
+			return fmt.Errorf("%s 사본 저장 실패: %w", lockFileName, err)
+		}
+		snap.LockFile = true
+	}
+
+	absDir, _ := normalizePath(baseDir)
+	index.Path = absDir
+	index.Snapshots = append([]*snapshot{snap}, index.Snapshots...)
+	if len(index.Snapshots) > maxSnapshots {
+		for _, old := range index.Snapshots[maxSnapshots:] {
+			os.RemoveAll(filepath.Join(dir, old.ID))
+		}
+		index.Snapshots = index.Snapshots[:maxSnapshots]
+	}
+	if err := writeJSONFile(filepath.Join(dir, snapshotIndexName), index); err != nil {
+		return err
+	}
+	fmt.Printf("ℹ️ 현재 버전(%s)을 기록했습니다. 문제가 생기면 '되돌리기'로 복원할 수 있습니다.\n", snap.shortCommit())
+	return nil
+}
+
+// snapshotBeforeChange는 저장소를 바꾸기 전에 스냅샷을 남깁니다. 실패해도 작업은 계속 진행합니다.
+func snapshotBeforeChange(baseDir, reason string) {
+	if err := takeSnapshot(baseDir, reason); err != nil {
+		fmt.Println("⚠️ 현재 버전을 기록하지 못했습니다 (이번 변경은 되돌리기 목록에 남지 않습니다):", err)
+	}
+}
+
+func (idx *snapshotIndex) find(id string) *snapshot {
+	for _, s := range idx.Snapshots {
+		if s.ID == id {
+			return s
+		}
+	}
+	return nil
+}
+
+// pick은 번호(1부터) 또는 ID로 스냅샷을 찾습니다. 비어 있으면 현재 커밋과 다른 가장 최근 스냅샷을 고릅니다.
+func (idx *snapshotIndex) pick(input, headCommit string) (*snapshot, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		for _, s := range idx.Snapshots {
+			if s.Commit != headCommit {
+				return s, nil
+			}
+		}
+		return nil, fmt.Errorf("현재 버전과 다른 스냅샷이 없습니다")
+	}
+	if n, err := strconv.Atoi(input); err == nil && n >= 1 && n <= len(idx.Snapshots) {
+		return idx.Snapshots[n-1], nil
+	}
+	if s := idx.find(input); s != nil {
+		return s, nil
+	}
+	return nil, fmt.Errorf("'%s'에 해당하는 스냅샷이 없습니다", input)
+}
+
+// printSnapshotList는 스냅샷 목록을 기록 시각과 함께 출력합니다.
+func printSnapshotList(index *snapshotIndex, headCommit string) {
+	if len(index.Snapshots) == 0 {
+		fmt.Println("기록된 이전 버전이 없습니다. (업데이트/브랜치 변경/버전 고정 시 자동으로 기록됩니다)")
+		return
+	}
+	for i, s := range index.Snapshots {
+		where := s.Branch
+		if s.PinRef != "" {
+			where = fmt.Sprintf("%s %s에 고정", pinKindLabel(s.PinKind), s.PinRef)
+		} else if where == "" {
+			where = "Detached HEAD"
+		}
+		marker := ""
+		if s.Commit == headCommit {
+			marker = " (현재 버전)"
+		}
+		fmt.Printf("[%d] %s | %s (%s) | %s | ID: %s%s\n", i+1, s.Created.Local().Format("2006-01-02 15:04:05"), s.shortCommit(), where, s.Reason, s.ID, marker)
+	}
+}
+
+// rollbackTo는 baseDir을 스냅샷의 커밋으로 되돌리고 package-lock.json을 복원한 뒤 npm 패키지를 다시 설치합니다.
+// 되돌리기 직전 상태도 스냅샷으로 남기므로 되돌리기 자체도 취소할 수 있습니다.
+func rollbackTo(baseDir string, target *snapshot) error {
+	if _, err := os.Stat(filepath.Join(baseDir, ".git")); os.IsNotExist(err) {
+		return fmt.Errorf("%w: %s", errNotInstalled, baseDir)
+	}
+	// 되돌리기 직전 스냅샷을 남기면서 오래된 스냅샷이 정리될 수 있으므로 사본을 먼저 읽어 둡니다.
+	var lock []byte
+	if target.LockFile {
+		_, dir, err := loadSnapshots(baseDir)
+		if err != nil {
+			return err
+		}
+		if lock, err = os.ReadFile(filepath.Join(dir, target.ID, lockFileName)); err != nil {
+			fmt.Printf("⚠️ 저장된 %s 사본을 읽지 못했습니다 (저장소의 파일을 사용합니다): %v\n", lockFileName, err)
+			lock = nil
+		}
+	}
+
+	snapshotBeforeChange(baseDir, "되돌리기 이전")
+
+	// npm install이 고친 package-lock.json이 남아 있으면 reset --keep/checkout이 거부하므로 먼저 커밋된 상태로 되돌립니다.
+	// (수정된 사본은 위 스냅샷에 남고, 대상 시점의 사본은 아래에서 다시 씁니다)
+	if err := discardLockFileChanges(baseDir); err != nil {
+		return err
+	}
+
+	if target.Branch != "" {
+		if current, _ := getCurrentGitBranch(baseDir); current != target.Branch {
+			fmt.Printf("브랜치 전환 (git checkout %s)...\n", target.Branch)
+			if err := runGitStep(baseDir, "checkout", target.Branch); err != nil {
+				return err
+			}
+		}
+		fmt.Printf("커밋 %s(으)로 되돌리는 중 (git reset --keep)...\n", target.shortCommit())
+		if err := runGitStep(baseDir, "reset", "--keep", target.Commit); err != nil {
+			fmt.Println("ℹ️ 되돌릴 파일에 로컬 변경사항이 있으면 reset --keep이 거부됩니다. 변경사항을 정리한 뒤 다시 시도해주세요.")
+			return err
+		}
+	} else {
+		fmt.Printf("커밋 %s(으)로 되돌리는 중 (git checkout --detach)...\n", target.shortCommit())
+		if err := runGitStep(baseDir, "checkout", "--detach", target.Commit); err != nil {
+			return err
+		}
+	}
+
+	if lock != nil {
+		if err := os.WriteFile(filepath.Join(baseDir, lockFileName), lock, 0644); err != nil {
+			return fmt.Errorf("%s 복원 실패: %w", lockFileName, err)
+		}
+		fmt.Printf("✅ %s을(를) 업데이트 이전 상태로 복원했습니다.\n", lockFileName)
+	}
+	touchInstance(baseDir, false, func(inst *instance) {
+		inst.PinKind, inst.PinRef = target.PinKind, target.PinRef
+	})
+
+	if err := installSillyTavernDependencies(baseDir); err != nil {
+		return err
+	}
+	recordInstanceUpdate(baseDir)
+	fmt.Printf("\n✅ %s 시점의 버전(%s)으로 되돌렸습니다.\n", target.Created.Local().Format("2006-01-02 15:04:05"), target.shortCommit())
+	if target.PinRef == "" && target.Branch != "" {
+		fmt.Printf("ℹ️ 다음 업데이트 시 다시 최신 버전으로 올라갑니다. 이 버전을 유지하려면 '버전 고정' 메뉴에서 커밋 %s(으)로 고정하세요.\n", target.shortCommit())
+	}
+	return nil
+}
+
+// discardLockFileChanges는 npm이 다시 만든 package-lock.json의 로컬 변경을 버리고 현재 커밋의 내용으로 되돌립니다.
+// 저장소가 package-lock.json을 추적하지 않으면 아무것도 하지 않습니다.
+func discardLockFileChanges(baseDir string) error {
+	out, err := exec.Command(gitExecutablePath, "-C", baseDir, "ls-files", "--", lockFileName).Output()
+	if err != nil {
+		return fmt.Errorf("git ls-files 실패: %w", err)
+	}
+	if strings.TrimSpace(string(out)) == "" {
+		return nil
+	}
+	return runGitStep(baseDir, "checkout", "--", lockFileName)
+}
+
+// runGitStep은 baseDir에서 git 명령을 실행하고, 실패하면 Git 오류 메시지를 포함한 오류를 반환합니다.
+func runGitStep(baseDir string, args ...string) error {
+	cmd := exec.Command(gitExecutablePath, append([]string{"-C", baseDir}, args...)...)
+	var errBuffer bytes.Buffer
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = &errBuffer
+	if err := cmd.Run(); err != nil {
+		errMsg := strings.TrimSpace(errBuffer.String())
+		if strings.Contains(errMsg, "detected dubious ownership") {
+			fmt.Println("\n‼️ Git 소유권 문제 감지됨. `git config --global --add safe.directory ...` 명령을 실행하고 재시도해주세요.")
+		}
+		return fmt.Errorf("git %s 실패: %w\n%s", args[0], err, errMsg)
+	}
+	return nil
+}
+
+// rollbackMenu는 기록된 이전 버전 목록을 보여주고 선택한 버전으로 되돌립니다.
+func rollbackMenu() {
+	fmt.Println("\n[ 이전 버전으로 되돌리기 ]")
+	baseDir := installDir
+	if _, err := os.Stat(filepath.Join(baseDir, ".git")); os.IsNotExist(err) {
+		fmt.Println("❌ 실리태번이 설치되어 있지 않거나 Git 저장소가 아닙니다. 먼저 설치해주세요.")
+		return
+	}
+	index, _, err := loadSnapshots(baseDir)
+	if err != nil {
+		fmt.Println("❌ 스냅샷 목록을 읽지 못했습니다:", err)
+		return
+	}
+	head, _ := gitHeadCommit(baseDir)
+	printSnapshotList(index, head)
+	if len(index.Snapshots) == 0 {
+		return
+	}
+
+	fmt.Print("\n되돌릴 번호 또는 ID를 입력하세요 (비워두면 가장 최근 이전 버전): ")
+	target, err := index.pick(getUserChoice(), head)
+	if err != nil {
+		fmt.Println("❌", err)
+		return
+	}
+	if !confirm(fmt.Sprintf("%s 시점의 버전(%s)으로 되돌리시겠습니까? (y/n): ", target.Created.Local().Format("2006-01-02 15:04:05"), target.shortCommit())) {
+		fmt.Println("되돌리기를 취소했습니다.")
+		return
+	}
+	if err := rollbackTo(baseDir, target); err != nil {
+		fmt.Println("\n❌ 되돌리기를 완료하지 못했습니다:", err)
+	}
+}
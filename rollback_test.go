@@ -0,0 +1,138 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// setupTestEnv는 설치 프로그램 데이터 디렉토리와 Git 설정을 임시 디렉토리로 돌리고, npm 대신 아무것도 하지 않는 스크립트를 씁니다.
+func setupTestEnv(t *testing.T) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("셸 스크립트로 npm을 대신합니다")
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git이 없습니다")
+	}
+	root := t.TempDir()
+	gitConfig := filepath.Join(root, "gitconfig")
+	if err := os.WriteFile(gitConfig, []byte("[user]\n\tname = test\n\temail = test@example.com\n[init]\n\tdefaultBranch = release\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GIT_CONFIG_GLOBAL", gitConfig)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv(installerDataDirEnv, filepath.Join(root, "home"))
+
+	fakeNpm := filepath.Join(root, "npm")
+	if err := os.WriteFile(fakeNpm, []byte("#!/bin/sh\nexit 0\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	savedNpm := npmExecutablePath
+	npmExecutablePath = fakeNpm
+	t.Cleanup(func() { npmExecutablePath = savedNpm })
+}
+
+func gitT(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func writeFileT(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readFileT(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// commitFiles는 files를 쓰고 모두 커밋한 뒤 커밋 해시를 반환합니다.
+func commitFiles(t *testing.T, dir, message string, files map[string]string) string {
+	t.Helper()
+	for name, content := range files {
+		writeFileT(t, filepath.Join(dir, name), content)
+	}
+	gitT(t, dir, "add", "-A")
+	gitT(t, dir, "commit", "-q", "-m", message)
+	return gitT(t, dir, "rev-parse", "HEAD")
+}
+
+func TestRollbackWithModifiedLockFile(t *testing.T) {
+	setupTestEnv(t)
+	dir := filepath.Join(t.TempDir(), "Silly Tavern")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	gitT(t, dir, "init", "-q")
+	first := commitFiles(t, dir, "v1", map[string]string{serverScriptName: "// v1\n", lockFileName: "{\"v\": 1}\n"})
+
+	// npm install이 고친 잠금 파일이 업데이트 이전 스냅샷에 저장됩니다.
+	writeFileT(t, filepath.Join(dir, lockFileName), "{\"v\": 1, \"npm\": true}\n")
+	if err := takeSnapshot(dir, "업데이트 이전"); err != nil {
+		t.Fatal(err)
+	}
+	index, _, err := loadSnapshots(dir)
+	if err != nil || len(index.Snapshots) != 1 || !index.Snapshots[0].LockFile {
+		t.Fatalf("snapshot not recorded with lock file: %+v, %v", index, err)
+	}
+	target := index.Snapshots[0]
+
+	// 업데이트: 새 커밋도 잠금 파일을 바꾸고, 그 뒤 npm install이 다시 고칩니다.
+	gitT(t, dir, "checkout", "-q", "--", lockFileName)
+	commitFiles(t, dir, "v2", map[string]string{serverScriptName: "// v2\n", lockFileName: "{\"v\": 2}\n"})
+	writeFileT(t, filepath.Join(dir, lockFileName), "{\"v\": 2, \"npm\": true}\n")
+
+	if err := rollbackTo(dir, target); err != nil {
+		t.Fatalf("rollbackTo: %v", err)
+	}
+	if head := gitT(t, dir, "rev-parse", "HEAD"); head != first {
+		t.Errorf("HEAD = %s, want %s", head, first)
+	}
+	if got := readFileT(t, filepath.Join(dir, serverScriptName)); got != "// v1\n" {
+		t.Errorf("%s = %q after rollback", serverScriptName, got)
+	}
+	if got := readFileT(t, filepath.Join(dir, lockFileName)); got != "{\"v\": 1, \"npm\": true}\n" {
+		t.Errorf("%s = %q, want the saved copy", lockFileName, got)
+	}
+
+	// 되돌리기 직전의 (npm이 고친) 잠금 파일도 스냅샷으로 남아 되돌리기를 취소할 수 있습니다.
+	index, dir2, err := loadSnapshots(dir)
+	if err != nil || len(index.Snapshots) < 2 {
+		t.Fatalf("pre-rollback snapshot missing: %+v, %v", index, err)
+	}
+	if got := readFileT(t, filepath.Join(dir2, index.Snapshots[0].ID, lockFileName)); got != "{\"v\": 2, \"npm\": true}\n" {
+		t.Errorf("pre-rollback lock copy = %q", got)
+	}
+}
+
+func TestDiscardLockFileChangesUntracked(t *testing.T) {
+	setupTestEnv(t)
+	dir := t.TempDir()
+	gitT(t, dir, "init", "-q")
+	commitFiles(t, dir, "init", map[string]string{serverScriptName: "// v1\n"})
+	writeFileT(t, filepath.Join(dir, lockFileName), "untracked\n")
+	if err := discardLockFileChanges(dir); err != nil {
+		t.Fatalf("discardLockFileChanges: %v", err)
+	}
+	if got := readFileT(t, filepath.Join(dir, lockFileName)); got != "untracked\n" {
+		t.Errorf("untracked lock file changed: %q", got)
+	}
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// semverComparator는 "<op><버전>" 하나를 나타냅니다 (op: >=, >, <=, <, =).
+type semverComparator struct {
+	op      string
+	version [3]int
+}
+
+func (c semverComparator) matches(v [3]int) bool {
+	cmp := compareVersions(v, c.version)
+	switch c.op {
+	case ">=":
+		return cmp >= 0
+	case ">":
+		return cmp > 0
+	case "<=":
+		return cmp <= 0
+	case "<":
+		return cmp < 0
+	default:
+		return cmp == 0
+	}
+}
+
+var semverHyphenRegex = regexp.MustCompile(`^\s*(\S+)\s+-\s+(\S+)\s*$`)
+
+// satisfiesRange는 버전 v가 npm 형식의 semver 범위(package.json의 engines.node 등)를 만족하는지 확인합니다.
+// "||"(또는), 공백(그리고), >=, >, <=, <, =, ^, ~, x 범위(18.x), 하이픈 범위(18 - 20)를 지원합니다.
+func satisfiesRange(v [3]int, rng string) (bool, error) {
+	rng = strings.TrimSpace(rng)
+	if rng == "" || rng == "*" {
+		return true, nil
+	}
+	for _, alternative := range strings.Split(rng, "||") {
+		comparators, err := parseComparatorSet(alternative)
+		if err != nil {
+			return false, fmt.Errorf("버전 범위 '%s' 해석 실패: %w", rng, err)
+		}
+		matched := true
+		for _, c := range comparators {
+			if !c.matches(v) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// parseComparatorSet은 공백으로 구분된 조건 묶음(모두 만족해야 함)을 비교 조건 목록으로 바꿉니다.
+func parseComparatorSet(set string) ([]semverComparator, error) {
+	if m := semverHyphenRegex.FindStringSubmatch(set); m != nil {
+		lower, _, err := parsePartialVersion(m[1])
+		if err != nil {
+			return nil, err
+		}
+		upper, n, err := parsePartialVersion(m[2])
+		if err != nil {
+			return nil, err
+		}
+		if n < 3 && n > 0 {
+			return []semverComparator{{">=", lower}, {"<", bumpVersion(upper, n-1)}}, nil
+		}
+		if n == 0 {
+			return []semverComparator{{">=", lower}}, nil
+		}
+		return []semverComparator{{">=", lower}, {"<=", upper}}, nil
+	}
+
+	// ">= 18"처럼 연산자와 버전 사이에 공백이 있어도 하나로 묶습니다.
+	var tokens []string
+	pending := ""
+	for _, field := range strings.Fields(set) {
+		if strings.Trim(field, "<>=~^") == "" {
+			pending += field
+			continue
+		}
+		tokens = append(tokens, pending+field)
+		pending = ""
+	}
+	if pending != "" {
+		return nil, fmt.Errorf("연산자 '%s' 뒤에 버전이 없습니다", pending)
+	}
+
+	var result []semverComparator
+	for _, token := range tokens {
+		comparators, err := parseComparator(token)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, comparators...)
+	}
+	return result, nil
+}
+
+// parseComparator는 "^18.0.0", ">=18", "18.x" 같은 조건 하나를 기본 비교 조건으로 풀어 씁니다.
+func parseComparator(token string) ([]semverComparator, error) {
+	op := ""
+	for _, prefix := range []string{">=", "<=", ">", "<", "=", "^", "~"} {
+		if strings.HasPrefix(token, prefix) {
+			op = prefix
+			break
+		}
+	}
+	version, n, err := parsePartialVersion(strings.TrimPrefix(token, op))
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		if op == "<" || op == ">" {
+			return nil, fmt.Errorf("'%s'은(는) 만족할 수 없는 조건입니다", token)
+		}
+		return nil, nil // "*", "x": 모든 버전
+	}
+
+	switch op {
+	case ">=":
+		return []semverComparator{{">=", version}}, nil
+	case ">":
+		if n < 3 { // ">18"은 ">=19.0.0"과 같습니다.
+			return []semverComparator{{">=", bumpVersion(version, n-1)}}, nil
+		}
+		return []semverComparator{{">", version}}, nil
+	case "<":
+		return []semverComparator{{"<", version}}, nil
+	case "<=":
+		if n < 3 { // "<=18"은 "<19.0.0"과 같습니다.
+			return []semverComparator{{"<", bumpVersion(version, n-1)}}, nil
+		}
+		return []semverComparator{{"<=", version}}, nil
+	case "^":
+		// 0이 아닌 가장 왼쪽 자리가 바뀌지 않는 범위입니다 (^18.2 -> >=18.2.0 <19.0.0).
+		idx := 0
+		if version[0] == 0 && n > 1 {
+			idx = 1
+			if version[1] == 0 && n > 2 {
+				idx = 2
+			}
+		}
+		return []semverComparator{{">=", version}, {"<", bumpVersion(version, idx)}}, nil
+	case "~":
+		idx := 1
+		if n == 1 {
+			idx = 0
+		}
+		return []semverComparator{{">=", version}, {"<", bumpVersion(version, idx)}}, nil
+	default: // "=" 또는 연산자 없음
+		if n == 3 {
+			return []semverComparator{{"=", version}}, nil
+		}
+		return []semverComparator{{">=", version}, {"<", bumpVersion(version, n-1)}}, nil
+	}
+}
+
+// parsePartialVersion은 "18", "18.2", "18.x", "v18.2.0-rc.1" 같은 버전을 해석합니다.
+// n은 명시된 자릿수(와일드카드 앞까지)이며, 생략된 자리는 0으로 채웁니다.
+func parsePartialVersion(s string) (version [3]int, n int, err error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if i := strings.IndexAny(s, "-+"); i >= 0 {
+		s = s[:i] // 프리릴리스/빌드 정보는 비교하지 않습니다.
+	}
+	if s == "" || s == "*" {
+		return version, 0, nil
+	}
+	parts := strings.Split(s, ".")
+	if len(parts) > 3 {
+		return version, 0, fmt.Errorf("잘못된 버전 형식입니다: %s", s)
+	}
+	for i, part := range parts {
+		if part == "x" || part == "X" || part == "*" {
+			break
+		}
+		num, errNum := strconv.Atoi(part)
+		if errNum != nil || num < 0 {
+			return version, 0, fmt.Errorf("잘못된 버전 형식입니다: %s", s)
+		}
+		version[i] = num
+		n = i + 1
+	}
+	return version, n, nil
+}
+
+// bumpVersion은 idx 자리를 1 올리고 그 아래 자리를 0으로 만듭니다.
+func bumpVersion(v [3]int, idx int) [3]int {
+	v[idx]++
+	for i := idx + 1; i < 3; i++ {
+		v[i] = 0
+	}
+	return v
+}
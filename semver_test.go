@@ -0,0 +1,86 @@
+package main
+
+import "testing"
+
+func TestSatisfiesRange(t *testing.T) {
+	tests := []struct {
+		rng string
+		yes []string
+		no  []string
+	}{
+		// 캐럿: 0이 아닌 가장 왼쪽 자리까지 고정
+		{"^1.2.3", []string{"1.2.3", "1.9.9"}, []string{"1.2.2", "2.0.0"}},
+		{"^18", []string{"18.0.0", "18.20.4"}, []string{"17.9.9", "19.0.0"}},
+		{"^1.x", []string{"1.0.0", "1.99.0"}, []string{"0.9.9", "2.0.0"}},
+		{"^0.2.3", []string{"0.2.3", "0.2.9"}, []string{"0.2.2", "0.3.0", "1.0.0"}},
+		{"^0.0.3", []string{"0.0.3"}, []string{"0.0.2", "0.0.4", "0.1.0"}},
+		{"^0.0", []string{"0.0.0", "0.0.9"}, []string{"0.1.0"}},
+		{"^0", []string{"0.0.0", "0.9.9"}, []string{"1.0.0"}},
+		{"^0.x", []string{"0.0.1", "0.9.0"}, []string{"1.0.0"}},
+		// 틸드: 부 버전까지 고정 (주 버전만 있으면 주 버전 고정)
+		{"~1.2.3", []string{"1.2.3", "1.2.9"}, []string{"1.2.2", "1.3.0"}},
+		{"~1.2", []string{"1.2.0", "1.2.9"}, []string{"1.1.9", "1.3.0"}},
+		{"~1", []string{"1.0.0", "1.9.9"}, []string{"2.0.0"}},
+		{"~0.2.3", []string{"0.2.3", "0.2.9"}, []string{"0.3.0"}},
+		// x 범위
+		{"18.x", []string{"18.0.0", "18.9.9"}, []string{"17.9.9", "19.0.0"}},
+		{"18.2.x", []string{"18.2.0", "18.2.9"}, []string{"18.1.9", "18.3.0"}},
+		{"18.X", []string{"18.5.0"}, []string{"19.0.0"}},
+		{"18", []string{"18.0.0", "18.9.9"}, []string{"19.0.0"}},
+		{"*", []string{"0.0.0", "22.2.0"}, nil},
+		{"x", []string{"22.2.0"}, nil},
+		{"", []string{"22.2.0"}, nil},
+		// 하이픈 범위: 뒤쪽이 일부만 있으면 그 자리까지 포함
+		{"18 - 20", []string{"18.0.0", "20.9.9"}, []string{"17.9.9", "21.0.0"}},
+		{"18.1 - 20.2", []string{"18.1.0", "20.2.9"}, []string{"18.0.9", "20.3.0"}},
+		{"18.0.0 - 20.1.2", []string{"20.1.2"}, []string{"20.1.3"}},
+		{"18 - x", []string{"18.0.0", "99.0.0"}, []string{"17.0.0"}},
+		// 비교 연산자와 공백
+		{">= 18", []string{"18.0.0", "22.0.0"}, []string{"17.9.9"}},
+		{">=18 <21", []string{"18.0.0", "20.9.9"}, []string{"21.0.0"}},
+		{">= 18.0.0 < 21", []string{"20.9.9"}, []string{"21.0.0"}},
+		{"> 18", []string{"19.0.0"}, []string{"18.9.9"}},
+		{">18.2.0", []string{"18.2.1"}, []string{"18.2.0"}},
+		{"<=18", []string{"18.9.9"}, []string{"19.0.0"}},
+		{"<=18.2.0", []string{"18.2.0"}, []string{"18.2.1"}},
+		{"<18", []string{"17.9.9"}, []string{"18.0.0"}},
+		{"=18.2.0", []string{"18.2.0"}, []string{"18.2.1"}},
+		{"v18.2.0", []string{"18.2.0"}, []string{"18.2.1"}},
+		// 또는
+		{"^16 || ^18 || >=20", []string{"16.0.0", "18.5.0", "22.0.0"}, []string{"15.9.9", "17.0.0", "19.9.9"}},
+		{"18.x||20.x", []string{"18.1.0", "20.1.0"}, []string{"19.0.0"}},
+		// 프리릴리스 표기는 무시하고 정식 버전끼리 비교
+		{">=20.0.0-rc.1", []string{"20.0.0", "21.0.0"}, []string{"19.9.9"}},
+		{"^18.0.0-pre", []string{"18.0.0", "18.5.0"}, []string{"19.0.0"}},
+		{"<18.0.0-rc.1", []string{"17.9.9"}, []string{"18.0.0"}},
+		{"18.0.0+build.5", []string{"18.0.0"}, []string{"18.0.1"}},
+	}
+	for _, tt := range tests {
+		for _, want := range []bool{true, false} {
+			versions := tt.yes
+			if !want {
+				versions = tt.no
+			}
+			for _, s := range versions {
+				v, ok := parseVersion(s)
+				if !ok {
+					t.Fatalf("parseVersion(%q) failed", s)
+				}
+				got, err := satisfiesRange(v, tt.rng)
+				if err != nil {
+					t.Errorf("satisfiesRange(%s, %q) error: %v", s, tt.rng, err)
+				} else if got != want {
+					t.Errorf("satisfiesRange(%s, %q) = %v, want %v", s, tt.rng, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestSatisfiesRangeInvalid(t *testing.T) {
+	for _, rng := range []string{">=", "^", "abc", "1.2.3.4", ">18 ||<", "<*", ">x", "18.a"} {
+		if _, err := satisfiesRange([3]int{18, 0, 0}, rng); err == nil {
+			t.Errorf("satisfiesRange(%q) accepted an invalid range", rng)
+		}
+	}
+}
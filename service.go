@@ -0,0 +1,291 @@
+package main
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+const (
+	serviceNamePrefix  = "sillytavern-"
+	serviceTaskFolder  = `\SillyTavern\` // Windows 작업 스케줄러에서 작업을 모아 두는 폴더
+	serviceRestartSec  = 5               // systemd가 비정상 종료 후 다시 시작하기까지 기다리는 초
+	serviceDirName     = "service"       // 설치 프로그램 데이터 디렉토리에 작업 정의(XML)를 보관하는 폴더
+	systemdSystemDir   = "/etc/systemd/system"
+	serviceDescription = "SillyTavern"
+)
+
+// serviceSpec은 서비스(systemd 유닛 / Windows 예약 작업)로 등록할 SillyTavern 인스턴스 정보입니다.
+type serviceSpec struct {
+	Name      string // 유닛/작업 이름 (인스턴스마다 다름)
+	Dir       string // SillyTavern 설치 경로
+	NodePath  string // 사용할 node 실행 파일의 절대 경로
+	Path      string // 서비스 환경의 PATH (node 디렉토리가 맨 앞)
+	System    bool   // Linux: 시스템 유닛(/etc/systemd/system), Windows: 로그온 없이 부팅 시 실행
+	User      string // 실행할 사용자
+	Installer string // 설치 프로그램 실행 파일 (Windows에서 감시 프로세스로 실행)
+	DataDir   string // 설치 프로그램 데이터 디렉토리 (Windows 감시 프로세스에 전달)
+}
+
+// serviceName은 baseDir 인스턴스의 유닛/작업 이름입니다. systemd 유닛 이름에 쓸 수 없는 문자는 '-'로 바꿉니다.
+func serviceName(baseDir string) (string, error) {
+	key, err := instanceKey(baseDir)
+	if err != nil {
+		return "", err
+	}
+	name := strings.Map(func(r rune) rune {
+		if r < 128 && (r == '-' || r == '_' || r == '.' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z') {
+			return r
+		}
+		return '-'
+	}, key)
+	return serviceNamePrefix + name, nil
+}
+
+// newServiceSpec은 baseDir 인스턴스를 서비스로 등록하기 위한 정보를 모읍니다.
+// 인스턴스 설정에 맞는 Node.js(시스템/인스턴스 전용)의 절대 경로를 사용합니다.
+func newServiceSpec(baseDir string, system bool) (*serviceSpec, error) {
+	absDir, err := normalizePath(baseDir)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(filepath.Join(absDir, serverScriptName)); err != nil {
+		return nil, fmt.Errorf("%w: %s에 %s가 없습니다. 먼저 설치해주세요.", errNotInstalled, absDir, serverScriptName)
+	}
+	if err := applyNodeRuntime(absDir); err != nil {
+		return nil, err
+	}
+	nodePath, err := exec.LookPath(nodeExecutablePath)
+	if err != nil {
+		return nil, fmt.Errorf("node 실행 파일(%s)을 찾을 수 없습니다: %w", nodeExecutablePath, err)
+	}
+	if nodePath, err = filepath.Abs(nodePath); err != nil {
+		return nil, fmt.Errorf("node 경로 확인 실패: %w", err)
+	}
+	name, err := serviceName(absDir)
+	if err != nil {
+		return nil, err
+	}
+	installer, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("설치 프로그램 경로 확인 실패: %w", err)
+	}
+	dataDir, err := installerDataDir()
+	if err != nil {
+		return nil, err
+	}
+	if runtime.GOOS == "windows" {
+		system = isAdmin // 부팅 시 실행하는 작업은 관리자만 등록할 수 있음
+	}
+	return &serviceSpec{
+		Name:      name,
+		Dir:       absDir,
+		NodePath:  nodePath,
+		Path:      filepath.Dir(nodePath) + string(os.PathListSeparator) + os.Getenv("PATH"),
+		System:    system,
+		User:      serviceUser(),
+		Installer: installer,
+		DataDir:   dataDir,
+	}, nil
+}
+
+// serviceUser는 서비스를 실행할 사용자입니다. sudo로 실행했으면 sudo를 실행한 원래 사용자입니다.
+func serviceUser() string {
+	if sudoUser := os.Getenv("SUDO_USER"); sudoUser != "" && isAdmin {
+		return sudoUser
+	}
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
+
+// systemdUnitDir은 유닛 파일을 둘 디렉토리입니다. 사용자 유닛은 ~/.config/systemd/user 입니다.
+func systemdUnitDir(system bool) (string, error) {
+	if system {
+		return systemdSystemDir, nil
+	}
+	configDir := os.Getenv("XDG_CONFIG_HOME")
+	if configDir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("홈 디렉토리 확인 실패: %w", err)
+		}
+		configDir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(configDir, "systemd", "user"), nil
+}
+
+// systemdQuote는 유닛 파일의 큰따옴표 값으로 쓸 수 있도록 \, ", %를 이스케이프합니다.
+func systemdQuote(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "%", "%%").Replace(s)
+	return `"` + s + `"`
+}
+
+// renderSystemdUnit은 svc를 실행하는 systemd 유닛 파일 내용을 만듭니다. node가 비정상 종료하면 systemd가 다시 시작합니다.
+func renderSystemdUnit(svc *serviceSpec) string {
+	var b strings.Builder
+	fmt.Fprintln(&b, "# SillyTavern Installer가 만든 파일입니다. 'service uninstall'로 제거할 수 있습니다.")
+	fmt.Fprintln(&b, "[Unit]")
+	fmt.Fprintf(&b, "Description=%s (%s)\n", serviceDescription, strings.ReplaceAll(svc.Dir, "%", "%%"))
+	if svc.System {
+		// 사용자 유닛에서는 network-online.target을 기다릴 수 없음
+		fmt.Fprintln(&b, "Wants=network-online.target")
+		fmt.Fprintln(&b, "After=network-online.target")
+	}
+	fmt.Fprintln(&b)
+	fmt.Fprintln(&b, "[Service]")
+	fmt.Fprintln(&b, "Type=simple")
+	if svc.System && svc.User != "" {
+		fmt.Fprintf(&b, "User=%s\n", svc.User)
+	}
+	fmt.Fprintf(&b, "WorkingDirectory=%s\n", strings.ReplaceAll(svc.Dir, "%", "%%"))
+	fmt.Fprintf(&b, "ExecStart=%s %s\n", systemdQuote(svc.NodePath), serverScriptName)
+	fmt.Fprintf(&b, "Environment=%s\n", systemdQuote("PATH="+svc.Path))
+	fmt.Fprintln(&b, "Environment=NODE_ENV=production")
+	fmt.Fprintln(&b, "Restart=on-failure")
+	fmt.Fprintf(&b, "RestartSec=%d\n", serviceRestartSec)
+	fmt.Fprintln(&b)
+	fmt.Fprintln(&b, "[Install]")
+	if svc.System {
+		fmt.Fprintln(&b, "WantedBy=multi-user.target")
+	} else {
+		fmt.Fprintln(&b, "WantedBy=default.target")
+	}
+	return b.String()
+}
+
+func xmlEscape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// renderTaskXML은 svc를 실행하는 Windows 작업 스케줄러 작업 정의(XML)를 만듭니다.
+// 작업은 설치 프로그램의 감시 프로세스를 실행하므로, 비정상 종료 시 재시작과 로그 기록은 백그라운드 실행과 같습니다.
+// System이면 로그온하지 않아도 부팅 시 실행하고(S4U), 아니면 사용자가 로그온할 때 실행합니다.
+func renderTaskXML(svc *serviceSpec) string {
+	args := fmt.Sprintf(`%s --dir "%s" --node "%s" --home "%s"`, supervisorCommand, svc.Dir, svc.NodePath, svc.DataDir)
+	trigger := "    <BootTrigger>\n      <Enabled>true</Enabled>\n    </BootTrigger>\n"
+	logonType := "S4U"
+	if !svc.System {
+		trigger = fmt.Sprintf("    <LogonTrigger>\n      <Enabled>true</Enabled>\n      <UserId>%s</UserId>\n    </LogonTrigger>\n", xmlEscape(svc.User))
+		logonType = "InteractiveToken"
+	}
+
+	var b strings.Builder
+	fmt.Fprintln(&b, `<?xml version="1.0" encoding="UTF-16"?>`)
+	fmt.Fprintln(&b, `<Task version="1.2" xmlns="http://schemas.microsoft.com/windows/2004/02/mit/task">`)
+	fmt.Fprintf(&b, "  <RegistrationInfo>\n    <Description>%s</Description>\n  </RegistrationInfo>\n", xmlEscape(serviceDescription+" ("+svc.Dir+")"))
+	fmt.Fprintf(&b, "  <Triggers>\n%s  </Triggers>\n", trigger)
+	fmt.Fprintf(&b, "  <Principals>\n    <Principal id=\"Author\">\n      <UserId>%s</UserId>\n      <LogonType>%s</LogonType>\n      <RunLevel>LeastPrivilege</RunLevel>\n    </Principal>\n  </Principals>\n", xmlEscape(svc.User), logonType)
+	fmt.Fprintln(&b, "  <Settings>")
+	fmt.Fprintln(&b, "    <MultipleInstancesPolicy>IgnoreNew</MultipleInstancesPolicy>")
+	fmt.Fprintln(&b, "    <DisallowStartIfOnBatteries>false</DisallowStartIfOnBatteries>")
+	fmt.Fprintln(&b, "    <StopIfGoingOnBatteries>false</StopIfGoingOnBatteries>")
+	fmt.Fprintln(&b, "    <ExecutionTimeLimit>PT0S</ExecutionTimeLimit>") // 기본값(72시간)이면 작업이 강제 종료됨
+	fmt.Fprintln(&b, "    <RestartOnFailure>\n      <Interval>PT1M</Interval>\n      <Count>3</Count>\n    </RestartOnFailure>")
+	fmt.Fprintln(&b, "    <Enabled>true</Enabled>")
+	fmt.Fprintln(&b, "  </Settings>")
+	fmt.Fprintln(&b, `  <Actions Context="Author">`)
+	fmt.Fprintf(&b, "    <Exec>\n      <Command>%s</Command>\n      <Arguments>%s</Arguments>\n      <WorkingDirectory>%s</WorkingDirectory>\n    </Exec>\n",
+		xmlEscape(svc.Installer), xmlEscape(args), xmlEscape(svc.Dir))
+	fmt.Fprintln(&b, "  </Actions>")
+	fmt.Fprintln(&b, "</Task>")
+	return b.String()
+}
+
+// renderServiceDefinition은 현재 운영체제에서 사용할 서비스 정의(유닛 파일 또는 작업 XML)를 만듭니다.
+func renderServiceDefinition(svc *serviceSpec) string {
+	if runtime.GOOS == "windows" {
+		return renderTaskXML(svc)
+	}
+	return renderSystemdUnit(svc)
+}
+
+// installService는 baseDir 인스턴스를 서비스로 등록합니다. enable이 false이면 정의 파일만 작성하고 등록/시작하지 않습니다.
+func installService(baseDir string, system, enable bool) error {
+	if runtime.GOOS == "darwin" {
+		return fmt.Errorf("macOS에서는 서비스 등록을 지원하지 않습니다. 'start --background'를 사용해주세요")
+	}
+	svc, err := newServiceSpec(baseDir, system)
+	if err != nil {
+		return err
+	}
+	if enable {
+		if state, err := loadServerState(svc.Dir); err == nil && state != nil {
+			fmt.Println("ℹ️ 설치 프로그램으로 실행 중인 SillyTavern을 먼저 중지합니다 (서비스로 다시 시작).")
+			if err := stopServer(svc.Dir); err != nil {
+				return err
+			}
+		}
+	}
+	path, err := host.installService(svc, enable)
+	if err != nil {
+		return err
+	}
+	if !enable {
+		fmt.Printf("✅ 서비스 정의를 작성했습니다 (등록/시작하지 않음): %s\n", path)
+		return nil
+	}
+	fmt.Printf("✅ SillyTavern을 서비스(%s)로 등록했습니다. 부팅 후 자동으로 실행됩니다.\n", svc.Name)
+	fmt.Printf("   정의 파일: %s\n", path)
+	return nil
+}
+
+// uninstallService는 baseDir 인스턴스의 서비스를 중지하고 등록을 해제합니다.
+func uninstallService(baseDir string) error {
+	name, err := serviceName(baseDir)
+	if err != nil {
+		return err
+	}
+	if err := host.uninstallService(name); err != nil {
+		return err
+	}
+	if runtime.GOOS == "windows" {
+		// 작업이 실행한 감시 프로세스도 중지합니다.
+		if err := stopServer(baseDir); err != nil && !errors.Is(err, errServerNotRunning) {
+			fmt.Println("⚠️ 실행 중인 SillyTavern 중지 실패:", err)
+		}
+	}
+	fmt.Printf("✅ 서비스(%s) 등록을 해제했습니다.\n", name)
+	return nil
+}
+
+// serviceMenu는 메뉴의 '부팅 시 자동 실행' 항목입니다.
+func serviceMenu() {
+	name, err := serviceName(installDir)
+	if err != nil {
+		fmt.Println("❌", err)
+		return
+	}
+	fmt.Println("\n[ 부팅 시 자동 실행 (서비스) ]")
+	fmt.Printf("서비스 이름: %s\n", name)
+	fmt.Println("\n1. 서비스로 등록 (부팅 시 자동 실행, 비정상 종료 시 재시작)")
+	fmt.Println("2. 서비스 등록 해제")
+	fmt.Println("3. 서비스 상태 보기")
+	fmt.Println("4. 돌아가기")
+	fmt.Print("\n선택하세요 (1-4): ")
+
+	switch getUserChoice() {
+	case "1":
+		system := false
+		if runtime.GOOS == "linux" && isAdmin {
+			system = confirm("시스템 서비스(/etc/systemd/system)로 등록하시겠습니까? n이면 사용자 서비스로 등록합니다. (y/n): ")
+		}
+		err = installService(installDir, system, true)
+	case "2":
+		err = uninstallService(installDir)
+	case "3":
+		err = host.serviceStatus(name)
+	}
+	if err != nil {
+		fmt.Println("❌", err)
+	}
+}
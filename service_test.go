@@ -0,0 +1,143 @@
+package main
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestSystemdQuote(t *testing.T) {
+	tests := map[string]string{
+		`/opt/node js/bin/node`: `"/opt/node js/bin/node"`,
+		`/srv/100% done`:        `"/srv/100%% done"`,
+		`a"b\c`:                 `"a\"b\\c"`,
+	}
+	for in, want := range tests {
+		if got := systemdQuote(in); got != want {
+			t.Errorf("systemdQuote(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
+
+func TestRenderSystemdUnitEscapesPaths(t *testing.T) {
+	svc := &serviceSpec{
+		Name:     "sillytavern-my-st-100--1234abcd",
+		Dir:      "/srv/my st/100% ready",
+		NodePath: "/opt/node js/bin/node",
+		Path:     "/opt/node js/bin:/usr/bin",
+		System:   true,
+		User:     "tavern",
+	}
+	unit := renderSystemdUnit(svc)
+	for _, want := range []string{
+		"Description=SillyTavern (/srv/my st/100%% ready)\n",
+		"WorkingDirectory=/srv/my st/100%% ready\n",
+		`ExecStart="/opt/node js/bin/node" server.js` + "\n",
+		`Environment="PATH=/opt/node js/bin:/usr/bin"` + "\n",
+		"User=tavern\n",
+		"After=network-online.target\n",
+		"WantedBy=multi-user.target\n",
+	} {
+		if !strings.Contains(unit, want) {
+			t.Errorf("unit is missing %q:\n%s", want, unit)
+		}
+	}
+	if strings.Contains(strings.ReplaceAll(unit, "%%", ""), "%") {
+		t.Errorf("unit has an unescaped %%:\n%s", unit)
+	}
+
+	svc.System = false
+	unit = renderSystemdUnit(svc)
+	if strings.Contains(unit, "User=") || strings.Contains(unit, "network-online") || !strings.Contains(unit, "WantedBy=default.target\n") {
+		t.Errorf("user unit has system-only settings:\n%s", unit)
+	}
+}
+
+func TestRenderTaskXMLEscapesPaths(t *testing.T) {
+	svc := &serviceSpec{
+		Dir:       `C:\Users\Kim\My ST & <Test> 100%`,
+		NodePath:  `C:\Program Files\nodejs\node.exe`,
+		User:      `PC\Kim`,
+		Installer: `C:\Tools\SillyTavernInstaller.exe`,
+		DataDir:   `C:\Users\Kim\AppData\Roaming\SillyTavernInstaller`,
+	}
+	var task struct {
+		Exec struct {
+			Command          string
+			Arguments        string
+			WorkingDirectory string
+		} `xml:"Actions>Exec"`
+		LogonType string `xml:"Principals>Principal>LogonType"`
+	}
+	// encoding/xml은 UTF-16 선언을 해석하지 못하므로 선언을 빼고 확인합니다.
+	doc := renderTaskXML(svc)
+	if err := xml.Unmarshal([]byte(doc[strings.Index(doc, "<Task"):]), &task); err != nil {
+		t.Fatalf("task XML does not parse: %v\n%s", err, doc)
+	}
+	wantArgs := `supervise --dir "C:\Users\Kim\My ST & <Test> 100%" --node "C:\Program Files\nodejs\node.exe" --home "C:\Users\Kim\AppData\Roaming\SillyTavernInstaller"`
+	if task.Exec.Arguments != wantArgs {
+		t.Errorf("Arguments = %s\nwant        %s", task.Exec.Arguments, wantArgs)
+	}
+	if task.Exec.Command != svc.Installer || task.Exec.WorkingDirectory != svc.Dir {
+		t.Errorf("Command = %q, WorkingDirectory = %q", task.Exec.Command, task.Exec.WorkingDirectory)
+	}
+	if task.LogonType != "InteractiveToken" {
+		t.Errorf("LogonType = %q, want InteractiveToken for a user task", task.LogonType)
+	}
+}
+
+func TestInstallServiceNoEnableWritesUserUnit(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("systemd 유닛은 Linux에서만 작성합니다")
+	}
+	root := t.TempDir()
+	t.Setenv(installerDataDirEnv, filepath.Join(root, "home"))
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(root, "xdg config"))
+
+	baseDir := filepath.Join(root, "my st", "100% ready")
+	if err := os.MkdirAll(baseDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(baseDir, serverScriptName), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	nodePath := filepath.Join(root, "node js", "node")
+	if err := os.MkdirAll(filepath.Dir(nodePath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(nodePath, []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	saved := nodeExecutablePath
+	nodeExecutablePath = nodePath
+	t.Cleanup(func() { nodeExecutablePath = saved })
+
+	if err := installService(baseDir, false, false); err != nil {
+		t.Fatalf("installService: %v", err)
+	}
+	name, err := serviceName(baseDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.ContainsAny(name, " %") {
+		t.Errorf("service name %q contains characters systemd does not accept", name)
+	}
+	unitPath := filepath.Join(root, "xdg config", "systemd", "user", name+".service")
+	data, err := os.ReadFile(unitPath)
+	if err != nil {
+		t.Fatalf("unit file was not written: %v", err)
+	}
+	unit := string(data)
+	for _, want := range []string{
+		"WorkingDirectory=" + strings.ReplaceAll(baseDir, "%", "%%") + "\n",
+		"ExecStart=" + systemdQuote(nodePath) + " server.js\n",
+		"WantedBy=default.target\n",
+	} {
+		if !strings.Contains(unit, want) {
+			t.Errorf("unit is missing %q:\n%s", want, unit)
+		}
+	}
+}
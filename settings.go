@@ -0,0 +1,237 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	installerDirName     = "SillyTavernInstaller"
+	settingsFileName     = "settings.json"
+	installDirEnv        = "SILLYTAVERN_DIR"  // SillyTavern 설치 경로 지정용 환경 변수
+	installerDataDirEnv  = "STINSTALLER_HOME" // 설치 프로그램 데이터 디렉토리 지정용 환경 변수
+	installDirSourceFlag = "--dir 옵션"
+	installDirSourceEnv  = installDirEnv + " 환경 변수"
+	installDirSourcePref = "저장된 설정"
+	installDirSourceDef  = "기본값"
+)
+
+// installerSettings는 설치 프로그램 자체의 설정입니다 (SillyTavern의 config.yaml과는 별개).
+type installerSettings struct {
+	InstallDir string `json:"installDir,omitempty"`
+	GitPath    string `json:"gitPath,omitempty"` // 설치 프로그램 데이터 디렉토리에 내려받은 portable Git 실행 파일 경로
+
+	// SillyTavern 로그 교체/정리 기준 (0이면 기본값)
+	LogMaxSizeMB  int `json:"logMaxSizeMB,omitempty"`
+	LogMaxFiles   int `json:"logMaxFiles,omitempty"`
+	LogMaxAgeDays int `json:"logMaxAgeDays,omitempty"`
+}
+
+// installerDataDir는 설치 프로그램의 설정/상태 파일을 보관하는 디렉토리를 반환합니다.
+// STINSTALLER_HOME 환경 변수가 있으면 그 경로를, 없으면 OS별 사용자 설정 디렉토리를 사용합니다.
+func installerDataDir() (string, error) {
+	if dir := strings.TrimSpace(os.Getenv(installerDataDirEnv)); dir != "" {
+		return normalizePath(dir)
+	}
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("사용자 설정 디렉토리 확인 실패: %w", err)
+	}
+	return filepath.Join(configDir, installerDirName), nil
+}
+
+func settingsFilePath() (string, error) {
+	dataDir, err := installerDataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dataDir, settingsFileName), nil
+}
+
+// loadSettings는 저장된 설정을 읽습니다. 설정 파일이 없으면 빈 설정을 반환합니다.
+func loadSettings() (*installerSettings, error) {
+	settings := &installerSettings{}
+	path, err := settingsFilePath()
+	if err != nil {
+		return settings, err
+	}
+	if err := readJSONFile(path, settings); err != nil {
+		return &installerSettings{}, err
+	}
+	return settings, nil
+}
+
+func saveSettings(settings *installerSettings) error {
+	path, err := settingsFilePath()
+	if err != nil {
+		return err
+	}
+	return writeJSONFile(path, settings)
+}
+
+// readJSONFile은 path의 JSON을 v로 읽습니다. 파일이 없으면 v를 그대로 두고 nil을 반환합니다.
+func readJSONFile(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("'%s' 파일 읽기 실패: %w", path, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("'%s' 파싱 실패: %w", path, err)
+	}
+	return nil
+}
+
+// writeJSONFile은 v를 임시 파일에 쓴 뒤 교체하여, 쓰기 도중 실패해도 기존 파일이 깨지지 않도록 합니다.
+func writeJSONFile(path string, v interface{}) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("디렉토리(%s) 생성 실패: %w", filepath.Dir(path), err)
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("'%s' 직렬화 실패: %w", path, err)
+	}
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("'%s' 파일 쓰기 실패: %w", tmpPath, err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("'%s' 파일 교체 실패: %w", path, err)
+	}
+	return nil
+}
+
+// normalizePath는 사용자가 입력한 경로의 따옴표와 '~'를 정리하고 절대 경로로 변환합니다.
+func normalizePath(path string) (string, error) {
+	path = strings.TrimSpace(path)
+	path = strings.Trim(path, `"'`)
+	if path == "" {
+		return "", fmt.Errorf("경로가 비어 있습니다")
+	}
+	if path == "~" || strings.HasPrefix(path, "~/") || strings.HasPrefix(path, `~\`) {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("홈 디렉토리 확인 실패: %w", err)
+		}
+		path = filepath.Join(home, path[1:])
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("절대 경로 변환 실패 (%s): %w", path, err)
+	}
+	return absPath, nil
+}
+
+// resolveInstallDir는 SillyTavern 설치 경로를
+// --dir 옵션 > SILLYTAVERN_DIR 환경 변수 > 선택된 인스턴스 > 저장된 설정 > 기본값(현재 디렉토리의 SillyTavern) 순으로 결정합니다.
+func resolveInstallDir(flagValue string, registry *instanceRegistry) (dir, source string, err error) {
+	type candidate struct{ value, source string }
+	candidates := []candidate{
+		{flagValue, installDirSourceFlag},
+		{os.Getenv(installDirEnv), installDirSourceEnv},
+	}
+	if active := registry.find(registry.Active); active != nil {
+		candidates = append(candidates, candidate{active.Path, fmt.Sprintf("선택된 인스턴스 '%s'", active.Name)})
+	}
+	if settings, errSettings := loadSettings(); errSettings != nil {
+		fmt.Printf("⚠️ 설치 프로그램 설정을 읽지 못했습니다: %v\n", errSettings)
+	} else {
+		candidates = append(candidates, candidate{settings.InstallDir, installDirSourcePref})
+	}
+	candidates = append(candidates, candidate{defaultBaseDir, installDirSourceDef})
+
+	for _, c := range candidates {
+		if strings.TrimSpace(c.value) == "" {
+			continue
+		}
+		dir, err := normalizePath(c.value)
+		if err != nil {
+			return "", c.source, fmt.Errorf("%s의 설치 경로가 올바르지 않습니다: %w", c.source, err)
+		}
+		return dir, c.source, nil
+	}
+	return "", installDirSourceDef, fmt.Errorf("설치 경로를 결정할 수 없습니다")
+}
+
+// saveInstallDirPreference는 설치 경로를 다음 실행에도 사용하도록 저장합니다.
+// 경로를 직접 지정하면 인스턴스 선택은 해제됩니다.
+func saveInstallDirPreference(dir string) error {
+	settings, err := loadSettings()
+	if err != nil {
+		return err
+	}
+	settings.InstallDir = dir
+	if err := saveSettings(settings); err != nil {
+		return err
+	}
+	return updateRegistry(func(r *instanceRegistry) error {
+		r.Active = ""
+		return nil
+	})
+}
+
+// applyInstallDir는 현재 옵션/환경 변수/설정을 기준으로 installDir과 currentInstance를 확정합니다.
+// --instance 옵션은 --dir 옵션이 없을 때 가장 먼저 적용됩니다.
+func applyInstallDir() error {
+	registry, err := loadRegistry()
+	if err != nil {
+		fmt.Printf("⚠️ 인스턴스 목록을 읽지 못했습니다: %v\n", err)
+	}
+	if installDirFlag == "" && instanceFlag != "" {
+		inst := registry.find(instanceFlag)
+		if inst == nil {
+			return fmt.Errorf("%w: '%s'", errUnknownInstance, instanceFlag)
+		}
+		installDir, installDirSource, currentInstance = inst.Path, fmt.Sprintf("--instance 옵션 '%s'", inst.Name), inst
+		return nil
+	}
+	dir, source, err := resolveInstallDir(installDirFlag, registry)
+	if err != nil {
+		return err
+	}
+	installDir, installDirSource, currentInstance = dir, source, registry.findByPath(dir)
+	return nil
+}
+
+// changeInstallDirSetting은 메뉴에서 설치 경로를 변경하고 저장합니다.
+func changeInstallDirSetting() {
+	fmt.Println("\n[ 설치 경로 변경 ]")
+	fmt.Printf("현재 설치 경로: %s (%s)\n", installDir, installDirSource)
+	fmt.Print("새 설치 경로를 입력하세요 (공백 포함 가능, 비워두면 변경 안 함): ")
+	input := getUserChoice()
+	if strings.TrimSpace(input) == "" {
+		fmt.Println("입력이 없어 설치 경로를 변경하지 않습니다.")
+		return
+	}
+	dir, err := normalizePath(input)
+	if err != nil {
+		fmt.Println("❌ 잘못된 경로입니다:", err)
+		return
+	}
+	if fi, err := os.Stat(dir); err == nil && !fi.IsDir() {
+		fmt.Printf("❌ '%s'는 디렉토리가 아닙니다.\n", dir)
+		return
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".git")); os.IsNotExist(err) {
+		fmt.Println("ℹ️ 해당 경로에는 아직 SillyTavern이 설치되어 있지 않습니다. '설치|업데이트' 메뉴로 설치할 수 있습니다.")
+	}
+
+	installDir, installDirSource, currentInstance = dir, installDirSourcePref, nil
+	if registry, err := loadRegistry(); err == nil {
+		currentInstance = registry.findByPath(dir)
+	}
+	if err := saveInstallDirPreference(dir); err != nil {
+		fmt.Println("⚠️ 설치 경로를 저장하지 못했습니다 (이번 실행에만 적용됩니다):", err)
+	} else {
+		fmt.Printf("✅ 설치 경로가 '%s'(으)로 변경 및 저장되었습니다.\n", dir)
+	}
+	if installDirFlag != "" || os.Getenv(installDirEnv) != "" {
+		fmt.Printf("ℹ️ 다음 실행 시에는 --dir 옵션 또는 %s 환경 변수가 저장된 설정보다 우선합니다.\n", installDirEnv)
+	}
+}
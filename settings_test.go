@@ -0,0 +1,124 @@
+package main
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+// withInstallerState는 설치 프로그램 데이터 디렉토리를 임시 디렉토리로 돌리고, 설치 경로 관련 전역 값을 테스트가 끝나면 되돌립니다.
+func withInstallerState(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv(installerDataDirEnv, filepath.Join(home, "installer data"))
+	t.Setenv(installDirEnv, "")
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	savedFlag, savedInstance, savedDir, savedSource, savedCurrent := installDirFlag, instanceFlag, installDir, installDirSource, currentInstance
+	savedCase := pathsCaseInsensitive
+	t.Cleanup(func() {
+		installDirFlag, instanceFlag, installDir, installDirSource, currentInstance = savedFlag, savedInstance, savedDir, savedSource, savedCurrent
+		pathsCaseInsensitive = savedCase
+	})
+	installDirFlag, instanceFlag, installDir, installDirSource, currentInstance = "", "", defaultBaseDir, installDirSourceDef, nil
+	return home
+}
+
+func TestApplyInstallDirPrecedence(t *testing.T) {
+	home := withInstallerState(t)
+	root := t.TempDir()
+	mainPath := filepath.Join(root, "main tavern")
+	altPath := filepath.Join(root, "alt tavern")
+	flagPath := filepath.Join(root, "from flag")
+	envPath := filepath.Join(root, "from env")
+	prefPath := filepath.Join(root, "saved setting")
+	defaultPath, err := filepath.Abs(defaultBaseDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name                                 string
+		flag, instance, env, active, pref    string
+		wantDir, wantSource, wantCurrentName string
+	}{
+		{"--dir wins", flagPath, "alt", envPath, "main", prefPath, flagPath, installDirSourceFlag, ""},
+		{"--dir of a registered path", mainPath, "alt", envPath, "", prefPath, mainPath, installDirSourceFlag, "main"},
+		{"--instance", "", "alt", envPath, "main", prefPath, altPath, "--instance 옵션 'alt'", "alt"},
+		{"env", "", "", envPath, "main", prefPath, envPath, installDirSourceEnv, ""},
+		{"active instance", "", "", "", "main", prefPath, mainPath, "선택된 인스턴스 'main'", "main"},
+		{"saved setting", "", "", "", "", prefPath, prefPath, installDirSourcePref, ""},
+		{"default", "", "", "", "", "", defaultPath, installDirSourceDef, ""},
+		{"blank values are skipped", "  ", "", " ", "", prefPath, prefPath, installDirSourcePref, ""},
+		{"~ in --dir", "~/my tavern", "", "", "", "", filepath.Join(home, "my tavern"), installDirSourceFlag, ""},
+		{"~ in env", "", "", "~", "", "", home, installDirSourceEnv, ""},
+		{"quoted env with spaces", "", "", `  "` + envPath + `"  `, "", "", envPath, installDirSourceEnv, ""},
+		{"relative saved setting", "", "", "", "", "./saved/../ST", mustAbs(t, "ST"), installDirSourcePref, ""},
+	}
+	for _, tt := range tests {
+		registry := &instanceRegistry{Active: tt.active, Instances: []*instance{
+			{Name: "main", Path: mainPath},
+			{Name: "alt", Path: altPath},
+		}}
+		if err := saveRegistry(registry); err != nil {
+			t.Fatal(err)
+		}
+		if err := saveSettings(&installerSettings{InstallDir: tt.pref}); err != nil {
+			t.Fatal(err)
+		}
+		t.Setenv(installDirEnv, tt.env)
+		installDirFlag, instanceFlag, currentInstance = tt.flag, tt.instance, nil
+
+		if err := applyInstallDir(); err != nil {
+			t.Errorf("%s: applyInstallDir: %v", tt.name, err)
+			continue
+		}
+		if installDir != tt.wantDir || installDirSource != tt.wantSource {
+			t.Errorf("%s: installDir = %q (%s), want %q (%s)", tt.name, installDir, installDirSource, tt.wantDir, tt.wantSource)
+		}
+		var currentName string
+		if currentInstance != nil {
+			currentName = currentInstance.Name
+		}
+		if currentName != tt.wantCurrentName {
+			t.Errorf("%s: currentInstance = %q, want %q", tt.name, currentName, tt.wantCurrentName)
+		}
+	}
+}
+
+func mustAbs(t *testing.T, path string) string {
+	t.Helper()
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return abs
+}
+
+func TestApplyInstallDirUnknownInstance(t *testing.T) {
+	withInstallerState(t)
+	instanceFlag = "missing"
+	if err := applyInstallDir(); !errors.Is(err, errUnknownInstance) {
+		t.Errorf("applyInstallDir = %v, want %v", err, errUnknownInstance)
+	}
+	// --dir이 있으면 --instance는 쓰이지 않습니다.
+	installDirFlag = t.TempDir()
+	if err := applyInstallDir(); err != nil || installDir != installDirFlag {
+		t.Errorf("applyInstallDir = %v, installDir %q", err, installDir)
+	}
+}
+
+func TestResolveInstallDirActiveInstanceMissing(t *testing.T) {
+	withInstallerState(t)
+	prefPath := filepath.Join(t.TempDir(), "saved")
+	if err := saveSettings(&installerSettings{InstallDir: prefPath}); err != nil {
+		t.Fatal(err)
+	}
+	// 목록에서 지워진 인스턴스가 선택된 상태로 남아 있으면 저장된 설정으로 넘어갑니다.
+	registry := &instanceRegistry{Active: "gone"}
+	dir, source, err := resolveInstallDir("", registry)
+	if err != nil || dir != prefPath || source != installDirSourcePref {
+		t.Errorf("resolveInstallDir = %q, %q, %v; want %q from %s", dir, source, err, prefPath, installDirSourcePref)
+	}
+}
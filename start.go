@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	serverScriptName   = "server.js"
+	defaultServerPort  = 8000
+	serverListeningKey = "listening" // SillyTavern이 접속 가능해지면 출력하는 줄에 포함된 단어
+)
+
+// serverPort는 config.yaml(없으면 default/config.yaml)의 포트를 읽습니다. 둘 다 없으면 기본값 8000입니다.
+func serverPort(baseDir string) int {
+	for _, configPath := range []string{filepath.Join(baseDir, configFileName), filepath.Join(baseDir, "default", configFileName)} {
+		if port, err := readConfigPort(configPath); err == nil {
+			return port
+		}
+	}
+	return defaultServerPort
+}
+
+// serverURL은 설정된 포트와 SSL 사용 여부로 브라우저에서 열 주소를 만듭니다.
+func serverURL(baseDir string) string {
+	scheme := "http"
+	if config, err := loadConfig(filepath.Join(baseDir, configFileName)); err == nil {
+		if enabled, ok := config.getString("ssl", "enabled"); ok && strings.EqualFold(enabled, "true") {
+			scheme = "https"
+		}
+	}
+	return fmt.Sprintf("%s://127.0.0.1:%d/", scheme, serverPort(baseDir))
+}
+
+// newServerCommand는 baseDir 인스턴스에서 'node server.js'를 실행할 명령을 만듭니다.
+// 인스턴스 설정에 맞는 Node.js(시스템/인스턴스 전용)를 사용하며, 해당 Node.js 디렉토리를 PATH 맨 앞에 둡니다.
+func newServerCommand(baseDir string, extraArgs ...string) (*exec.Cmd, error) {
+	if _, err := os.Stat(filepath.Join(baseDir, serverScriptName)); err != nil {
+		return nil, fmt.Errorf("%w: %s에 %s가 없습니다. 먼저 설치해주세요.", errNotInstalled, baseDir, serverScriptName)
+	}
+	if err := applyNodeRuntime(baseDir); err != nil {
+		return nil, err
+	}
+	cmd := exec.Command(nodeExecutablePath, append([]string{serverScriptName}, extraArgs...)...)
+	cmd.Dir = baseDir
+	if nodeDir := getNodeJsDir(); nodeDir != "" {
+		cmd.Env = environWithPathPrefix(nodeDir)
+	}
+	return cmd, nil
+}
+
+// watchServerOutput은 서버 출력을 그대로 out에 옮겨 적으면서, "listening" 줄이 처음 나오면 onListening을 한 번 호출합니다.
+func watchServerOutput(r io.Reader, out io.Writer, once *sync.Once, onListening func()) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		fmt.Fprintln(out, line)
+		if strings.Contains(strings.ToLower(line), serverListeningKey) {
+			once.Do(onListening)
+		}
+	}
+}
+
+// startSillyTavern은 SillyTavern을 이 창에서 실행하고 종료될 때까지 출력을 보여줍니다.
+// 접속 가능해지면 주소를 알려주며, openBrowser가 true이면 기본 브라우저로 엽니다.
+// Ctrl+C는 SillyTavern에만 전달되고, 설치 프로그램은 종료를 확인한 뒤 돌아옵니다.
+func startSillyTavern(baseDir string, openBrowser bool) error {
+	if state, err := loadServerState(baseDir); err != nil {
+		return err
+	} else if state != nil {
+		return fmt.Errorf("SillyTavern이 이미 실행 중입니다 (PID %d). 중지(stop)한 뒤 다시 실행해주세요", state.SupervisorPID)
+	}
+	if err := checkPortFree(baseDir); err != nil {
+		return err
+	}
+	dir, err := serverRunDir(baseDir)
+	if err != nil {
+		return err
+	}
+	os.Remove(filepath.Join(dir, stopRequestName))
+	state := &serverState{Path: baseDir, SupervisorPID: os.Getpid(), Started: time.Now()}
+	defer os.Remove(filepath.Join(dir, serverStateName))
+
+	url := serverURL(baseDir)
+	fmt.Printf("SillyTavern 실행 중 (%s %s, 위치: %s)...\n", nodeExecutablePath, serverScriptName, baseDir)
+	fmt.Println("ℹ️ 종료하려면 Ctrl+C를 누르세요.")
+	fmt.Println("---------------------------------------------------------")
+
+	logPath, err := serverLogPath(baseDir)
+	if err != nil {
+		return err
+	}
+	serverLog, err := openRotatingLog(logPath, loadLogLimits())
+	if err != nil {
+		return err
+	}
+	defer serverLog.Close()
+
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	defer signal.Stop(interrupts)
+
+	// 화면에 보여주면서 로그 파일에도 남깁니다.
+	stdout, stderr := io.MultiWriter(os.Stdout, serverLog), io.MultiWriter(os.Stderr, serverLog)
+	stopped, err := runServerOnce(baseDir, dir, state, stdout, stderr, func() {
+		fmt.Printf("\n✅ SillyTavern이 실행되었습니다: %s\n\n", url)
+		if openBrowser {
+			if err := host.openBrowser(url); err != nil {
+				fmt.Println("⚠️ 브라우저를 열지 못했습니다:", err)
+			}
+		}
+	})
+	if state.ServerPID == 0 {
+		return err // 실행하지 못함
+	}
+	fmt.Println("---------------------------------------------------------")
+
+	select {
+	case <-interrupts:
+		stopped = true
+	default:
+	}
+	if stopped {
+		fmt.Println("ℹ️ SillyTavern을 종료했습니다.")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("SillyTavern이 오류로 종료되었습니다: %w", err)
+	}
+	fmt.Println("ℹ️ SillyTavern이 종료되었습니다.")
+	return nil
+}
+
+// startSillyTavernMenu는 메뉴의 '실리태번 실행' 항목입니다.
+func startSillyTavernMenu() {
+	fmt.Println("\n[ 실리태번 실행 ]")
+	openBrowser := confirm("실행되면 브라우저로 여시겠습니까? (y/n): ")
+	if err := startSillyTavern(installDir, openBrowser); err != nil {
+		fmt.Println("❌", err)
+	}
+}
@@ -0,0 +1,476 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	runDirName        = "run"
+	serverStateName   = "server.json" // 실행 중인 SillyTavern 정보 (pidfile)
+	stopRequestName   = "stop"        // 이 파일이 생기면 감시 프로세스가 SillyTavern을 종료합니다
+	supervisorCommand = "supervise"   // 백그라운드 감시 프로세스용 (도움말에 표시하지 않는) 명령
+)
+
+var (
+	stopPollInterval   = 500 * time.Millisecond
+	stopGracePeriod    = 10 * time.Second // 정상 종료를 기다린 뒤 강제 종료하기까지의 시간
+	startWaitTimeout   = 60 * time.Second // 백그라운드 실행 후 접속 가능해질 때까지 기다리는 시간
+	restartBaseWait    = 2 * time.Second  // 비정상 종료 후 첫 재시작 대기 시간 (재시작할 때마다 2배)
+	restartMaxWait     = time.Minute
+	restartStableAfter = time.Minute // 이 시간 이상 실행되었다가 종료되면 대기 시간을 처음으로 되돌림
+	maxRapidRestarts   = 10          // 금방 종료되는 일이 연속으로 이만큼 반복되면 재시작을 포기함
+)
+
+// serverState는 실행 중인 SillyTavern의 기록(pidfile)입니다.
+type serverState struct {
+	Path          string    `json:"path"`
+	SupervisorPID int       `json:"supervisorPid"`       // 감시 프로세스 (메뉴에서 직접 실행했으면 그 설치 프로그램)
+	ServerPID     int       `json:"serverPid,omitempty"` // node server.js
+	Background    bool      `json:"background"`          // 백그라운드 감시 프로세스로 실행 중인지 여부
+	Started       time.Time `json:"started"`             // 감시 시작 시각
+	ServerStarted time.Time `json:"serverStarted"`       // 현재 node 프로세스 시작 시각
+	Restarts      int       `json:"restarts"`            // 비정상 종료 후 자동 재시작 횟수
+	Port          int       `json:"port"`                // 시작할 때 설정된 포트
+	Listening     bool      `json:"listening"`           // 접속 가능 메시지를 확인했는지 여부
+	LastExit      string    `json:"lastExit,omitempty"`  // 마지막 비정상 종료 사유
+	GaveUp        bool      `json:"gaveUp,omitempty"`    // 재시작을 포기했는지 여부
+}
+
+func serverRunDir(baseDir string) (string, error) {
+	return instanceDataDir(baseDir, runDirName)
+}
+
+// loadServerState는 baseDir 인스턴스의 실행 기록을 읽습니다. 기록이 없거나 감시 프로세스가 이미 끝났으면 nil입니다.
+func loadServerState(baseDir string) (*serverState, error) {
+	dir, err := serverRunDir(baseDir)
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(dir, serverStateName)
+	state := &serverState{}
+	if err := readJSONFile(path, state); err != nil {
+		return nil, err
+	}
+	if state.SupervisorPID == 0 {
+		return nil, nil
+	}
+	if !host.processAlive(state.SupervisorPID) {
+		// 비정상 종료 등으로 남은 기록은 정리합니다.
+		os.Remove(path)
+		os.Remove(filepath.Join(dir, stopRequestName))
+		return nil, nil
+	}
+	return state, nil
+}
+
+func saveServerState(dir string, state *serverState) {
+	if err := writeJSONFile(filepath.Join(dir, serverStateName), state); err != nil {
+		fmt.Println("⚠️ 실행 기록 저장 실패:", err)
+	}
+}
+
+func stopRequested(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, stopRequestName))
+	return err == nil
+}
+
+// portBound는 로컬에서 port로 접속할 수 있는지 확인합니다.
+func portBound(port int) bool {
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port)), time.Second)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+// runServerOnce는 SillyTavern을 한 번 실행하고 끝날 때까지 기다립니다. 실행 중에 stop 파일이 생기면 종료시키며,
+// 그 경우 stopped가 true입니다. 출력은 stdout/stderr로 보내고, 접속 가능해지면 onListening을 호출합니다.
+func runServerOnce(baseDir, dir string, state *serverState, stdout, stderr io.Writer, onListening func()) (stopped bool, err error) {
+	cmd, err := newServerCommand(baseDir)
+	if err != nil {
+		return false, err
+	}
+	outPipe, err := cmd.StdoutPipe()
+	if err != nil {
+		return false, fmt.Errorf("출력 연결 실패: %w", err)
+	}
+	errPipe, err := cmd.StderrPipe()
+	if err != nil {
+		return false, fmt.Errorf("출력 연결 실패: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return false, fmt.Errorf("SillyTavern 실행 실패: %w", err)
+	}
+
+	var mu sync.Mutex
+	state.ServerPID, state.ServerStarted, state.Listening = cmd.Process.Pid, time.Now(), false
+	state.Port = serverPort(baseDir)
+	saveServerState(dir, state)
+
+	done := make(chan struct{})
+	var stopOnce sync.Once
+	go func() {
+		ticker := time.NewTicker(stopPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if !stopRequested(dir) {
+					continue
+				}
+				stopOnce.Do(func() {
+					mu.Lock()
+					stopped = true
+					mu.Unlock()
+					host.terminate(cmd.Process)
+					time.AfterFunc(stopGracePeriod, func() {
+						select {
+						case <-done:
+						default:
+							cmd.Process.Kill()
+						}
+					})
+				})
+			}
+		}
+	}()
+
+	var once sync.Once
+	listening := func() {
+		mu.Lock()
+		state.Listening = true
+		saveServerState(dir, state)
+		mu.Unlock()
+		if onListening != nil {
+			onListening()
+		}
+	}
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() { defer wg.Done(); watchServerOutput(outPipe, stdout, &once, listening) }()
+	go func() { defer wg.Done(); watchServerOutput(errPipe, stderr, &once, listening) }()
+	wg.Wait()
+	err = cmd.Wait()
+	close(done)
+
+	mu.Lock()
+	defer mu.Unlock()
+	return stopped, err
+}
+
+// superviseServer는 백그라운드 감시 프로세스의 본체입니다. SillyTavern을 실행하고, 중지 요청 없이 종료되면
+// 대기 시간을 늘려 가며 다시 실행합니다. SillyTavern 출력과 감시 기록은 인스턴스의 로그 파일에 남깁니다.
+func superviseServer(baseDir string) error {
+	dir, err := serverRunDir(baseDir)
+	if err != nil {
+		return err
+	}
+	logPath, err := serverLogPath(baseDir)
+	if err != nil {
+		return err
+	}
+	serverLog, err := openRotatingLog(logPath, loadLogLimits())
+	if err != nil {
+		return err
+	}
+	defer serverLog.Close()
+	state := &serverState{Path: baseDir, SupervisorPID: os.Getpid(), Background: true, Started: time.Now()}
+	defer func() {
+		os.Remove(filepath.Join(dir, serverStateName))
+		os.Remove(filepath.Join(dir, stopRequestName))
+	}()
+
+	wait, rapid := restartBaseWait, 0
+	for !stopRequested(dir) {
+		logSupervisor(serverLog, "SillyTavern 시작 (%s)", baseDir)
+		startedAt := time.Now()
+		stopped, err := runServerOnce(baseDir, dir, state, serverLog, serverLog, func() {
+			logSupervisor(serverLog, "접속 가능: %s", serverURL(baseDir))
+		})
+		if stopped {
+			logSupervisor(serverLog, "중지 요청으로 SillyTavern을 종료했습니다.")
+			return nil
+		}
+		if err != nil && state.ServerPID == 0 {
+			logSupervisor(serverLog, "❌ %v", err)
+			return err // 실행 자체가 불가능한 경우 (미설치 등)
+		}
+		reason := "정상 종료 코드"
+		if err != nil {
+			reason = err.Error()
+		}
+		if time.Since(startedAt) >= restartStableAfter {
+			wait, rapid = restartBaseWait, 0
+		} else {
+			rapid++
+		}
+		state.LastExit = fmt.Sprintf("%s (%s)", reason, time.Now().Format("2006-01-02 15:04:05"))
+		if rapid >= maxRapidRestarts {
+			state.GaveUp = true
+			saveServerState(dir, state)
+			logSupervisor(serverLog, "‼️ SillyTavern이 연속으로 %d번 금방 종료되어 자동 재시작을 중단합니다: %s", rapid, reason)
+			return fmt.Errorf("SillyTavern이 반복해서 종료되었습니다: %s", reason)
+		}
+		logSupervisor(serverLog, "⚠️ SillyTavern이 예기치 않게 종료되었습니다 (%s). %s 후 다시 시작합니다.", reason, wait)
+		state.ServerPID, state.Listening = 0, false
+		saveServerState(dir, state)
+		for deadline := time.Now().Add(wait); time.Now().Before(deadline) && !stopRequested(dir); {
+			time.Sleep(stopPollInterval)
+		}
+		wait *= 2
+		if wait > restartMaxWait {
+			wait = restartMaxWait
+		}
+		state.Restarts++
+	}
+	logSupervisor(serverLog, "중지 요청으로 감시를 종료합니다.")
+	return nil
+}
+
+func logSupervisor(w io.Writer, format string, a ...interface{}) {
+	fmt.Fprintf(w, "[%s] [installer] %s\n", time.Now().Format("2006-01-02 15:04:05"), fmt.Sprintf(format, a...))
+}
+
+// startBackground는 감시 프로세스를 분리해서 실행하고 SillyTavern이 접속 가능해질 때까지 기다립니다.
+func startBackground(baseDir string, openBrowser bool) error {
+	if state, err := loadServerState(baseDir); err != nil {
+		return err
+	} else if state != nil {
+		return fmt.Errorf("SillyTavern이 이미 실행 중입니다 (PID %d). 다시 시작하려면 restart를 사용하세요", state.SupervisorPID)
+	}
+	if err := checkPortFree(baseDir); err != nil {
+		return err
+	}
+	if _, err := os.Stat(filepath.Join(baseDir, serverScriptName)); err != nil {
+		return fmt.Errorf("%w: %s에 %s가 없습니다. 먼저 설치해주세요.", errNotInstalled, baseDir, serverScriptName)
+	}
+	dir, err := serverRunDir(baseDir)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("디렉토리(%s) 생성 실패: %w", dir, err)
+	}
+	os.Remove(filepath.Join(dir, stopRequestName))
+
+	self, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("설치 프로그램 경로 확인 실패: %w", err)
+	}
+	logPath, err := serverLogPath(baseDir)
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command(self, supervisorCommand, "--dir", baseDir, "--node", nodeExecutablePath)
+	host.detachProcess(cmd)
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("백그라운드 실행 실패: %w", err)
+	}
+	pid := cmd.Process.Pid
+	cmd.Process.Release()
+	fmt.Printf("SillyTavern을 백그라운드로 시작했습니다 (감시 프로세스 PID %d, 로그: %s).\n", pid, logPath)
+
+	fmt.Print("접속 가능해질 때까지 기다리는 중")
+	for deadline := time.Now().Add(startWaitTimeout); time.Now().Before(deadline); time.Sleep(stopPollInterval) {
+		state, _ := loadServerState(baseDir)
+		if state == nil {
+			if host.processAlive(pid) {
+				continue // 아직 기록을 남기기 전
+			}
+			fmt.Println()
+			return fmt.Errorf("SillyTavern이 시작 직후 종료되었습니다. 로그(%s)를 확인해주세요", logPath)
+		}
+		if state.Listening {
+			fmt.Println()
+			url := serverURL(baseDir)
+			fmt.Printf("✅ SillyTavern이 실행되었습니다: %s\n", url)
+			if openBrowser {
+				if err := host.openBrowser(url); err != nil {
+					fmt.Println("⚠️ 브라우저를 열지 못했습니다:", err)
+				}
+			}
+			return nil
+		}
+		fmt.Print(".")
+	}
+	fmt.Println()
+	fmt.Printf("ℹ️ %s 안에 접속 가능 메시지를 확인하지 못했습니다. 상태(status)와 로그(%s)를 확인해주세요.\n", startWaitTimeout, logPath)
+	return nil
+}
+
+// errServerNotRunning은 실행 중이지 않은 SillyTavern을 중지하려 할 때의 오류입니다.
+var errServerNotRunning = errors.New("SillyTavern이 실행 중이 아닙니다")
+
+// stopServer는 감시 프로세스에 중지를 요청하고 종료될 때까지 기다립니다. 제때 끝나지 않으면 강제 종료합니다.
+func stopServer(baseDir string) error {
+	state, err := loadServerState(baseDir)
+	if err != nil {
+		return err
+	}
+	if state == nil {
+		return errServerNotRunning
+	}
+	dir, err := serverRunDir(baseDir)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, stopRequestName), []byte(time.Now().Format(time.RFC3339)), 0644); err != nil {
+		return fmt.Errorf("중지 요청 실패: %w", err)
+	}
+	fmt.Printf("SillyTavern 중지 중 (PID %d)...\n", state.SupervisorPID)
+	for deadline := time.Now().Add(stopGracePeriod + 5*time.Second); time.Now().Before(deadline); time.Sleep(stopPollInterval) {
+		if !host.processAlive(state.SupervisorPID) {
+			os.Remove(filepath.Join(dir, stopRequestName))
+			fmt.Println("✅ SillyTavern을 중지했습니다.")
+			return nil
+		}
+	}
+
+	fmt.Println("⚠️ 제때 종료되지 않아 강제로 종료합니다.")
+	for _, pid := range []int{state.ServerPID, state.SupervisorPID} {
+		if pid == 0 {
+			continue
+		}
+		if p, err := os.FindProcess(pid); err == nil {
+			p.Kill()
+		}
+	}
+	os.Remove(filepath.Join(dir, serverStateName))
+	os.Remove(filepath.Join(dir, stopRequestName))
+	fmt.Println("✅ SillyTavern을 중지했습니다.")
+	return nil
+}
+
+// restartServer는 실행 중인 SillyTavern을 중지한 뒤 백그라운드로 다시 시작합니다 (중지 상태이면 바로 시작).
+func restartServer(baseDir string) error {
+	if err := stopServer(baseDir); err != nil && !errors.Is(err, errServerNotRunning) {
+		return err
+	}
+	return startBackground(baseDir, false)
+}
+
+// printServerStatus는 실행 여부, 가동 시간, 포트 응답 여부를 출력합니다. 실행 중이면 true를 반환합니다.
+func printServerStatus(baseDir string) (bool, error) {
+	state, err := loadServerState(baseDir)
+	if err != nil {
+		return false, err
+	}
+	if state == nil {
+		fmt.Printf("⏹️ SillyTavern이 실행 중이 아닙니다 (%s).\n", baseDir)
+		return false, nil
+	}
+	mode := "메뉴/명령 창에서 실행"
+	if state.Background {
+		mode = "백그라운드"
+	}
+	fmt.Printf("▶️ SillyTavern 실행 중 (%s, %s)\n", baseDir, mode)
+	fmt.Printf("   감시 PID: %d | node PID: %s\n", state.SupervisorPID, pidLabel(state.ServerPID))
+	if state.ServerPID != 0 {
+		fmt.Printf("   가동 시간: %s (시작: %s)\n", formatUptime(time.Since(state.ServerStarted)), state.ServerStarted.Local().Format("2006-01-02 15:04:05"))
+	}
+	if state.Restarts > 0 {
+		fmt.Printf("   자동 재시작: %d회 (마지막 종료: %s)\n", state.Restarts, state.LastExit)
+	}
+	if state.GaveUp {
+		fmt.Println("   ‼️ 반복된 종료로 자동 재시작을 중단했습니다.")
+	}
+	bound := "응답 없음"
+	if portBound(state.Port) {
+		bound = "접속 가능"
+	}
+	fmt.Printf("   포트: %d (%s) | 주소: %s\n", state.Port, bound, serverURL(baseDir))
+	if logPath, err := serverLogPath(baseDir); err == nil {
+		fmt.Printf("   로그: %s\n", logPath)
+	}
+	return true, nil
+}
+
+// checkPortFree는 설정된 포트를 이미 다른 프로세스(서비스로 실행 중인 SillyTavern 등)가 사용하고 있으면 오류를 반환합니다.
+func checkPortFree(baseDir string) error {
+	if port := serverPort(baseDir); portBound(port) {
+		return fmt.Errorf("포트 %d를 이미 사용 중입니다. 서비스로 실행 중이거나 다른 프로그램이 사용 중인지 확인해주세요 (service status)", port)
+	}
+	return nil
+}
+
+func pidLabel(pid int) string {
+	if pid == 0 {
+		return "(재시작 대기 중)"
+	}
+	return strconv.Itoa(pid)
+}
+
+func formatUptime(d time.Duration) string {
+	d = d.Round(time.Second)
+	days := int(d.Hours()) / 24
+	h, m, s := int(d.Hours())%24, int(d.Minutes())%60, int(d.Seconds())%60
+	if days > 0 {
+		return fmt.Sprintf("%d일 %d시간 %d분", days, h, m)
+	}
+	if h > 0 {
+		return fmt.Sprintf("%d시간 %d분 %d초", h, m, s)
+	}
+	return fmt.Sprintf("%d분 %d초", m, s)
+}
+
+// offerRestart는 설정 변경 후 SillyTavern이 실행 중이면 바로 재시작할지 묻습니다.
+func offerRestart(baseDir string) {
+	state, err := loadServerState(baseDir)
+	if err != nil || state == nil {
+		return
+	}
+	if !confirm("SillyTavern이 실행 중입니다. 지금 재시작하여 변경 사항을 적용하시겠습니까? (y/n): ") {
+		return
+	}
+	if err := restartServer(baseDir); err != nil {
+		fmt.Println("❌ 재시작 실패:", err)
+	}
+}
+
+// serverMenu는 실행/중지/재시작/상태 메뉴입니다.
+func serverMenu() {
+	fmt.Println("\n[ 실리태번 실행 / 중지 ]")
+	if _, err := printServerStatus(installDir); err != nil {
+		fmt.Println("⚠️ 실행 상태를 확인하지 못했습니다:", err)
+	}
+	fmt.Println("\n1. 실행 (이 창에서, Ctrl+C로 종료)")
+	fmt.Println("2. 백그라운드로 실행 (충돌 시 자동 재시작)")
+	fmt.Println("3. 중지")
+	fmt.Println("4. 재시작")
+	fmt.Println("5. 로그 보기")
+	fmt.Println("6. 부팅 시 자동 실행 (서비스 등록/해제)")
+	fmt.Println("7. 돌아가기")
+	fmt.Print("\n선택하세요 (1-7): ")
+
+	var err error
+	switch getUserChoice() {
+	case "1":
+		startSillyTavernMenu()
+	case "2":
+		err = startBackground(installDir, confirm("실행되면 브라우저로 여시겠습니까? (y/n): "))
+	case "3":
+		err = stopServer(installDir)
+	case "4":
+		err = restartServer(installDir)
+	case "5":
+		logsMenu()
+	case "6":
+		serviceMenu()
+	}
+	if err != nil {
+		fmt.Println("❌", err)
+	}
+}
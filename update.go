@@ -0,0 +1,463 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	autoStashPrefix = "SillyTavernInstaller" // 이 프로그램이 만든 stash 항목의 메시지 앞부분
+
+	conflictAsk    = ""       // 대화형 실행: 충돌 시 메뉴로 묻기
+	conflictMine   = "mine"   // 충돌한 파일은 내 버전 사용
+	conflictTheirs = "theirs" // 충돌한 파일은 새 버전 사용
+	conflictAbort  = "abort"  // 업데이트 이전 커밋과 변경사항으로 되돌림
+)
+
+// conflictResolution은 충돌이 났을 때 사용할 해결 방법입니다 (--on-conflict). 지정하지 않으면(conflictAsk)
+// 대화형 실행에서는 메뉴로 묻고, 비대화형 실행에서는 abort로 처리합니다.
+var conflictResolution = conflictAsk
+
+// setConflictResolution은 --on-conflict 값(mine, theirs, abort)을 확인하고 적용합니다.
+func setConflictResolution(value string) error {
+	switch value {
+	case conflictMine, conflictTheirs, conflictAbort:
+		conflictResolution = value
+		return nil
+	}
+	return fmt.Errorf("--on-conflict 값은 mine, theirs, abort 중 하나여야 합니다: '%s'", value)
+}
+
+// localChanges는 작업 트리의 변경사항을 Git이 추적하는 소스 파일과 그 외(사용자 데이터 등)로 나눈 것입니다.
+// .gitignore에 포함된 파일(data/, config.yaml 등)은 어느 쪽에도 들어가지 않으며 업데이트가 건드리지 않습니다.
+type localChanges struct {
+	Tracked   []string // 수정/삭제/추가(스테이징)된 추적 파일
+	Untracked []string // 추적되지 않는 파일
+	LockFile  bool     // npm install이 다시 만든 package-lock.json이 바뀌었는지 (소스 변경으로 보지 않음)
+}
+
+// detectLocalChanges는 'git status --porcelain'으로 baseDir의 로컬 변경사항을 분류합니다.
+func detectLocalChanges(baseDir string) (*localChanges, error) {
+	cmd := exec.Command(gitExecutablePath, "-C", baseDir, "status", "--porcelain", "-z", "--untracked-files=all")
+	var errBuffer bytes.Buffer
+	cmd.Stderr = &errBuffer
+	out, err := cmd.Output()
+	if err != nil {
+		errMsg := strings.TrimSpace(errBuffer.String())
+		if strings.Contains(errMsg, "detected dubious ownership") {
+			fmt.Println("\n‼️ Git 소유권 문제 감지됨. `git config --global --add safe.directory ...` 명령을 실행하고 재시도해주세요.")
+			return nil, fmt.Errorf("Git 소유권 문제로 변경사항 확인 실패: %w", err)
+		}
+		return nil, fmt.Errorf("로컬 변경사항 확인 실패: %w\n%s", err, errMsg)
+	}
+	changes := &localChanges{}
+	entries := strings.Split(string(out), "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if len(entry) < 4 {
+			continue
+		}
+		status, path := entry[:2], entry[3:]
+		if status == "??" {
+			changes.Untracked = append(changes.Untracked, path)
+			continue
+		}
+		if path == lockFileName {
+			changes.LockFile = true
+		} else {
+			changes.Tracked = append(changes.Tracked, path)
+		}
+		if status[0] == 'R' || status[0] == 'C' {
+			i++ // 이름 변경/복사는 원래 경로가 다음 항목으로 따라옵니다.
+		}
+	}
+	return changes, nil
+}
+
+// print는 변경사항 요약을 출력합니다. 파일이 많으면 앞부분만 보여줍니다.
+func (c *localChanges) print() {
+	const maxShown = 10
+	show := func(files []string) {
+		for i, f := range files {
+			if i == maxShown {
+				fmt.Printf("   ... 외 %d개\n", len(files)-maxShown)
+				break
+			}
+			fmt.Println("   -", f)
+		}
+	}
+	if len(c.Tracked) > 0 {
+		fmt.Printf("ℹ️ 직접 수정한 SillyTavern 소스 파일 %d개 (업데이트 동안 임시 저장 후 다시 적용):\n", len(c.Tracked))
+		show(c.Tracked)
+	}
+	if len(c.Untracked) > 0 {
+		fmt.Printf("ℹ️ Git이 추적하지 않는 파일 %d개 (사용자 파일로 보고 그대로 둠):\n", len(c.Untracked))
+		show(c.Untracked)
+	}
+}
+
+// autoStash는 이 프로그램이 업데이트/브랜치 전환 전에 만든 stash 항목 하나입니다.
+// stash 목록의 맨 위가 아니라 커밋 SHA로 찾으므로, 사용자가 만든 다른 stash는 건드리지 않습니다.
+type autoStash struct {
+	baseDir string
+	commit  string // stash 커밋 SHA
+	head    string // 임시 저장 당시의 HEAD 커밋
+	branch  string // 임시 저장 당시의 브랜치 (분리된 HEAD이면 빈 문자열)
+	label   string
+}
+
+// stashLocalChanges는 추적되는 소스 파일의 변경사항만 이름 붙인 stash 항목으로 저장하고 작업 트리를 되돌립니다.
+// 추적되지 않는 파일과 .gitignore에 포함된 사용자 데이터는 그대로 둡니다. 저장할 내용이 없으면 nil을 반환합니다.
+func stashLocalChanges(baseDir, label string) (*autoStash, error) {
+	changes, err := detectLocalChanges(baseDir)
+	if err != nil {
+		return nil, err
+	}
+	changes.print()
+	// package-lock.json은 npm install이 만든 결과물이므로 임시 저장하지 않고 버립니다. 업데이트 후 npm install이 다시 만듭니다.
+	if changes.LockFile {
+		fmt.Printf("ℹ️ npm이 수정한 %s은(는) 소스 변경이 아니므로 되돌립니다.\n", lockFileName)
+		if err := runGitStep(baseDir, "checkout", "--", lockFileName); err != nil {
+			return nil, err
+		}
+	}
+	if len(changes.Tracked) == 0 {
+		fmt.Println("ℹ️ 임시 저장할 소스 변경사항이 없습니다.")
+		return nil, nil
+	}
+	head, err := gitHeadCommit(baseDir)
+	if err != nil {
+		return nil, err
+	}
+	branch, _ := getCurrentGitBranch(baseDir)
+	s := &autoStash{baseDir: baseDir, head: head, branch: branch}
+	s.label = fmt.Sprintf("%s: %s_%s", autoStashPrefix, label, time.Now().Format("20060102150405"))
+
+	fmt.Println("소스 변경사항 임시 저장 (git stash create / store)...")
+	out, err := exec.Command(gitExecutablePath, "-C", baseDir, "stash", "create", s.label).Output()
+	if err != nil {
+		return nil, fmt.Errorf("git stash create 실패: %w", err)
+	}
+	s.commit = strings.TrimSpace(string(out))
+	if s.commit == "" {
+		fmt.Println("ℹ️ 임시 저장할 소스 변경사항이 없습니다.")
+		return nil, nil
+	}
+	// stash 목록에도 기록해 두어, 이후 단계가 실패해도 'git stash list'에서 찾을 수 있게 합니다.
+	if err := runGitStep(baseDir, "stash", "store", "-m", s.label, s.commit); err != nil {
+		return nil, err
+	}
+	if err := runGitStep(baseDir, "reset", "--hard", "-q", "HEAD"); err != nil {
+		return nil, err
+	}
+	fmt.Printf("소스 변경사항 임시 저장 완료 (%s).\n", shortSHA(s.commit))
+	return s, nil
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+// restore는 임시 저장한 변경사항을 현재 작업 트리에 다시 적용하고 해당 stash 항목만 삭제합니다.
+// 충돌이 나면 해결 방법을 묻습니다. s가 nil이면 아무것도 하지 않습니다.
+func (s *autoStash) restore() error {
+	if s == nil {
+		return nil
+	}
+	fmt.Printf("임시 저장한 소스 변경사항 다시 적용 (git stash apply %s)...\n", shortSHA(s.commit))
+	out, err := exec.Command(gitExecutablePath, "-C", s.baseDir, "stash", "apply", s.commit).CombinedOutput()
+	if err == nil {
+		s.drop()
+		fmt.Println("✅ 소스 변경사항을 다시 적용했습니다.")
+		return nil
+	}
+	conflicts := conflictedFiles(s.baseDir)
+	if len(conflicts) == 0 {
+		fmt.Println("\n❌ 소스 변경사항을 다시 적용하지 못했습니다. Git 메시지:")
+		fmt.Println(strings.TrimSpace(string(out)))
+		fmt.Printf("ℹ️ 변경사항은 stash 목록에 '%s'(으)로 남아 있습니다.\n", s.label)
+		return fmt.Errorf("git stash apply 실패: %w", err)
+	}
+	// stash 적용 충돌에서 --ours는 업데이트된 버전, --theirs는 임시 저장한 내 버전입니다.
+	choice, err := resolveConflicts(s.baseDir, conflicts, "--theirs", "--ours")
+	if err != nil {
+		return err
+	}
+	switch choice {
+	case conflictAbort:
+		return s.abort()
+	case conflictMine:
+		s.drop()
+	default:
+		fmt.Printf("ℹ️ 충돌한 파일의 내 변경사항은 stash 목록에 '%s'(으)로 남겨 두었습니다.\n", s.label)
+	}
+	// 충돌 표시를 지우고 일반적인 수정 상태로 둡니다 ('git stash pop' 이후와 같은 상태).
+	return runGitStep(s.baseDir, "reset", "-q")
+}
+
+// drop은 stash 목록에서 이 항목(커밋 SHA가 같은 항목)만 삭제합니다.
+func (s *autoStash) drop() {
+	out, err := exec.Command(gitExecutablePath, "-C", s.baseDir, "stash", "list", "--format=%H").Output()
+	if err != nil {
+		return
+	}
+	for i, sha := range strings.Fields(string(out)) {
+		if sha == s.commit {
+			exec.Command(gitExecutablePath, "-C", s.baseDir, "stash", "drop", "-q", fmt.Sprintf("stash@{%d}", i)).Run()
+			return
+		}
+	}
+}
+
+// abort는 진행 중인 병합을 취소하고 임시 저장 당시의 브랜치/커밋으로 되돌린 뒤 변경사항을 다시 적용합니다.
+func (s *autoStash) abort() error {
+	if err := abortToCommit(s.baseDir, s.branch, s.head); err != nil {
+		return err
+	}
+	if out, err := exec.Command(gitExecutablePath, "-C", s.baseDir, "stash", "apply", s.commit).CombinedOutput(); err != nil {
+		fmt.Printf("⚠️ 변경사항을 다시 적용하지 못했습니다: %s\n", strings.TrimSpace(string(out)))
+		fmt.Printf("ℹ️ 변경사항은 stash 목록에 '%s'(으)로 남아 있습니다.\n", s.label)
+	} else {
+		s.drop()
+	}
+	return fmt.Errorf("충돌로 업데이트를 취소하고 이전 커밋(%s)으로 되돌렸습니다", shortSHA(s.head))
+}
+
+// abortToCommit은 진행 중인 병합을 취소하고 작업 트리를 branch(비어 있으면 분리된 HEAD)의 commit으로 되돌립니다.
+func abortToCommit(baseDir, branch, commit string) error {
+	exec.Command(gitExecutablePath, "-C", baseDir, "merge", "--abort").Run()
+	fmt.Printf("업데이트 이전 커밋(%s)으로 되돌리는 중...\n", shortSHA(commit))
+	if branch != "" {
+		if err := runGitStep(baseDir, "checkout", "-q", "-f", branch); err != nil {
+			return err
+		}
+		return runGitStep(baseDir, "reset", "--hard", "-q", commit)
+	}
+	return runGitStep(baseDir, "checkout", "-q", "-f", "--detach", commit)
+}
+
+// conflictedFiles는 병합 충돌이 남아 있는 파일 목록입니다.
+func conflictedFiles(baseDir string) []string {
+	out, err := exec.Command(gitExecutablePath, "-C", baseDir, "diff", "--name-only", "--diff-filter=U").Output()
+	if err != nil {
+		return nil
+	}
+	return strings.Fields(string(out))
+}
+
+// resolveConflicts는 충돌한 파일을 보여주고 해결 방법(내 버전/새 버전/취소)을 고르게 한 뒤, 고른 쪽 버전으로 파일을 정리합니다.
+// mineSide/theirsSide는 각각 내 버전과 새 버전에 해당하는 'git checkout' 옵션(--ours/--theirs)입니다.
+// 취소를 고르면 파일은 건드리지 않고 conflictAbort를 반환합니다.
+func resolveConflicts(baseDir string, files []string, mineSide, theirsSide string) (string, error) {
+	fmt.Println("\n⚠️ 다음 파일에서 내 변경사항과 새 버전이 충돌했습니다:")
+	for _, f := range files {
+		fmt.Println("   -", f)
+	}
+	choice := conflictResolution
+	if nonInteractive && choice == conflictAsk {
+		choice = conflictAbort
+	}
+	for choice == conflictAsk {
+		fmt.Println("\n1. 내 변경사항 유지 (충돌한 파일은 내 버전 사용)")
+		fmt.Println("2. 새 버전 사용 (충돌한 파일의 내 변경사항은 버림)")
+		fmt.Println("3. 업데이트 취소 (업데이트 이전 커밋과 변경사항으로 되돌림)")
+		fmt.Print("\n선택하세요 (1-3): ")
+		switch getUserChoice() {
+		case "1":
+			choice = conflictMine
+		case "2":
+			choice = conflictTheirs
+		case "3":
+			choice = conflictAbort
+		default:
+			fmt.Println("잘못된 선택입니다.")
+		}
+	}
+	side := mineSide
+	switch choice {
+	case conflictAbort:
+		return choice, nil
+	case conflictTheirs:
+		side = theirsSide
+	}
+	for _, f := range files {
+		if err := exec.Command(gitExecutablePath, "-C", baseDir, "checkout", side, "--", f).Run(); err != nil {
+			// 고른 쪽에서 삭제된 파일입니다.
+			if err := runGitStep(baseDir, "rm", "-q", "-f", "--", f); err != nil {
+				return "", err
+			}
+			continue
+		}
+		if err := runGitStep(baseDir, "add", "--", f); err != nil {
+			return "", err
+		}
+	}
+	if choice == conflictMine {
+		fmt.Println("✅ 충돌한 파일을 내 버전으로 정리했습니다.")
+	} else {
+		fmt.Println("✅ 충돌한 파일을 새 버전으로 정리했습니다.")
+	}
+	return choice, nil
+}
+
+// resolveMergeConflict는 'git pull' 병합 충돌(로컬 커밋과 원격 변경이 겹친 경우)을 해결합니다.
+// 취소하면 업데이트 이전 커밋(preUpdate)으로 되돌리고, 임시 저장한 변경사항을 다시 적용합니다.
+func resolveMergeConflict(baseDir, branch, preUpdate string, files []string, stash *autoStash) error {
+	choice, err := resolveConflicts(baseDir, files, "--ours", "--theirs")
+	if err != nil {
+		return err
+	}
+	if choice == conflictAbort {
+		if stash != nil {
+			return stash.abort()
+		}
+		if err := abortToCommit(baseDir, branch, preUpdate); err != nil {
+			return err
+		}
+		return fmt.Errorf("충돌로 업데이트를 취소하고 이전 커밋(%s)으로 되돌렸습니다", shortSHA(preUpdate))
+	}
+	if err := runGitStep(baseDir, "commit", "-q", "--no-edit"); err != nil {
+		return err
+	}
+	fmt.Println("✅ 병합을 완료했습니다.")
+	return stash.restore()
+}
+
+// updateWatchFiles는 업데이트 미리보기에서 따로 강조하는 파일입니다.
+var updateWatchFiles = []string{"package.json", "default/config.yaml"}
+
+// updatePreview는 원격 브랜치에 새로 올라온 변경사항 요약입니다.
+type updatePreview struct {
+	Branch   string
+	Commits  []string // 'git log --oneline' 형식, 최신순
+	Files    []string // 바뀐 파일
+	Watched  []string // updateWatchFiles 중 바뀐 파일
+	Upstream string   // origin/<브랜치>
+}
+
+// previewUpdate는 원격 저장소 정보를 가져온 뒤 현재 브랜치에 적용될 커밋과 바뀐 파일을 모읍니다.
+// 작업 트리는 바꾸지 않습니다.
+func previewUpdate(baseDir string) (*updatePreview, error) {
+	branch, err := getCurrentGitBranch(baseDir)
+	if err != nil {
+		return nil, err
+	}
+	p := &updatePreview{Branch: branch, Upstream: "origin/" + branch}
+	fmt.Printf("원격 저장소 정보 가져오기 (git fetch origin %s)...\n", branch)
+	if err := runGitStep(baseDir, "fetch", "-q", "origin", branch); err != nil {
+		return nil, err
+	}
+	out, err := exec.Command(gitExecutablePath, "-C", baseDir, "log", "--oneline", "--no-decorate", "HEAD.."+p.Upstream).Output()
+	if err != nil {
+		return nil, fmt.Errorf("git log HEAD..%s 실패: %w", p.Upstream, err)
+	}
+	p.Commits = nonEmptyLines(string(out))
+	if len(p.Commits) == 0 {
+		return p, nil
+	}
+	// 세 점(...)은 공통 조상 이후 원격에서 바뀐 내용만 비교합니다 (로컬 커밋은 제외).
+	out, err = exec.Command(gitExecutablePath, "-C", baseDir, "diff", "--name-only", "HEAD..."+p.Upstream).Output()
+	if err != nil {
+		return nil, fmt.Errorf("git diff HEAD...%s 실패: %w", p.Upstream, err)
+	}
+	p.Files = nonEmptyLines(string(out))
+	for _, f := range p.Files {
+		for _, watched := range updateWatchFiles {
+			if f == watched {
+				p.Watched = append(p.Watched, f)
+			}
+		}
+	}
+	return p, nil
+}
+
+func nonEmptyLines(s string) []string {
+	var lines []string
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimRight(line, "\r"); strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// print는 새 커밋 목록과 바뀐 파일 요약을 출력합니다. 강조할 파일은 바뀐 줄도 함께 보여줍니다.
+func (p *updatePreview) print(baseDir string) {
+	const maxCommits, maxDiffLines = 20, 40
+	if len(p.Commits) == 0 {
+		fmt.Printf("✅ 최신 버전입니다 (%s 브랜치).\n", p.Branch)
+		return
+	}
+	fmt.Printf("\n📌 %s 브랜치에 새 커밋 %d개가 있습니다 (바뀐 파일 %d개):\n", p.Branch, len(p.Commits), len(p.Files))
+	for i, c := range p.Commits {
+		if i == maxCommits {
+			fmt.Printf("   ... 외 %d개 (전체: git log HEAD..%s)\n", len(p.Commits)-maxCommits, p.Upstream)
+			break
+		}
+		fmt.Println("   ", c)
+	}
+	for _, f := range p.Watched {
+		switch f {
+		case "package.json":
+			fmt.Println("\n⚠️ package.json이 바뀝니다 (업데이트 후 npm 패키지를 다시 설치합니다):")
+		default:
+			fmt.Printf("\n⚠️ %s 파일이 바뀝니다 (새 설정 항목이 추가되었을 수 있습니다):\n", f)
+		}
+		out, err := exec.Command(gitExecutablePath, "-C", baseDir, "diff", "--no-color", "-U0", "HEAD..."+p.Upstream, "--", f).Output()
+		if err != nil {
+			continue
+		}
+		shown := 0
+		for _, line := range nonEmptyLines(string(out)) {
+			if !(strings.HasPrefix(line, "+") || strings.HasPrefix(line, "-")) || strings.HasPrefix(line, "+++") || strings.HasPrefix(line, "---") {
+				continue
+			}
+			if shown == maxDiffLines {
+				fmt.Printf("   ... (전체: git diff HEAD...%s -- %s)\n", p.Upstream, f)
+				break
+			}
+			fmt.Println("   ", line)
+			shown++
+		}
+	}
+}
+
+// checkForUpdates는 업데이트 미리보기를 보여줍니다. 새 커밋이 있으면 true를 반환합니다.
+// 버전이 고정된 인스턴스는 고정을 해제하기 전까지 업데이트하지 않으므로 확인하지 않습니다.
+func checkForUpdates(baseDir string) (bool, error) {
+	if _, err := os.Stat(filepath.Join(baseDir, ".git")); os.IsNotExist(err) {
+		return false, fmt.Errorf("%w: %s", errNotInstalled, baseDir)
+	}
+	if kind, ref := pinFor(baseDir); ref != "" {
+		fmt.Printf("📌 %s %s에 고정되어 있어 업데이트를 확인하지 않습니다. (해제: '버전 고정' 메뉴 또는 pin clear)\n", pinKindLabel(kind), ref)
+		return false, nil
+	}
+	p, err := previewUpdate(baseDir)
+	if err != nil {
+		return false, err
+	}
+	p.print(baseDir)
+	return len(p.Commits) > 0, nil
+}
+
+// checkForUpdatesMenu는 메뉴의 '업데이트 확인' 항목입니다. 새 커밋이 있으면 업데이트할지 묻습니다.
+func checkForUpdatesMenu() {
+	fmt.Println("\n[ 업데이트 확인 ]")
+	available, err := checkForUpdates(installDir)
+	if err != nil {
+		fmt.Println("❌", err)
+		return
+	}
+	if !available || !confirm("\n지금 업데이트하시겠습니까? (y/n): ") {
+		return
+	}
+	installOrUpdateSillyTavern()
+}
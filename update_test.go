@@ -0,0 +1,72 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestStashLocalChangesDiscardsLockFile(t *testing.T) {
+	setupTestEnv(t)
+	dir := t.TempDir()
+	gitT(t, dir, "init", "-q")
+	commitFiles(t, dir, "v1", map[string]string{serverScriptName: "// v1\n", lockFileName: "{\"v\": 1}\n"})
+
+	writeFileT(t, filepath.Join(dir, lockFileName), "{\"v\": 1, \"npm\": true}\n")
+	writeFileT(t, filepath.Join(dir, serverScriptName), "// v1 edited\n")
+	writeFileT(t, filepath.Join(dir, "notes.txt"), "mine\n")
+
+	changes, err := detectLocalChanges(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !changes.LockFile || !reflect.DeepEqual(changes.Tracked, []string{serverScriptName}) || !reflect.DeepEqual(changes.Untracked, []string{"notes.txt"}) {
+		t.Fatalf("detectLocalChanges = %+v", changes)
+	}
+
+	stash, err := stashLocalChanges(dir, "test")
+	if err != nil || stash == nil {
+		t.Fatalf("stashLocalChanges = %v, %v", stash, err)
+	}
+	if files := gitT(t, dir, "diff", "--name-only", stash.head, stash.commit); files != serverScriptName {
+		t.Errorf("stash contains %q, want only %s", files, serverScriptName)
+	}
+	if got := readFileT(t, filepath.Join(dir, lockFileName)); got != "{\"v\": 1}\n" {
+		t.Errorf("%s = %q after stash, want the committed version", lockFileName, got)
+	}
+
+	// 업데이트가 잠금 파일을 바꿔도 다시 적용할 때 충돌하지 않습니다.
+	commitFiles(t, dir, "v2", map[string]string{lockFileName: "{\"v\": 2}\n"})
+	if err := stash.restore(); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if got := readFileT(t, filepath.Join(dir, serverScriptName)); got != "// v1 edited\n" {
+		t.Errorf("%s = %q after restore", serverScriptName, got)
+	}
+	if got := readFileT(t, filepath.Join(dir, lockFileName)); got != "{\"v\": 2}\n" {
+		t.Errorf("%s = %q after restore, want the updated version", lockFileName, got)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "notes.txt")); err != nil {
+		t.Errorf("untracked file was touched: %v", err)
+	}
+}
+
+func TestStashLocalChangesLockFileOnly(t *testing.T) {
+	setupTestEnv(t)
+	dir := t.TempDir()
+	gitT(t, dir, "init", "-q")
+	commitFiles(t, dir, "v1", map[string]string{serverScriptName: "// v1\n", lockFileName: "{\"v\": 1}\n"})
+	writeFileT(t, filepath.Join(dir, lockFileName), "{\"v\": 1, \"npm\": true}\n")
+
+	stash, err := stashLocalChanges(dir, "test")
+	if err != nil || stash != nil {
+		t.Fatalf("stashLocalChanges = %v, %v; want nothing stashed", stash, err)
+	}
+	if status := gitT(t, dir, "status", "--porcelain"); status != "" {
+		t.Errorf("work tree not clean: %q", status)
+	}
+	if list := gitT(t, dir, "stash", "list"); list != "" {
+		t.Errorf("stash list = %q", list)
+	}
+}
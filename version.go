@@ -0,0 +1,84 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var versionNumberRegex = regexp.MustCompile(`(\d+)(?:\.(\d+))?(?:\.(\d+))?`)
+
+// parseVersion은 "v1.12.3", "git version 2.45.2.windows.1" 같은 문자열에서
+// 처음 나오는 주.부.수 버전 번호를 찾아 [3]int로 반환합니다.
+func parseVersion(s string) ([3]int, bool) {
+	var version [3]int
+	m := versionNumberRegex.FindStringSubmatch(s)
+	if m == nil {
+		return version, false
+	}
+	for i := 0; i < 3; i++ {
+		if m[i+1] != "" {
+			version[i], _ = strconv.Atoi(m[i+1])
+		}
+	}
+	return version, true
+}
+
+// compareVersions는 a < b이면 음수, 같으면 0, a > b이면 양수를 반환합니다.
+func compareVersions(a, b [3]int) int {
+	for i := 0; i < 3; i++ {
+		if a[i] != b[i] {
+			return a[i] - b[i]
+		}
+	}
+	return 0
+}
+
+// errVersionTooOld는 설치된 프로그램이 최소 요구 버전보다 낮을 때의 오류입니다.
+var errVersionTooOld = errors.New("최소 요구 버전보다 낮습니다")
+
+// versionRequirement는 SillyTavern 설치/실행에 필요한 프로그램의 최소 버전입니다.
+type versionRequirement struct {
+	name    string // 표시 이름
+	minimum [3]int
+}
+
+// 최소 요구 버전 표 (SillyTavern은 Node.js 18 이상이 필요합니다)
+var (
+	gitRequirement  = versionRequirement{"Git", [3]int{2, 20, 0}}
+	nodeRequirement = versionRequirement{"Node.js", [3]int{18, 0, 0}}
+	npmRequirement  = versionRequirement{"npm", [3]int{8, 0, 0}}
+)
+
+func formatVersion(v [3]int) string {
+	return fmt.Sprintf("%d.%d.%d", v[0], v[1], v[2])
+}
+
+// commandVersion은 "<실행 파일> --version" 출력에서 버전 번호를 읽습니다.
+func commandVersion(executable string) ([3]int, error) {
+	out, err := exec.Command(executable, "--version").Output()
+	if err != nil {
+		return [3]int{}, fmt.Errorf("%s --version 실행 실패: %w", executable, err)
+	}
+	version, ok := parseVersion(string(out))
+	if !ok {
+		return [3]int{}, fmt.Errorf("%s 버전을 해석할 수 없습니다: %s", executable, strings.TrimSpace(string(out)))
+	}
+	return version, nil
+}
+
+// checkMinimumVersion은 executable의 버전이 req의 최소 버전 이상인지 확인하고 확인한 버전을 반환합니다.
+// 버전이 낮으면 errVersionTooOld를 감싼 오류를, 버전을 확인할 수 없으면 그 밖의 오류를 반환합니다.
+func checkMinimumVersion(req versionRequirement, executable string) ([3]int, error) {
+	version, err := commandVersion(executable)
+	if err != nil {
+		return version, err
+	}
+	if compareVersions(version, req.minimum) < 0 {
+		return version, fmt.Errorf("%s %s은(는) %w (필요: %s 이상)", req.name, formatVersion(version), errVersionTooOld, formatVersion(req.minimum))
+	}
+	return version, nil
+}
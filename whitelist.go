@@ -0,0 +1,361 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+func updateWhitelistSetting() {
+	fmt.Println("\n[ 화이트리스트 수정 ]")
+	configPath, err := getConfigPath()
+	if err != nil {
+		fmt.Println("오류:", err)
+		return
+	}
+	config, err := loadConfig(configPath)
+	if err != nil {
+		fmt.Println("설정 파일 로드 오류:", err)
+		return
+	}
+	currentWhitelist := whitelistFromConfig(config)
+	fmt.Println("화이트리스트 모드:", whitelistModeLabel(config))
+	if len(currentWhitelist) > 0 {
+		fmt.Println("현재 화이트리스트:")
+		for i, entry := range currentWhitelist {
+			fmt.Printf("  %d) %s\n", i+1, entry)
+		}
+	} else {
+		fmt.Println("현재 화이트리스트: (설정된 IP 없음)")
+	}
+
+	fmt.Println("\n1. IP/대역 추가")
+	fmt.Println("2. IP/대역 제거")
+	fmt.Println("3. 목록 전체 교체")
+	fmt.Println("4. 목록 비우기")
+	fmt.Println("5. 화이트리스트 모드 켜기/끄기")
+	fmt.Println("6. 돌아가기")
+	fmt.Print("\n선택하세요 (1-6): ")
+
+	var finalWhitelist []string
+	switch getUserChoice() {
+	case "1":
+		fmt.Println("IPv4/IPv6 주소 또는 CIDR 대역을 입력할 수 있습니다 (예: 192.168.0.10, 192.168.0.0/16, ::1).")
+		fmt.Print("추가할 항목을 입력하세요 (쉼표(,)로 구분, 비워두면 추가 안 함): ")
+		input := getUserChoice()
+		if strings.TrimSpace(input) == "" {
+			fmt.Println("입력이 없어 화이트리스트에 IP를 추가하지 않습니다.")
+			return
+		}
+		finalWhitelist, err = addToWhitelist(configPath, strings.Split(input, ","))
+	case "2":
+		if len(currentWhitelist) == 0 {
+			fmt.Println("제거할 항목이 없습니다.")
+			return
+		}
+		fmt.Print("제거할 항목의 번호 또는 값을 입력하세요 (쉼표(,)로 구분): ")
+		input := getUserChoice()
+		if strings.TrimSpace(input) == "" {
+			fmt.Println("입력이 없어 제거하지 않습니다.")
+			return
+		}
+		finalWhitelist, err = removeFromWhitelist(configPath, whitelistEntriesFromInput(currentWhitelist, input))
+	case "3":
+		fmt.Print("새 화이트리스트 전체를 입력하세요 (쉼표(,)로 구분): ")
+		input := getUserChoice()
+		if strings.TrimSpace(input) == "" {
+			fmt.Println("입력이 없어 변경하지 않습니다. 목록을 비우려면 '목록 비우기'를 선택하세요.")
+			return
+		}
+		finalWhitelist, err = replaceWhitelist(configPath, strings.Split(input, ","))
+	case "4":
+		if !confirm("화이트리스트의 모든 항목을 삭제하시겠습니까? (y/n): ") {
+			fmt.Println("취소했습니다.")
+			return
+		}
+		finalWhitelist, err = replaceWhitelist(configPath, nil)
+	case "5":
+		enabled := !whitelistModeEnabled(config)
+		if err := setWhitelistMode(configPath, enabled); err != nil {
+			fmt.Println("❌ 설정 파일 저장 오류:", err)
+			return
+		}
+		if enabled {
+			fmt.Println("✅ 화이트리스트 모드를 켰습니다. SillyTavern을 재시작해야 적용됩니다.")
+		} else {
+			fmt.Println("✅ 화이트리스트 모드를 껐습니다. SillyTavern을 재시작해야 적용됩니다.")
+		}
+		offerRestart(installDir)
+		return
+	default:
+		return
+	}
+	if err != nil {
+		fmt.Println("❌ 화이트리스트를 변경하지 못했습니다:", err)
+		return
+	}
+	fmt.Println("✅ 화이트리스트가 업데이트되었습니다. SillyTavern을 재시작해야 적용됩니다.")
+	printFinalWhitelist(finalWhitelist)
+	offerRestart(installDir)
+}
+
+func printFinalWhitelist(whitelist []string) {
+	if len(whitelist) > 0 {
+		fmt.Println("   최종 화이트리스트:", strings.Join(whitelist, ", "))
+	} else {
+		fmt.Println("   (화이트리스트 비워짐)")
+	}
+}
+
+// normalizeWhitelistEntry는 entry가 IPv4/IPv6 주소 또는 CIDR 대역인지 확인하고, 중복 비교용 표준 형식만 반환합니다
+// (예: "::0001" -> "::1", "10.0.0.5/8" -> "10.0.0.0/8"). 설정 파일에는 이 값이 아니라 사용자가 입력한 형태를 그대로 저장합니다.
+func normalizeWhitelistEntry(entry string) (string, error) {
+	entry = strings.TrimSpace(entry)
+	if strings.Contains(entry, "/") {
+		_, ipNet, err := net.ParseCIDR(entry)
+		if err != nil {
+			return "", fmt.Errorf("잘못된 CIDR 대역입니다: '%s'", entry)
+		}
+		return ipNet.String(), nil
+	}
+	ip := net.ParseIP(entry)
+	if ip == nil {
+		return "", fmt.Errorf("잘못된 IP 주소 형식입니다: '%s'", entry)
+	}
+	return ip.String(), nil
+}
+
+// whitelistKey는 항목 비교용 키를 반환합니다 (해석할 수 없는 기존 항목은 원문 그대로 비교).
+func whitelistKey(entry string) string {
+	if key, err := normalizeWhitelistEntry(entry); err == nil {
+		return key
+	}
+	return strings.TrimSpace(entry)
+}
+
+// whitelistEntriesFromInput은 메뉴 입력(번호 또는 값)을 화이트리스트 항목 값으로 변환합니다.
+func whitelistEntriesFromInput(current []string, input string) []string {
+	var entries []string
+	for _, item := range strings.Split(input, ",") {
+		item = strings.TrimSpace(item)
+		if n, err := strconv.Atoi(item); err == nil && n >= 1 && n <= len(current) {
+			entries = append(entries, current[n-1])
+		} else if item != "" {
+			entries = append(entries, item)
+		}
+	}
+	return entries
+}
+
+// whitelistFromConfig는 설정의 whitelist 항목을 중복과 공백을 제거한 순서 유지 목록으로 반환합니다.
+func whitelistFromConfig(config *configDocument) []string {
+	seen := make(map[string]bool)
+	whitelist := []string{}
+	if wlNode := config.get("whitelist"); wlNode != nil && wlNode.Kind == yaml.SequenceNode {
+		for _, item := range wlNode.Content {
+			if item.Kind != yaml.ScalarNode {
+				continue
+			}
+			trimmedIP := strings.TrimSpace(item.Value)
+			if trimmedIP != "" && !seen[whitelistKey(trimmedIP)] {
+				seen[whitelistKey(trimmedIP)] = true
+				whitelist = append(whitelist, trimmedIP)
+			}
+		}
+	}
+	return whitelist
+}
+
+// setWhitelist는 whitelist 시퀀스를 entries로 맞춥니다.
+// 남아 있는 항목의 노드(주석, 따옴표 스타일)는 그대로 두고, 새 항목은 기존 항목의 스타일을 따릅니다.
+func setWhitelist(config *configDocument, entries []string) {
+	wlNode := config.get("whitelist")
+	if wlNode == nil || wlNode.Kind != yaml.SequenceNode {
+		wlNode = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		setMappingValue(config.mapping(), "whitelist", wlNode)
+	}
+	existing := make(map[string]*yaml.Node)
+	var itemStyle yaml.Style
+	for _, item := range wlNode.Content {
+		if item.Kind == yaml.ScalarNode {
+			if _, dup := existing[strings.TrimSpace(item.Value)]; !dup {
+				existing[strings.TrimSpace(item.Value)] = item
+			}
+			itemStyle = item.Style
+		}
+	}
+	content := make([]*yaml.Node, 0, len(entries))
+	for _, entry := range entries {
+		if item, ok := existing[entry]; ok {
+			content = append(content, item)
+			delete(existing, entry)
+			continue
+		}
+		content = append(content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Style: itemStyle, Value: entry})
+	}
+	wlNode.Content = content
+}
+
+func readWhitelist(configPath string) ([]string, error) {
+	config, err := loadConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+	return whitelistFromConfig(config), nil
+}
+
+// saveWhitelist는 whitelist를 설정에 반영하여 저장합니다.
+// 화이트리스트 모드가 켜진 상태에서 목록이 비면 접속이 모두 차단될 수 있으므로 경고합니다.
+func saveWhitelist(configPath string, config *configDocument, whitelist []string) error {
+	setWhitelist(config, whitelist)
+	if err := saveConfig(configPath, config); err != nil {
+		return err
+	}
+	if len(whitelist) == 0 && whitelistModeEnabled(config) {
+		fmt.Println("⚠️ 화이트리스트 모드가 켜져 있는데 목록이 비어 있습니다. 모든 접속이 차단될 수 있습니다.")
+	}
+	return nil
+}
+
+// addToWhitelist는 유효한 IP/CIDR 항목을 기존 순서 뒤에 추가하고 최종 화이트리스트를 반환합니다.
+// 잘못된 형식이거나 이미 존재하는 항목은 안내 후 건너뜁니다.
+func addToWhitelist(configPath string, entriesToAdd []string) ([]string, error) {
+	config, err := loadConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+	whitelist := whitelistFromConfig(config)
+	seen := make(map[string]bool)
+	for _, entry := range whitelist {
+		seen[whitelistKey(entry)] = true
+	}
+
+	added := 0
+	for _, entry := range entriesToAdd {
+		trimmed := strings.TrimSpace(entry)
+		if trimmed == "" {
+			continue
+		}
+		key, err := normalizeWhitelistEntry(trimmed)
+		if err != nil {
+			fmt.Printf("⚠️ %v (무시됨)\n", err)
+			continue
+		}
+		if seen[key] {
+			fmt.Printf("ℹ️ '%s'는 이미 화이트리스트에 존재합니다.\n", trimmed)
+			continue
+		}
+		seen[key] = true
+		whitelist = append(whitelist, trimmed)
+		added++
+	}
+	if added == 0 {
+		fmt.Println("새로 추가된 항목이 없습니다 (모두 유효하지 않거나 이미 존재).")
+		return whitelist, nil
+	}
+	if err := saveWhitelist(configPath, config, whitelist); err != nil {
+		return nil, err
+	}
+	return whitelist, nil
+}
+
+// removeFromWhitelist는 지정한 항목을 화이트리스트에서 제거하고 최종 화이트리스트를 반환합니다.
+// "::1"과 "0:0:0:0:0:0:0:1"처럼 표기만 다른 주소도 같은 항목으로 취급합니다.
+func removeFromWhitelist(configPath string, entriesToRemove []string) ([]string, error) {
+	config, err := loadConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+	toRemove := make(map[string]string)
+	for _, entry := range entriesToRemove {
+		if trimmed := strings.TrimSpace(entry); trimmed != "" {
+			toRemove[whitelistKey(trimmed)] = trimmed
+		}
+	}
+
+	whitelist := []string{}
+	removed := make(map[string]bool)
+	for _, entry := range whitelistFromConfig(config) {
+		if _, ok := toRemove[whitelistKey(entry)]; ok {
+			removed[whitelistKey(entry)] = true
+			continue
+		}
+		whitelist = append(whitelist, entry)
+	}
+	for key, entry := range toRemove {
+		if !removed[key] {
+			fmt.Printf("ℹ️ '%s'는 화이트리스트에 없습니다.\n", entry)
+		}
+	}
+	if len(removed) == 0 {
+		fmt.Println("제거된 항목이 없습니다.")
+		return whitelist, nil
+	}
+	if err := saveWhitelist(configPath, config, whitelist); err != nil {
+		return nil, err
+	}
+	return whitelist, nil
+}
+
+// replaceWhitelist는 화이트리스트 전체를 entries로 교체합니다. entries가 비어 있으면 목록을 비웁니다.
+// 잘못된 항목이 하나라도 있으면 아무것도 바꾸지 않습니다.
+func replaceWhitelist(configPath string, entries []string) ([]string, error) {
+	config, err := loadConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+	whitelist := []string{}
+	seen := make(map[string]bool)
+	for _, entry := range entries {
+		trimmed := strings.TrimSpace(entry)
+		if trimmed == "" {
+			continue
+		}
+		key, err := normalizeWhitelistEntry(trimmed)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[key] {
+			seen[key] = true
+			whitelist = append(whitelist, trimmed)
+		}
+	}
+	if err := saveWhitelist(configPath, config, whitelist); err != nil {
+		return nil, err
+	}
+	return whitelist, nil
+}
+
+func whitelistModeEnabled(config *configDocument) bool {
+	value, ok := config.getString("whitelistMode")
+	return ok && strings.EqualFold(value, "true")
+}
+
+func whitelistModeLabel(config *configDocument) string {
+	if config.get("whitelistMode") == nil {
+		return "(설정 없음)"
+	}
+	if whitelistModeEnabled(config) {
+		return "켜짐"
+	}
+	return "꺼짐"
+}
+
+// setWhitelistMode는 config.yaml의 whitelistMode 값을 바꿉니다.
+func setWhitelistMode(configPath string, enabled bool) error {
+	config, err := loadConfig(configPath)
+	if err != nil {
+		return err
+	}
+	config.setScalar(strconv.FormatBool(enabled), "!!bool", "whitelistMode")
+	if err := saveConfig(configPath, config); err != nil {
+		return err
+	}
+	if enabled && len(whitelistFromConfig(config)) == 0 {
+		fmt.Println("⚠️ 화이트리스트가 비어 있습니다. 모든 접속이 차단될 수 있으니 접속할 IP를 추가해주세요.")
+	}
+	return nil
+}
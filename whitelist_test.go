@@ -0,0 +1,166 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeWhitelistConfig는 whitelist 항목과 whitelistMode만 있는 config.yaml을 임시 디렉토리에 만듭니다.
+func writeWhitelistConfig(t *testing.T, entries ...string) string {
+	t.Helper()
+	var b strings.Builder
+	b.WriteString("whitelistMode: true\n# Whitelist of allowed IP addresses\nwhitelist:\n")
+	for _, e := range entries {
+		b.WriteString("  - " + e + "\n")
+	}
+	b.WriteString("port: 8000\n")
+	configPath := filepath.Join(t.TempDir(), configFileName)
+	writeFileT(t, configPath, b.String())
+	return configPath
+}
+
+func TestNormalizeWhitelistEntry(t *testing.T) {
+	tests := []struct{ entry, want string }{
+		{"::1", "::1"},
+		{"::0001", "::1"},
+		{"0:0:0:0:0:0:0:1", "::1"},
+		{" 127.0.0.1 ", "127.0.0.1"},
+		{"10.0.0.5/8", "10.0.0.0/8"},
+		{"192.168.1.77/24", "192.168.1.0/24"},
+		{"fe80::1234/10", "fe80::/10"},
+	}
+	for _, tt := range tests {
+		if got, err := normalizeWhitelistEntry(tt.entry); err != nil || got != tt.want {
+			t.Errorf("normalizeWhitelistEntry(%q) = %q, %v; want %q", tt.entry, got, err, tt.want)
+		}
+	}
+	for _, entry := range []string{"1.2.3", "300.1.1.1", "::1/129", "10.0.0.0/33", "localhost", "192.168.*.*", ""} {
+		if got, err := normalizeWhitelistEntry(entry); err == nil {
+			t.Errorf("normalizeWhitelistEntry(%q) = %q, want error", entry, got)
+		}
+	}
+}
+
+func TestAddToWhitelist(t *testing.T) {
+	configPath := writeWhitelistConfig(t, "::1", "127.0.0.1")
+	got, err := addToWhitelist(configPath, []string{"::0001", " 10.0.0.5/8", "1.2.3", "300.1.1.1", "::1/129", "10.1.2.3/8", "192.168.0.10"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 사용자가 입력한 형태로 저장하고, 표준 형식이 같은 항목(::0001, 10.1.2.3/8)은 중복으로 건너뜁니다.
+	want := []string{"::1", "127.0.0.1", "10.0.0.5/8", "192.168.0.10"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("addToWhitelist = %q, want %q", got, want)
+	}
+	if saved, err := readWhitelist(configPath); err != nil || !reflect.DeepEqual(saved, want) {
+		t.Errorf("saved whitelist = %q, %v; want %q", saved, err, want)
+	}
+
+	// 설정 파일에 이미 중복으로 들어 있는 항목은 한 번만 읽습니다.
+	dupPath := writeWhitelistConfig(t, "::1", "'::0001'", "127.0.0.1")
+	if got, err := readWhitelist(dupPath); err != nil || !reflect.DeepEqual(got, []string{"::1", "127.0.0.1"}) {
+		t.Errorf("readWhitelist = %q, %v", got, err)
+	}
+}
+
+func TestRemoveFromWhitelist(t *testing.T) {
+	current := []string{"::1", "127.0.0.1", "10.0.0.0/8", "192.168.0.10"}
+	tests := []struct {
+		name, input string
+		want        []string
+	}{
+		{"by number", "2, 4", []string{"::1", "10.0.0.0/8"}},
+		{"by value", "0:0:0:0:0:0:0:1, 10.9.9.9/8", []string{"127.0.0.1", "192.168.0.10"}},
+		{"mixed", "1, 192.168.0.10", []string{"127.0.0.1", "10.0.0.0/8"}},
+		{"out of range number is a value", "9", current},
+		{"not present", "8.8.8.8", current},
+	}
+	for _, tt := range tests {
+		configPath := writeWhitelistConfig(t, current...)
+		got, err := removeFromWhitelist(configPath, whitelistEntriesFromInput(current, tt.input))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: removeFromWhitelist(%q) = %q, want %q", tt.name, tt.input, got, tt.want)
+		}
+		if saved, _ := readWhitelist(configPath); !reflect.DeepEqual(saved, tt.want) {
+			t.Errorf("%s: saved whitelist = %q, want %q", tt.name, saved, tt.want)
+		}
+	}
+}
+
+func TestWhitelistKeepsLegacyEntries(t *testing.T) {
+	// SillyTavern이 예전에 허용하던 와일드카드 항목은 해석할 수 없어도 지우거나 바꾸지 않습니다.
+	configPath := writeWhitelistConfig(t, "::1", "'192.168.*.*'", "127.0.0.1")
+	before := readFileT(t, configPath)
+
+	got, err := addToWhitelist(configPath, []string{"10.0.0.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"::1", "192.168.*.*", "127.0.0.1", "10.0.0.1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("addToWhitelist = %q, want %q", got, want)
+	}
+	got, err = removeFromWhitelist(configPath, []string{"127.0.0.1", "10.0.0.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"::1", "192.168.*.*"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("removeFromWhitelist = %q, want %q", got, want)
+	}
+	if after := readFileT(t, configPath); !strings.Contains(after, "  - '192.168.*.*'\n") {
+		t.Errorf("legacy entry was rewritten:\n%s\nbefore:\n%s", after, before)
+	}
+
+	// 해석할 수 없는 항목도 원문 그대로 지정하면 지울 수 있습니다.
+	got, err = removeFromWhitelist(configPath, []string{"192.168.*.*"})
+	if err != nil || !reflect.DeepEqual(got, []string{"::1"}) {
+		t.Errorf("removeFromWhitelist(legacy) = %q, %v", got, err)
+	}
+}
+
+func TestReplaceWhitelistRejectsInvalid(t *testing.T) {
+	configPath := writeWhitelistConfig(t, "::1", "127.0.0.1")
+	before := readFileT(t, configPath)
+	if _, err := replaceWhitelist(configPath, []string{"10.0.0.1", "1.2.3"}); err == nil {
+		t.Fatal("replaceWhitelist accepted an invalid entry")
+	}
+	if after := readFileT(t, configPath); after != before {
+		t.Errorf("config.yaml changed by a rejected replace:\n%s", after)
+	}
+	got, err := replaceWhitelist(configPath, []string{"10.0.0.5/8", "10.0.0.0/8", "::1"})
+	if err != nil || !reflect.DeepEqual(got, []string{"10.0.0.5/8", "::1"}) {
+		t.Errorf("replaceWhitelist = %q, %v", got, err)
+	}
+}
+
+func TestSetWhitelistMode(t *testing.T) {
+	original := string(readDefaultConfig(t))
+	configPath := filepath.Join(t.TempDir(), configFileName)
+	writeFileT(t, configPath, original)
+
+	if err := setWhitelistMode(configPath, false); err != nil {
+		t.Fatal(err)
+	}
+	after := readFileT(t, configPath)
+	if changed := changedLines(t, original, after, "\n"); !reflect.DeepEqual(changed, []string{"whitelistMode: true -> whitelistMode: false"}) {
+		t.Errorf("changed lines = %q", changed)
+	}
+	config, err := loadConfig(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if whitelistModeEnabled(config) || whitelistModeLabel(config) != "꺼짐" {
+		t.Errorf("whitelistMode still on after toggling off")
+	}
+
+	if err := setWhitelistMode(configPath, true); err != nil {
+		t.Fatal(err)
+	}
+	if got := readFileT(t, configPath); got != original {
+		t.Errorf("toggling back did not restore the file:\n%s", got)
+	}
+}